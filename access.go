@@ -0,0 +1,89 @@
+package govalin
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// AccessManagerFunc decides whether the call may invoke the handler of its
+// route, given the roles declared by the route and its route groups
+//
+// The access manager must invoke the handler to let the call continue, or
+// respond otherwise, such as with Call.Error. It's invoked for every call
+// matching a handler, including calls to routes without roles.
+type AccessManagerFunc func(call *Call, handler HandlerFunc, roles []string)
+
+// RolesFunc returns the roles of the client of the call, or false if the client
+// isn't authenticated.
+type RolesFunc func(call *Call) ([]string, bool)
+
+// Roles adds roles permitted to call the route, such as 'admin' or permissions
+// like 'users:write'. The roles are passed to the access manager of the App,
+// see App.AccessManager, and combine with the roles of route groups.
+func (route *Route) Roles(roles ...string) *Route {
+	route.roles = append(route.roles, roles...)
+	return route
+}
+
+// AccessManager sets the access manager deciding whether calls may invoke the
+// handlers of their routes, such as RBAC. Before handlers have run when the
+// access manager is invoked, so authentication should happen in before
+// handlers.
+//
+// Calls to routes declaring roles are responded with 403 Forbidden if no
+// access manager is set, so routes aren't unprotected by mistake.
+func (server *App) AccessManager(accessManager AccessManagerFunc) *App {
+	server.accessManager = accessManager
+	return server
+}
+
+// RBAC creates an access manager allowing calls to routes without roles, and
+// calls from clients having any of the roles of the route. Calls from clients
+// which aren't authenticated are responded with 401 Unauthorized, and calls from
+// clients without any of the roles with 403 Forbidden.
+func RBAC(rolesFunc RolesFunc) AccessManagerFunc {
+	return func(call *Call, handler HandlerFunc, roles []string) {
+		if len(roles) == 0 {
+			handler(call)
+			return
+		}
+
+		clientRoles, authenticated := rolesFunc(call)
+		if !authenticated {
+			call.Error(NewHTTPError(http.StatusUnauthorized, "Authentication is required"))
+			return
+		}
+
+		if !slices.ContainsFunc(roles, func(role string) bool {
+			return slices.Contains(clientRoles, role)
+		}) {
+			call.Error(NewHTTPError(http.StatusForbidden, "Missing a role permitted to call the route"))
+			return
+		}
+
+		handler(call)
+	}
+}
+
+// invokeHandler invokes the handler of the route of the call through the
+// access manager.
+func (server *App) invokeHandler(call *Call, handler HandlerFunc) {
+	roles := []string{}
+	if call.route != nil {
+		roles = call.route.roles
+	}
+
+	if server.accessManager != nil {
+		server.accessManager(call, handler, roles)
+		return
+	}
+
+	if len(roles) > 0 {
+		slog.Error("Denying call to route with roles as no access manager is set", "route", call.routePattern)
+		call.Error(NewHTTPError(http.StatusForbidden))
+		return
+	}
+
+	handler(call)
+}
@@ -0,0 +1,175 @@
+package govalin_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func withRoles(roles ...string) govalin.RouteFunc {
+	return func(route *govalin.Route) {
+		route.Roles(roles...)
+	}
+}
+
+// rolesFromHeader authenticates clients by the roles in the X-Roles header.
+func rolesFromHeader(call *govalin.Call) ([]string, bool) {
+	roles := call.Header("X-Roles")
+	return strings.Split(roles, ","), roles != ""
+}
+
+func statusWithRoles(t *testing.T, govalinHTTP govalintesting.GovalinHTTP, path string, roles string) int {
+	headers := map[string]string{}
+	if roles != "" {
+		headers["X-Roles"] = roles
+	}
+
+	response, err := govalinHTTP.Raw().Do(http.MethodGet, govalinHTTP.Host+path, headers, nil)
+	assert.NoError(t, err)
+	_ = response.Body.Close()
+
+	return response.StatusCode
+}
+
+func TestRBAC(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.AccessManager(govalin.RBAC(rolesFromHeader))
+
+		app.Get("/public", func(call *govalin.Call) {
+			call.Text("public")
+		})
+		app.Route("/admin", func() {
+			app.Get("/users", func(call *govalin.Call) {
+				call.Text("users")
+			})
+			app.Get("/reports", func(call *govalin.Call) {
+				call.Text("reports")
+			}, withRoles("auditor"))
+		}, withRoles("admin"))
+
+		return app
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, http.StatusOK, statusWithRoles(t, govalinHTTP, "/public", ""))
+
+		assert.Equal(t, http.StatusUnauthorized, statusWithRoles(t, govalinHTTP, "/admin/users", ""))
+		assert.Equal(t, http.StatusForbidden, statusWithRoles(t, govalinHTTP, "/admin/users", "user"))
+		assert.Equal(t, http.StatusOK, statusWithRoles(t, govalinHTTP, "/admin/users", "user,admin"))
+
+		assert.Equal(t, http.StatusOK, statusWithRoles(t, govalinHTTP, "/admin/reports", "auditor"),
+			"Should combine the roles of the route and its group",
+		)
+		assert.Equal(t, http.StatusForbidden, statusWithRoles(t, govalinHTTP, "/admin/users", "auditor"))
+
+		response, _ := govalinHTTP.Raw().Do(http.MethodGet, govalinHTTP.Host+"/admin/users", nil, nil)
+		body, _ := response.ToString()
+		assert.JSONEq(t, `{
+			"title": "Unauthorized",
+			"detail": "Authentication is required",
+			"status": 401,
+			"type": "https://developer.mozilla.org/en-US/docs/Web/HTTP/Status/401"
+		}`, body)
+	})
+}
+
+func TestRolesOfRouteGroupsApplyToHTTPServe(t *testing.T) {
+	for _, withAccessManager := range []bool{true, false} {
+		govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+			if withAccessManager {
+				app.AccessManager(govalin.RBAC(rolesFromHeader))
+			}
+
+			app.Route("/admin", func() {
+				app.HTTPServe("/legacy", func(w http.ResponseWriter, _ *http.Request) {
+					_, _ = w.Write([]byte("legacy"))
+				})
+			}, withRoles("admin"))
+			assert.NotContains(t, app.OpenAPI().Paths, "/admin/legacy", "Should not document HTTPServe")
+
+			return app
+		}, func(govalinHTTP govalintesting.GovalinHTTP) {
+			if withAccessManager {
+				assert.Equal(t, http.StatusUnauthorized, statusWithRoles(t, govalinHTTP, "/admin/legacy", ""))
+				assert.Equal(t, http.StatusForbidden, statusWithRoles(t, govalinHTTP, "/admin/legacy", "user"))
+				assert.Equal(t, http.StatusOK, statusWithRoles(t, govalinHTTP, "/admin/legacy", "admin"))
+			} else {
+				assert.Equal(t, http.StatusForbidden, statusWithRoles(t, govalinHTTP, "/admin/legacy", "admin"),
+					"Should deny calls to HTTPServe in groups with roles without an access manager",
+				)
+			}
+		})
+	}
+}
+
+func TestAccessManager(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		// Only let owners access their own documents, unless they're admins
+		app.AccessManager(func(call *govalin.Call, handler govalin.HandlerFunc, roles []string) {
+			if len(roles) > 0 && call.Header("X-User") != call.PathParam("owner") && call.Header("X-Roles") != "admin" {
+				call.Error(govalin.NewHTTPError(http.StatusForbidden))
+				return
+			}
+			handler(call)
+		})
+
+		return app.Get("/documents/{owner}", func(call *govalin.Call) {
+			call.Text(call.PathParam("owner"))
+		}, withRoles("owner"))
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, _ := govalinHTTP.Raw().Do(
+			http.MethodGet, govalinHTTP.Host+"/documents/alice", map[string]string{"X-User": "alice"}, nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		response, _ = govalinHTTP.Raw().Do(
+			http.MethodGet, govalinHTTP.Host+"/documents/alice", map[string]string{"X-User": "bob"}, nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+		assert.Equal(t, http.StatusOK, statusWithRoles(t, govalinHTTP, "/documents/alice", "admin"))
+	})
+}
+
+func TestRolesWithoutAccessManager(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/open", func(call *govalin.Call) {
+			call.Text("open")
+		})
+		return app.Get("/protected", func(call *govalin.Call) {
+			call.Text("protected")
+		}, withRoles("admin"))
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, http.StatusOK, statusWithRoles(t, govalinHTTP, "/open", ""))
+		assert.Equal(t, http.StatusForbidden, statusWithRoles(t, govalinHTTP, "/protected", "admin"),
+			"Should deny calls to routes with roles without an access manager",
+		)
+	})
+}
+
+func TestOpenAPIDocumentsRoles(t *testing.T) {
+	app := govalin.New(func(config *govalin.Config) {
+		config.EnableStartupLog(false)
+	})
+
+	app.Route("/admin", func() {
+		app.Get("/users", func(_ *govalin.Call) {}, withRoles("auditor"))
+	}, withRoles("admin"))
+	app.Get("/public", func(_ *govalin.Call) {})
+
+	document := app.OpenAPI()
+
+	users := document.Paths["/admin/users"].Get
+	assert.Equal(t, []string{"admin", "auditor"}, users.Roles)
+	assert.Contains(t, users.Responses, "200")
+	assert.Contains(t, users.Responses, "401")
+	assert.Contains(t, users.Responses, "403")
+
+	public := document.Paths["/public"].Get
+	assert.Empty(t, public.Roles)
+	assert.NotContains(t, public.Responses, "401")
+}
@@ -0,0 +1,401 @@
+package govalin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/internal/http/writers"
+)
+
+const (
+	accessLogMessage   = "incoming request"
+	redactedValue      = "REDACTED"
+	combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+)
+
+// AccessLogFormat is the format of access log records.
+type AccessLogFormat int
+
+const (
+	// AccessLogFormatStructured logs the selected fields as attributes of the
+	// record, which are written as JSON when using a slog.JSONHandler.
+	AccessLogFormatStructured AccessLogFormat = iota
+	// AccessLogFormatCombined logs the Apache combined log format as the message
+	// of the record, such as
+	// '127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.1" 200 2326 "-" "curl/8.0"'.
+	AccessLogFormatCombined
+)
+
+// AccessLogField is a field of structured access log records.
+type AccessLogField string
+
+const (
+	AccessLogFieldID           AccessLogField = "id"             // ID of the call
+	AccessLogFieldMethod       AccessLogField = "method"         // Method of the request
+	AccessLogFieldDuration     AccessLogField = "duration_in_ms" // Duration of the call in milliseconds
+	AccessLogFieldPath         AccessLogField = "path"           // Path of the request
+	AccessLogFieldQuery        AccessLogField = "query"          // Query of the request, with redacted parameters
+	AccessLogFieldRoute        AccessLogField = "route"          // Path pattern of the route handling the call
+	AccessLogFieldStatus       AccessLogField = "status"         // Status of the response
+	AccessLogFieldClientIP     AccessLogField = "client_ip"      // IP of the client, see Call.ClientIP
+	AccessLogFieldUserAgent    AccessLogField = "user_agent"     // User agent of the request
+	AccessLogFieldReferer      AccessLogField = "referer"        // Referer of the request
+	AccessLogFieldProtocol     AccessLogField = "protocol"       // Protocol of the request, such as HTTP/1.1
+	AccessLogFieldRequestSize  AccessLogField = "request_size"   // Content length of the request
+	AccessLogFieldResponseSize AccessLogField = "response_size"  // Bytes written to the response body
+	AccessLogFieldHeaders      AccessLogField = "headers"        // Headers of the request selected by Headers
+)
+
+// AccessLogAttrsFunc returns custom attributes added to the access log record
+// of the call, such as the ID of the authenticated user.
+type AccessLogAttrsFunc func(call *Call) []slog.Attr
+
+// AccessLogConfiguration configures the access log
+//
+// The access log logs a record at the info level for every call, including
+// calls handled by HTTPServe. Records are logged using the default logger unless
+// a handler is given, letting access logs be written to a separate destination.
+type AccessLogConfiguration struct {
+	format          AccessLogFormat
+	fields          []AccessLogField
+	headers         []string
+	redactedHeaders []string
+	redactedQuery   []string
+	attrsFuncs      []AccessLogAttrsFunc
+	skipPaths       []string
+	sampleRate      float64
+	handler         slog.Handler
+}
+
+type AccessLogConfigFunc func(accessLogConfig *AccessLogConfiguration)
+
+// AccessLog configures the access log, see EnableAccessLog for disabling it.
+func (config *Config) AccessLog(confFunc AccessLogConfigFunc) *Config {
+	confFunc(&config.server.accessLog)
+	return config
+}
+
+func newAccessLogConfiguration() AccessLogConfiguration {
+	return AccessLogConfiguration{
+		format: AccessLogFormatStructured,
+		fields: []AccessLogField{
+			AccessLogFieldID,
+			AccessLogFieldMethod,
+			AccessLogFieldDuration,
+			AccessLogFieldPath,
+			AccessLogFieldQuery,
+			AccessLogFieldRoute,
+			AccessLogFieldStatus,
+			AccessLogFieldClientIP,
+			AccessLogFieldUserAgent,
+			AccessLogFieldReferer,
+			AccessLogFieldResponseSize,
+		},
+		headers: []string{},
+		redactedHeaders: []string{
+			headers.Authorization,
+			headers.Cookie,
+			headers.ProxyAuthorization,
+			"X-Api-Key",
+		},
+		redactedQuery: []string{"access_token", "api_key", "password", "secret", "token"},
+		attrsFuncs:    []AccessLogAttrsFunc{},
+		skipPaths:     []string{},
+		sampleRate:    1,
+	}
+}
+
+// Format sets the format of access log records. Defaults to
+// AccessLogFormatStructured.
+func (config *AccessLogConfiguration) Format(format AccessLogFormat) *AccessLogConfiguration {
+	config.format = format
+	return config
+}
+
+// Fields selects the fields of structured access log records. Defaults to all
+// fields except the protocol, request size and headers.
+func (config *AccessLogConfiguration) Fields(fields ...AccessLogField) *AccessLogConfiguration {
+	config.fields = fields
+	return config
+}
+
+// Headers sets the request headers logged in the headers field, which is added
+// to the selected fields. Values of redacted headers are replaced.
+func (config *AccessLogConfiguration) Headers(names ...string) *AccessLogConfiguration {
+	config.headers = names
+	if len(names) > 0 && !slices.Contains(config.fields, AccessLogFieldHeaders) {
+		config.fields = append(config.fields, AccessLogFieldHeaders)
+	}
+	return config
+}
+
+// RedactHeaders sets the headers whose values are replaced in access logs.
+// Defaults to Authorization, Cookie, Proxy-Authorization and X-Api-Key.
+func (config *AccessLogConfiguration) RedactHeaders(names ...string) *AccessLogConfiguration {
+	config.redactedHeaders = names
+	return config
+}
+
+// RedactQueryParams sets the query parameters whose values are replaced in
+// access logs. Defaults to access_token, api_key, password, secret and token.
+func (config *AccessLogConfiguration) RedactQueryParams(names ...string) *AccessLogConfiguration {
+	config.redactedQuery = names
+	return config
+}
+
+// Attrs adds a function returning custom attributes of access log records.
+func (config *AccessLogConfiguration) Attrs(attrsFunc AccessLogAttrsFunc) *AccessLogConfiguration {
+	config.attrsFuncs = append(config.attrsFuncs, attrsFunc)
+	return config
+}
+
+// SkipPaths sets paths which aren't logged, such as health checks. Paths ending
+// with '*' skip all paths starting with the path, such as '/health/*'.
+func (config *AccessLogConfiguration) SkipPaths(paths ...string) *AccessLogConfiguration {
+	config.skipPaths = paths
+	return config
+}
+
+// SampleRate sets the fraction of successful calls which are logged, from 0 to
+// 1. Calls responding with a status of 400 or above are always logged.
+// Defaults to 1, logging every call.
+func (config *AccessLogConfiguration) SampleRate(sampleRate float64) *AccessLogConfiguration {
+	config.sampleRate = min(max(sampleRate, 0), 1)
+	return config
+}
+
+// Handler sets the handler access log records are logged to, such as a
+// slog.JSONHandler writing to a separate file. Defaults to the handler of the
+// default logger.
+func (config *AccessLogConfiguration) Handler(handler slog.Handler) *AccessLogConfiguration {
+	config.handler = handler
+	return config
+}
+
+// skip returns whether the call shouldn't be logged.
+func (config *AccessLogConfiguration) skip(path string, status int) bool {
+	for _, skipPath := range config.skipPaths {
+		if prefix, isPrefix := strings.CutSuffix(skipPath, "*"); isPrefix && strings.HasPrefix(path, prefix) {
+			return true
+		}
+		if path == skipPath {
+			return true
+		}
+	}
+
+	return status < http.StatusBadRequest && config.sampleRate < 1 && rand.Float64() >= config.sampleRate
+}
+
+func (config *AccessLogConfiguration) logger() *slog.Logger {
+	if config.handler != nil {
+		return slog.New(config.handler)
+	}
+
+	return slog.Default()
+}
+
+// responseRecorder records the status and size of responses, including those
+// of calls handled by HTTPServe which bypass the call.
+type responseRecorder struct {
+	writers.Wrapper
+	status  int
+	written int64
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	if recorder.status == 0 || recorder.status < http.StatusOK {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+
+	written, writeErr := recorder.ResponseWriter.Write(data)
+	recorder.written += int64(written)
+	return written, writeErr
+}
+
+func (server *App) logAccessLog(call *Call, recorder *responseRecorder, start time.Time) {
+	if !server.config.server.accessLogEnabled {
+		return
+	}
+	config := &server.config.server.accessLog
+
+	status := call.status
+	if recorder.status != 0 {
+		status = recorder.status
+	}
+	if call.Disconnected() {
+		status = StatusClientClosedRequest
+	}
+
+	if config.skip(call.URL().Path, status) {
+		return
+	}
+
+	attrs := []slog.Attr{}
+	message := accessLogMessage
+	if config.format == AccessLogFormatCombined {
+		message = config.combined(call, status, recorder.written, start)
+	} else {
+		attrs = config.fieldAttrs(call, status, recorder.written, start)
+	}
+	for _, attrsFunc := range config.attrsFuncs {
+		attrs = append(attrs, attrsFunc(call)...)
+	}
+
+	// The context of the call may be cancelled, which handlers shouldn't drop
+	// records of
+	config.logger().LogAttrs(context.WithoutCancel(call.Context()), slog.LevelInfo, message, attrs...)
+}
+
+func (config *AccessLogConfiguration) fieldAttrs(call *Call, status int, written int64, start time.Time) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(config.fields))
+	for _, field := range config.fields {
+		key := string(field)
+
+		switch field {
+		case AccessLogFieldID:
+			attrs = append(attrs, slog.String(key, call.ID()))
+		case AccessLogFieldMethod:
+			attrs = append(attrs, slog.String(key, call.Method()))
+		case AccessLogFieldDuration:
+			attrs = append(attrs, slog.Float64(key, float64(time.Since(start))/float64(time.Millisecond)))
+		case AccessLogFieldPath:
+			attrs = append(attrs, slog.String(key, call.URL().Path))
+		case AccessLogFieldQuery:
+			if query := config.redactQuery(call.URL().RawQuery); query != "" {
+				attrs = append(attrs, slog.String(key, query))
+			}
+		case AccessLogFieldRoute:
+			if route := call.RoutePattern(); route != "" {
+				attrs = append(attrs, slog.String(key, route))
+			}
+		case AccessLogFieldStatus:
+			attrs = append(attrs, slog.Int(key, status))
+		case AccessLogFieldClientIP:
+			attrs = append(attrs, slog.String(key, call.ClientIP()))
+		case AccessLogFieldUserAgent:
+			attrs = append(attrs, slog.String(key, call.UserAgent()))
+		case AccessLogFieldReferer:
+			if referer := call.Referer(); referer != "" {
+				attrs = append(attrs, slog.String(key, referer))
+			}
+		case AccessLogFieldProtocol:
+			attrs = append(attrs, slog.String(key, call.req.Proto))
+		case AccessLogFieldRequestSize:
+			attrs = append(attrs, slog.Int64(key, max(call.req.ContentLength, 0)))
+		case AccessLogFieldResponseSize:
+			attrs = append(attrs, slog.Int64(key, written))
+		case AccessLogFieldHeaders:
+			attrs = append(attrs, config.headerAttrs(call))
+		}
+	}
+
+	return attrs
+}
+
+func (config *AccessLogConfiguration) headerAttrs(call *Call) slog.Attr {
+	attrs := make([]any, 0, len(config.headers))
+	for _, name := range config.headers {
+		values := call.HeaderValues(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		value := strings.Join(values, ", ")
+		if config.isRedactedHeader(name) {
+			value = redactedValue
+		}
+		attrs = append(attrs, slog.String(strings.ToLower(name), value))
+	}
+
+	return slog.Group(string(AccessLogFieldHeaders), attrs...)
+}
+
+func (config *AccessLogConfiguration) isRedactedHeader(name string) bool {
+	return slices.ContainsFunc(config.redactedHeaders, func(redacted string) bool {
+		return strings.EqualFold(redacted, name)
+	})
+}
+
+// redactQuery replaces the values of redacted parameters of the raw query,
+// keeping the order of the parameters.
+func (config *AccessLogConfiguration) redactQuery(rawQuery string) string {
+	if rawQuery == "" || len(config.redactedQuery) == 0 {
+		return rawQuery
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, _, hasValue := strings.Cut(param, "=")
+		name, unescapeErr := url.QueryUnescape(key)
+		if unescapeErr != nil {
+			name = key
+		}
+
+		redacted := slices.ContainsFunc(config.redactedQuery, func(redacted string) bool {
+			return strings.EqualFold(redacted, name)
+		})
+		if redacted && hasValue {
+			params[i] = key + "=" + redactedValue
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// combined formats the call in the Apache combined log format.
+func (config *AccessLogConfiguration) combined(call *Call, status int, written int64, start time.Time) string {
+	user := "-"
+	if username, _, ok := call.req.BasicAuth(); ok && username != "" {
+		user = username
+	}
+
+	target := call.URL().EscapedPath()
+	if query := config.redactQuery(call.URL().RawQuery); query != "" {
+		target += "?" + query
+	}
+
+	size := "-"
+	if written > 0 {
+		size = strconv.FormatInt(written, 10)
+	}
+
+	return fmt.Sprintf(
+		`%s - %s [%s] "%s %s %s" %d %s %s %s`,
+		call.ClientIP(),
+		user,
+		start.Format(combinedTimeFormat),
+		call.Method(),
+		target,
+		call.req.Proto,
+		status,
+		size,
+		quoteCombined(call.Referer()),
+		quoteCombined(call.UserAgent()),
+	)
+}
+
+// quoteCombined quotes a header value of the combined log format, escaping
+// quotes and control characters so values can't forge log lines.
+func quoteCombined(value string) string {
+	if value == "" {
+		return `"-"`
+	}
+
+	return strconv.Quote(value)
+}
@@ -0,0 +1,177 @@
+package govalin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+// logBuffer collects log records written by the server.
+type logBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (buffer *logBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	return buffer.buffer.Write(data)
+}
+
+func (buffer *logBuffer) lines() []string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	return strings.Split(strings.TrimSpace(buffer.buffer.String()), "\n")
+}
+
+// records waits for the number of records to be logged, returning them.
+func (buffer *logBuffer) records(t *testing.T, count int) []map[string]any {
+	assert.Eventually(t, func() bool {
+		return len(buffer.lines()) >= count && buffer.lines()[0] != ""
+	}, time.Second, 5*time.Millisecond)
+
+	records := []map[string]any{}
+	for _, line := range buffer.lines() {
+		record := map[string]any{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestAccessLogStructured(t *testing.T) {
+	logs := &logBuffer{}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableStartupLog(false)
+			config.AccessLog(func(accessLogConfig *govalin.AccessLogConfiguration) {
+				accessLogConfig.
+					Handler(slog.NewJSONHandler(logs, nil)).
+					Fields(
+						govalin.AccessLogFieldMethod,
+						govalin.AccessLogFieldPath,
+						govalin.AccessLogFieldQuery,
+						govalin.AccessLogFieldRoute,
+						govalin.AccessLogFieldStatus,
+						govalin.AccessLogFieldResponseSize,
+					).
+					Headers("Authorization", "X-Tenant").
+					Attrs(func(call *govalin.Call) []slog.Attr {
+						return []slog.Attr{slog.String("tenant", call.Header("X-Tenant"))}
+					}).
+					SkipPaths("/health/*")
+			})
+		}).
+			Get("/users/{id}", func(call *govalin.Call) {
+				call.Text("user")
+			}).
+			Get("/health/live", func(call *govalin.Call) {
+				call.Text("up")
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, "up", govalinHTTP.Get("/health/live"))
+		response, _ := govalinHTTP.Raw().Do(
+			http.MethodGet,
+			govalinHTTP.Host+"/users/42?page=2&token=secret&Password=hunter2",
+			map[string]string{"Authorization": "Bearer secret", "X-Tenant": "acme"},
+			nil,
+		)
+		_ = response.Body.Close()
+
+		records := logs.records(t, 1)
+		assert.Len(t, records, 1, "Should skip the health check")
+
+		record := records[0]
+		assert.Equal(t, "incoming request", record["msg"])
+		assert.Equal(t, "GET", record["method"])
+		assert.Equal(t, "/users/42", record["path"])
+		assert.Equal(t, "page=2&token=REDACTED&Password=REDACTED", record["query"])
+		assert.Equal(t, "/users/{id}", record["route"])
+		assert.Equal(t, float64(200), record["status"])
+		assert.Equal(t, float64(4), record["response_size"])
+		assert.Equal(t, map[string]any{"authorization": "REDACTED", "x-tenant": "acme"}, record["headers"])
+		assert.Equal(t, "acme", record["tenant"])
+		assert.NotContains(t, record, "id", "Should only log selected fields")
+		assert.NotContains(t, record, "user_agent")
+	})
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	logs := &logBuffer{}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableStartupLog(false)
+			config.AccessLog(func(accessLogConfig *govalin.AccessLogConfiguration) {
+				accessLogConfig.
+					Handler(slog.NewJSONHandler(logs, nil)).
+					Format(govalin.AccessLogFormatCombined)
+			})
+		})
+		app.HTTPServe("/legacy", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("legacy"))
+		})
+
+		return app
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		req, _ := http.NewRequest(http.MethodGet, govalinHTTP.Host+"/legacy?api_key=abc", nil)
+		req.SetBasicAuth("alice", "secret")
+		req.Header.Set("Referer", "https://example.com/")
+		req.Header.Set("User-Agent", `agent "quoted"`)
+		response, _ := http.DefaultClient.Do(req)
+		body, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		assert.Equal(t, "legacy", string(body))
+
+		message := logs.records(t, 1)[0]["msg"].(string)
+		assert.Regexp(t,
+			`^127\.0\.0\.1 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] `+
+				`"GET /legacy\?api_key=REDACTED HTTP/1\.1" 202 6 "https://example\.com/" "agent \\"quoted\\""$`,
+			message,
+			"Should log calls handled by HTTPServe with the status and size of their response",
+		)
+	})
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	logs := &logBuffer{}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableStartupLog(false)
+			config.AccessLog(func(accessLogConfig *govalin.AccessLogConfiguration) {
+				accessLogConfig.
+					Handler(slog.NewJSONHandler(logs, nil)).
+					SampleRate(0)
+			})
+		}).Get("/users/{id}", func(call *govalin.Call) {
+			call.Text("user")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, "user", govalinHTTP.Get("/users/1"))
+		assert.Equal(t, "user", govalinHTTP.Get("/users/2"))
+		govalinHTTP.Get("/missing")
+
+		records := logs.records(t, 1)
+		assert.Len(t, records, 1, "Should only log failed calls")
+		assert.Equal(t, "/missing", records[0]["path"])
+		assert.Equal(t, float64(404), records[0]["status"])
+		assert.NotEmpty(t, records[0]["id"])
+		assert.NotEmpty(t, records[0]["duration_in_ms"])
+	})
+}
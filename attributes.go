@@ -0,0 +1,39 @@
+package govalin
+
+// Get or set a call attribute by key
+//
+// Attributes let before handlers, the endpoint handler and after handlers share
+// values such as an authenticated user or a tenant. Attributes only live for the
+// current call, see SessionAttr for values which are kept between calls. Returns
+// nil if the attribute doesn't exist. Use Attr for typed access.
+func (call *Call) Attribute(key string, value ...any) any {
+	if len(value) > 0 {
+		call.attributes[key] = value[0]
+		return value[0]
+	}
+
+	return call.attributes[key]
+}
+
+// RemoveAttribute removes the call attribute by given key.
+func (call *Call) RemoveAttribute(key string) {
+	delete(call.attributes, key)
+}
+
+// Attr gets a call attribute as the given type
+//
+// Returns false if the attribute doesn't exist or is of another type.
+func Attr[T any](call *Call, key string) (T, bool) {
+	value, ok := call.attributes[key].(T)
+	return value, ok
+}
+
+// AttrOrDefault gets a call attribute as the given type, or the given default
+// value if the attribute doesn't exist or is of another type.
+func AttrOrDefault[T any](call *Call, key string, def T) T {
+	if value, ok := Attr[T](call, key); ok {
+		return value
+	}
+
+	return def
+}
@@ -0,0 +1,46 @@
+package govalin_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenant struct {
+	Name string
+}
+
+func TestAttributes(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Before("/*", func(call *govalin.Call) bool {
+			assert.Nil(t, call.Attribute("tenant"), "Should not leak attributes between calls")
+			call.Attribute("tenant", tenant{Name: call.QueryParam("tenant")})
+			call.Attribute("count", 1)
+			return true
+		})
+		app.Get("/tenant", func(call *govalin.Call) {
+			current, ok := govalin.Attr[tenant](call, "tenant")
+			assert.True(t, ok)
+
+			_, ok = govalin.Attr[string](call, "count")
+			assert.False(t, ok, "Should not convert attributes of another type")
+
+			call.Attribute("count", govalin.AttrOrDefault(call, "count", 0)+1)
+			call.RemoveAttribute("tenant")
+			call.Text(current.Name)
+		})
+		app.After("/*", func(call *govalin.Call) {
+			assert.Nil(t, call.Attribute("tenant"))
+			assert.Equal(t, 2, call.Attribute("count"), "Should share attributes with after handlers")
+			call.Text(fmt.Sprint(call.Attribute("count")))
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Equal(t, "govalin2", http.Get("/tenant?tenant=govalin"))
+		assert.Equal(t, "other2", http.Get("/tenant?tenant=other"))
+	})
+}
@@ -1,6 +1,7 @@
 package govalin
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,7 +14,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/pkkummermo/govalin/internal/http/charsets"
 	"github.com/pkkummermo/govalin/internal/http/contenttypes"
 	"github.com/pkkummermo/govalin/internal/http/headers"
@@ -43,41 +43,65 @@ type Call struct {
 	bypassLifecycle bool
 	w               http.ResponseWriter
 	req             *http.Request
+	requestCtx      context.Context
 	pathParams      map[string]string
 	bodyBytes       []byte
+	bodyDecoded     bool
+	bodyDecodeErr   error
 	charset         string
 	session         session.Session
+	sessionResumed  bool
+	attributes      map[string]any
+	route           *Route
+	routePattern    string
+	clientInfo      *clientInfo
+	traceContext    TraceContext
+	completeFuncs   []func()
+	checkedETag     string
+	checkedModified string
 	Raw             raw // Raw contains the raw request and response
 }
 
-func newCallFromRequest(w http.ResponseWriter, req *http.Request, config *Config, pathParams map[string]string) Call {
-	govalinIDHeader := req.Header[http.CanonicalHeaderKey("x-govalin-id")]
-
-	var uniqueID string
-	if govalinIDHeader == nil {
-		uniqueID = uuid.New().String()
-	} else {
-		uniqueID = govalinIDHeader[0]
+func newCallFromRequest(w http.ResponseWriter, req *http.Request, config *Config, pathParams map[string]string) *Call {
+	traceContext := newTraceContext(req)
+	uniqueID := config.server.requestID.callID(req, traceContext)
+	if config.server.requestID.echo {
+		w.Header().Set(config.server.requestID.header, uniqueID)
 	}
 
-	call := Call{
+	call := &Call{
 		id:              uniqueID,
+		traceContext:    traceContext,
 		config:          config,
 		w:               w,
 		req:             req,
+		requestCtx:      req.Context(),
 		status:          0,
 		bypassLifecycle: false,
 		pathParams:      pathParams,
 		charset:         charsets.UTF8,
+		attributes:      map[string]any{},
 		Raw: raw{
 			W:   &w,
 			Req: req,
 		},
 	}
 
+	call.Attribute(ClientIPAttribute, call.ClientIP())
+
+	ctx := context.WithValue(req.Context(), callIDContextKey, uniqueID)
+	ctx = context.WithValue(ctx, traceContextKey, traceContext)
+	ctx = context.WithValue(ctx, callContextKey, call)
+	call.WithContext(ctx)
+
+	for _, observer := range config.server.events.callObservers {
+		observer.OnCallStart(call)
+	}
+
 	if config.server.sessionsEnabled {
-		initiateSessionFromCall(&call)
+		initiateSessionFromCall(call)
 	}
+	call.WithContext(context.WithValue(call.Context(), sessionIDContextKey, call.session.ID))
 
 	return call
 }
@@ -96,7 +120,23 @@ func initiateSessionFromCall(call *Call) {
 		return
 	}
 
-	session, getSessionErr := call.config.server.sessionStore.GetSession(sessionCookie.Value, 0)
+	sessionID := sessionCookie.Value
+	if keyring := call.config.server.sessionKeyring; keyring != nil {
+		verifiedID, verifyErr := keyring.Verify(sessionCookieName, sessionCookie.Value)
+		if verifyErr != nil {
+			slog.Debug("Failed to verify session cookie, adding new session", "err", verifyErr)
+			addNewSessionErr := addNewSessionToCall(call)
+			if addNewSessionErr != nil {
+				slog.Error("Failed to add new session to call", "err", addNewSessionErr)
+			}
+			return
+		}
+		sessionID = string(verifiedID)
+	}
+
+	endPhase := call.startPhase(CallPhaseSessionStore, "GetSession")
+	session, getSessionErr := call.config.server.sessionStore.GetSession(sessionID, 0)
+	endPhase(getSessionErr)
 	// The session might be expired, so we need to create a new one
 	if getSessionErr != nil {
 		slog.Debug("Failed to get session from session store, adding new session", "err", getSessionErr)
@@ -108,27 +148,38 @@ func initiateSessionFromCall(call *Call) {
 	}
 
 	call.session = session
+	call.sessionResumed = true
 }
 
 func addNewSessionToCall(call *Call) error {
+	endPhase := call.startPhase(CallPhaseSessionStore, "CreateSession")
 	sessionID, createSessionErr := call.config.server.sessionStore.
 		CreateSession(time.Now().Add(call.config.server.sessionExpireTime).UnixNano())
+	endPhase(createSessionErr)
 
 	if createSessionErr != nil {
 		slog.Error("Failed to create session", "err", createSessionErr)
 		return createSessionErr
 	}
 
+	endPhase = call.startPhase(CallPhaseSessionStore, "GetSession")
 	session, getNewSessionErr := call.config.server.sessionStore.
 		GetSession(sessionID, 0)
+	endPhase(getNewSessionErr)
 	if getNewSessionErr != nil {
 		slog.Error("Failed to get session from session store", "err", getNewSessionErr)
 		return getNewSessionErr
 	}
 
+	expiresAt := time.Now().Add(call.config.server.sessionExpireTime)
+	cookieValue := sessionID
+	if keyring := call.config.server.sessionKeyring; keyring != nil {
+		cookieValue = keyring.Sign(sessionCookieName, []byte(sessionID), expiresAt)
+	}
+
 	_, cookieErr := call.Cookie(sessionCookieName, &http.Cookie{
-		Value:    sessionID,
-		Expires:  time.Now().Add(call.config.server.sessionExpireTime),
+		Value:    cookieValue,
+		Expires:  expiresAt,
 		HttpOnly: true,
 	})
 	if cookieErr != nil {
@@ -146,11 +197,22 @@ func (call *Call) readBody() ([]byte, error) {
 		return call.bodyBytes, nil
 	}
 
+	if decodeErr := call.decodeBody(); decodeErr != nil {
+		call.bodyBytes = []byte{}
+		return []byte{}, decodeErr
+	}
+
 	limitedReader := io.LimitReader(call.req.Body, call.config.server.maxBodyReadSize)
 
 	bytes, err := io.ReadAll(limitedReader)
 	if err != nil {
 		call.bodyBytes = []byte{}
+
+		var validationErr *validation.Error
+		if errors.As(err, &validationErr) {
+			return []byte{}, err
+		}
+
 		return []byte{}, fmt.Errorf("failed to read request body. %w", err)
 	}
 
@@ -177,9 +239,33 @@ func (call *Call) parseForm() error {
 
 	switch {
 	case strings.Contains(contentType, contenttypes.ApplicationFormURLEncoded):
+		if call.req.Header.Get(headers.ContentEncoding) != "" {
+			if decodeErr := call.decodeBody(); decodeErr != nil {
+				return decodeErr
+			}
+
+			// Decoded forms are limited like other bodies, to defend against zip bombs
+			maxBodyReadSize := call.config.server.maxBodyReadSize
+			call.req.Body = &limitedBody{ReadCloser: call.req.Body, remaining: maxBodyReadSize, limit: maxBodyReadSize}
+		}
+
 		err := call.req.ParseForm()
 		if err != nil {
 			slog.Error("Failed to parse form data", "err", err)
+
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return newRequestTooLargeError(
+					"body",
+					fmt.Sprintf("The body exceeds the max size of %d bytes", maxBytesErr.Limit),
+				)
+			}
+
+			var validationErr *validation.Error
+			if errors.As(err, &validationErr) {
+				return err
+			}
+
 			return validation.NewError(validation.NewErrorResponse(
 				http.StatusBadRequest,
 				validation.NewParameterErrorDetail(
@@ -190,19 +276,7 @@ func (call *Call) parseForm() error {
 		}
 		return nil
 	case strings.Contains(contentType, contenttypes.MultipartFormData):
-		err := call.req.ParseMultipartForm(0)
-		if err != nil {
-			slog.Error("Failed to parse form data", "err", err)
-			return validation.NewError(validation.NewErrorResponse(
-				http.StatusBadRequest,
-				validation.NewParameterErrorDetail(
-					"formData",
-					"Invalid form data",
-				),
-			))
-		}
-
-		return nil
+		return call.parseMultipartForm()
 	default:
 		slog.Warn("POST request is missing the correct content-type to parse form param")
 		return validation.NewError(validation.NewErrorResponse(
@@ -217,6 +291,29 @@ func (call *Call) parseForm() error {
 	}
 }
 
+// OnComplete registers a function which is run when the call has been handled,
+// after the After handlers and before the access log. Functions are run in the reverse
+// order of registration, like deferred calls. Used by plugins to release
+// resources held by a call, such as closing wrapped response writers.
+func (call *Call) OnComplete(completeFunc func()) {
+	call.completeFuncs = append(call.completeFuncs, completeFunc)
+}
+
+// complete runs the registered complete functions and removes files spooled to
+// disk when parsing multipart bodies. The request of the call may have been
+// replaced using WithContext, so the server can't be relied on to remove them.
+func (call *Call) complete() {
+	for i := len(call.completeFuncs) - 1; i >= 0; i-- {
+		call.completeFuncs[i]()
+	}
+
+	if call.req.MultipartForm != nil {
+		if removeErr := call.req.MultipartForm.RemoveAll(); removeErr != nil {
+			slog.Error("Failed to remove multipart files", "err", removeErr)
+		}
+	}
+}
+
 func (call *Call) sendStatusOrDefault() {
 	if call.statusWritten {
 		return
@@ -230,7 +327,22 @@ func (call *Call) sendStatusOrDefault() {
 	call.statusWritten = true
 }
 
-// ID gives an UUIDv4 string that's unique to the call.
+// WrapResponseWriter replaces the response writer of the call
+//
+// The given function receives the current response writer and returns the writer
+// the call will use from now on, letting plugins observe or transform responses.
+// Wrapping writers should implement Unwrap() http.ResponseWriter so features like
+// flushing and hijacking remain available through http.ResponseController.
+func (call *Call) WrapResponseWriter(wrapFunc func(w http.ResponseWriter) http.ResponseWriter) {
+	call.w = wrapFunc(call.w)
+	*call.Raw.W = call.w
+}
+
+// ID returns the ID of the call, used to correlate logs and responses
+//
+// The ID is taken from the request ID header of the request when it's valid,
+// and generated otherwise, such as a UUID, a ULID or the trace ID of the call.
+// See RequestIDConfiguration.
 func (call *Call) ID() string {
 	return call.id
 }
@@ -245,11 +357,6 @@ func (call *Call) Authorization() string {
 	return call.Header(headers.Authorization)
 }
 
-// Host returns the host of the current request.
-func (call *Call) Host() string {
-	return call.Header(headers.Host)
-}
-
 // Referer returns the requests referer header if available. Also handles the edge
 // case if the name of the header names spelling is correct (referrer).
 func (call *Call) Referer() string {
@@ -364,6 +471,14 @@ func (call *Call) QueryParam(key string) string {
 	return call.req.URL.Query().Get(key)
 }
 
+// Get all query param values for given key
+//
+// Returns all values of repeated query params, such as '?tag=a&tag=b', in the
+// order given. Returns nil if the query param isn't given.
+func (call *Call) QueryParams(key string) []string {
+	return call.req.URL.Query()[key]
+}
+
 // Get query param by key, if empty, use default
 //
 // Returns the query param value as string or use the given
@@ -425,6 +540,44 @@ func (call *Call) Header(key string, value ...string) string {
 	return ""
 }
 
+// Get all header values by given key
+//
+// Returns all values of a request header which may be repeated, such as
+// Forwarded and Accept, in the order given. A value may contain several comma
+// separated elements. Returns nil if the header isn't given.
+func (call *Call) HeaderValues(key string) []string {
+	key = http.CanonicalHeaderKey(key)
+
+	if key == headers.Host {
+		return []string{call.req.Host}
+	}
+
+	return call.req.Header[key]
+}
+
+// Set header on the response, replacing existing values
+//
+// Sets the given values on the response, replacing any values added before,
+// unlike Header which adds a value. Giving no values removes the header.
+func (call *Call) SetHeader(key string, values ...string) {
+	key = http.CanonicalHeaderKey(key)
+
+	if len(values) == 0 {
+		call.w.Header().Del(key)
+		return
+	}
+
+	call.w.Header()[key] = append([]string(nil), values...)
+}
+
+// Remove header from the response
+//
+// Removes all values of the header from the response, such as headers set by
+// plugins. Has no effect once the response has been written.
+func (call *Call) RemoveHeader(key string) {
+	call.w.Header().Del(key)
+}
+
 // Get header value by key, if empty, use default
 //
 // Get a header value based on given header key from the request,
@@ -456,10 +609,7 @@ func (call *Call) Status(statusCode ...int) int {
 // Text will set the content-type of the response as text/plain and write it to the response.
 // If no other status has been given the response, it will write a 200 OK to the response.
 func (call *Call) Text(text string) {
-	call.w.Header().Add(headers.ContentType, headers.ContentTypeHeader(contenttypes.TextPlain, call.charset))
-	call.sendStatusOrDefault()
-
-	_, err := call.w.Write([]byte(text))
+	err := call.writeBody(headers.ContentTypeHeader(contenttypes.TextPlain, call.charset), []byte(text))
 	if err != nil {
 		slog.Error(fmt.Sprintf("Error when trying write to response, %v", err))
 	}
@@ -470,10 +620,7 @@ func (call *Call) Text(text string) {
 // HTML will set the content-type of the response as text/html and write it to the response.
 // If no other status has been given the response, it will write a 200 OK to the response.
 func (call *Call) HTML(text string) {
-	call.w.Header().Add(headers.ContentType, headers.ContentTypeHeader(contenttypes.TextHTML, call.charset))
-	call.sendStatusOrDefault()
-
-	_, err := call.w.Write([]byte(text))
+	err := call.writeBody(headers.ContentTypeHeader(contenttypes.TextHTML, call.charset), []byte(text))
 	if err != nil {
 		slog.Error(fmt.Sprintf("Error when trying write to response, %v", err))
 	}
@@ -485,15 +632,12 @@ func (call *Call) HTML(text string) {
 // object as JSON, and writes it to the response. If no other status has been given the response,
 // it will write a 200 OK to the response.
 func (call *Call) JSON(obj interface{}) {
-	call.w.Header().Add(headers.ContentType, headers.ContentTypeHeader(contenttypes.ApplicationJSON, charsets.UTF8))
 	jsonBytes, err := json.Marshal(obj)
 	if err != nil {
 		slog.Error(fmt.Sprintf("error when trying to JSON marshall object, %v", err))
 	}
 
-	call.sendStatusOrDefault()
-
-	_, err = call.w.Write(jsonBytes)
+	err = call.writeBody(headers.ContentTypeHeader(contenttypes.ApplicationJSON, charsets.UTF8), jsonBytes)
 	if err != nil {
 		slog.Error(fmt.Sprintf("error when trying write to response, %v", err))
 	}
@@ -535,6 +679,14 @@ func (call *Call) BodyAs(obj any) error {
 	return nil
 }
 
+// SessionResumed returns whether the session of the call was resumed from a
+// valid session cookie sent by the client. Returns false when the session was
+// created for the call, such as for clients not sending cookies, or when
+// sessions aren't enabled.
+func (call *Call) SessionResumed() bool {
+	return call.sessionResumed
+}
+
 // Get or set a session attribute by key and value
 //
 // Get or set a session attribute based on given key from the request. The
@@ -550,7 +702,12 @@ To enable, either enable sessions on the app config object or use the session pl
 
 	if len(value) > 0 {
 		call.session.Data[key] = value[0]
-		return value[0], call.config.server.sessionStore.SetSessionData(call.session.ID, call.session.Data)
+
+		endPhase := call.startPhase(CallPhaseSessionStore, "SetSessionData")
+		setErr := call.config.server.sessionStore.SetSessionData(call.session.ID, call.session.Data)
+		endPhase(setErr)
+
+		return value[0], setErr
 	}
 
 	if call.session.Data[key] == nil {
@@ -620,12 +777,17 @@ func (call *Call) Error(err error) {
 
 	var validationErr *validation.Error
 	if errors.As(err, &validationErr) {
-		call.Status(http.StatusBadRequest)
+		if validationErr.ErrorResponse.Status != 0 {
+			call.Status(validationErr.ErrorResponse.Status)
+		} else {
+			call.Status(http.StatusBadRequest)
+		}
 		call.JSON(validationErr.ErrorResponse)
 		return
 	}
 
 	slog.Error(fmt.Sprintf("Unknown error '%v'. Error not handled", err))
+	call.Status(http.StatusInternalServerError)
 	call.JSON(validation.NewError(
 		validation.NewErrorResponse(
 			http.StatusInternalServerError,
@@ -1,6 +1,7 @@
 package govalin
 
 import (
+	"net/netip"
 	"time"
 
 	"github.com/pkkummermo/govalin/internal/session"
@@ -10,6 +11,8 @@ const (
 	defaultPort                      = 6060               // govalin default port.
 	defaultMaxReadTimeout            = 10                 // maximum read timeout for requests.
 	defaultMaxBodyReadSize     int64 = 4096               //  Default max body read size.
+	defaultMaxFileSize         int64 = 32 << 20           // Default max size of uploaded files.
+	defaultMaxFiles                  = 16                 // Default max number of uploaded files.
 	defaultShutdownTimeoutInMS       = 200                // Max time for shutdown.
 	defaultSessionExpireTime         = 3600 * time.Second // Default session expire time.
 )
@@ -22,24 +25,38 @@ type serverConfig struct {
 	port                uint16
 	maxReadTimeout      int64
 	maxBodyReadSize     int64
+	maxFileSize         int64
+	maxFiles            int
+	etagMode            ETagMode
+	keyring             *Keyring
+	trustedProxies      []netip.Prefix
+	requestID           RequestIDConfiguration
 	shutdownTimeoutInMS int64
 	accessLogEnabled    bool
+	accessLog           AccessLogConfiguration
 	startupLogEnabled   bool
 	plugins             []Plugin
 	sessionsEnabled     bool
 	sessionStore        session.Store
 	sessionExpireTime   time.Duration
+	sessionKeyring      *Keyring
 	events              ServerEvents
 }
 
 type ServerEvents struct {
 	onServerStartup  []OnServerStartup
 	onServerShutdown []OnServerShutdown
+	onServerStopped  []OnServerStopped
 	onRouteAdded     []OnRouteAdded
+	callObservers    []CallObserver
 }
 
 type OnServerStartup func()
 type OnServerShutdown func()
+
+// OnServerStopped is called when the server has stopped after a shutdown, once
+// in-flight calls have completed or the shutdown timeout has been exceeded.
+type OnServerStopped func()
 type OnRouteAdded func(method string, path string, handler HandlerFunc)
 
 func (events *ServerEvents) AddOnServerStartup(event OnServerStartup) {
@@ -51,6 +68,14 @@ func (events *ServerEvents) AddOnServerShutdown(event OnServerShutdown) {
 func (events *ServerEvents) AddOnRouteAdded(event OnRouteAdded) {
 	events.onRouteAdded = append(events.onRouteAdded, event)
 }
+func (events *ServerEvents) AddOnServerStopped(event OnServerStopped) {
+	events.onServerStopped = append(events.onServerStopped, event)
+}
+
+// AddCallObserver adds an observer notified of the phases of every call.
+func (events *ServerEvents) AddCallObserver(observer CallObserver) {
+	events.callObservers = append(events.callObservers, observer)
+}
 
 // Config contains configuration for a Govalin instance.
 type Config struct {
@@ -85,6 +110,44 @@ func (config *Config) ServerMaxBodyReadSize(maxReadSize int64) *Config {
 	return config
 }
 
+// ServerMaxFileSize sets the max size in bytes of each file in multipart bodies.
+//
+// Uploads with bigger files are refused with 413 Request Entity Too Large. The
+// limit can be overridden per route using Route.MaxFileSize.
+func (config *Config) ServerMaxFileSize(maxFileSize int64) *Config {
+	config.server.maxFileSize = maxFileSize
+	return config
+}
+
+// ServerMaxFiles sets the max number of files accepted in multipart bodies.
+//
+// Uploads with more files are refused with 413 Request Entity Too Large. The
+// limit can be overridden per route using Route.MaxFiles.
+func (config *Config) ServerMaxFiles(maxFiles int) *Config {
+	config.server.maxFiles = maxFiles
+	return config
+}
+
+// ETags sets whether ETags are generated for responses to GET and HEAD requests
+//
+// When enabled, successful responses written using Text, HTML and JSON get an
+// ETag hashed from the response body, and requests with a matching If-None-Match
+// header are responded to with 304 Not Modified without a body. Defaults to
+// ETagDisabled. The mode can be overridden per route using Route.ETag.
+func (config *Config) ETags(mode ETagMode) *Config {
+	config.server.etagMode = mode
+	return config
+}
+
+// Keyring sets the keyring used to sign and encrypt cookies
+//
+// Required by Call.SignedCookie and Call.EncryptedCookie. The keyring can be
+// reused to sign the session cookie using SessionConfiguration.Keyring.
+func (config *Config) Keyring(keyring *Keyring) *Config {
+	config.server.keyring = keyring
+	return config
+}
+
 // ServerMaxReadTimeout sets the max read timeout for requests towards the Govalin server.
 func (config *Config) ServerMaxReadTimeout(timeout int64) *Config {
 	config.server.maxReadTimeout = timeout
@@ -105,6 +168,7 @@ func (config *Config) EnableSessions(confFunc ...SessionConfigFunc) *Config {
 	config.server.sessionsEnabled = true
 	config.server.sessionExpireTime = configuredSession.sessionExpireTime
 	config.server.sessionStore = configuredSession.sessionStore
+	config.server.sessionKeyring = configuredSession.keyring
 
 	return config
 }
@@ -132,13 +196,19 @@ func newConfig() *Config {
 			port:                defaultPort,
 			maxReadTimeout:      defaultMaxReadTimeout,
 			maxBodyReadSize:     defaultMaxBodyReadSize,
+			maxFileSize:         defaultMaxFileSize,
+			maxFiles:            defaultMaxFiles,
+			etagMode:            ETagDisabled,
+			requestID:           newRequestIDConfiguration(),
 			shutdownTimeoutInMS: defaultShutdownTimeoutInMS,
 			sessionsEnabled:     false,
 			accessLogEnabled:    true,
+			accessLog:           newAccessLogConfiguration(),
 			startupLogEnabled:   true,
 			events: ServerEvents{
 				onServerStartup:  []OnServerStartup{},
 				onServerShutdown: []OnServerShutdown{},
+				onServerStopped:  []OnServerStopped{},
 				onRouteAdded:     []OnRouteAdded{},
 			},
 		},
@@ -148,6 +218,7 @@ func newConfig() *Config {
 type SessionConfiguration struct {
 	sessionExpireTime time.Duration
 	sessionStore      session.Store
+	keyring           *Keyring
 }
 
 // SessionExpireTime sets the expire time for sessions.
@@ -162,4 +233,12 @@ func (config *SessionConfiguration) SessionStore(store session.Store) *SessionCo
 	return config
 }
 
+// Keyring sets the keyring used to sign the session cookie, such as the keyring
+// configured using Config.Keyring. Session cookies which have been tampered
+// with are replaced by new sessions. Session cookies aren't signed by default.
+func (config *SessionConfiguration) Keyring(keyring *Keyring) *SessionConfiguration {
+	config.keyring = keyring
+	return config
+}
+
 type SessionConfigFunc func(sessionConfig *SessionConfiguration)
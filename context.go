@@ -0,0 +1,70 @@
+package govalin
+
+import (
+	"context"
+	"errors"
+)
+
+// StatusClientClosedRequest is the status recorded in the access log for calls
+// where the client disconnected before the response was completed.
+const StatusClientClosedRequest = 499
+
+// ErrServerShutdown is the cause of the cancellation of call contexts which are
+// still running when the graceful shutdown of the server times out.
+var ErrServerShutdown = errors.New("govalin server shut down")
+
+type contextKey int
+
+const (
+	callIDContextKey contextKey = iota
+	sessionIDContextKey
+	traceContextKey
+	callContextKey
+)
+
+// CallIDFromContext returns the ID of the call the context belongs to, or an
+// empty string if the context doesn't belong to a call.
+func CallIDFromContext(ctx context.Context) string {
+	callID, _ := ctx.Value(callIDContextKey).(string)
+	return callID
+}
+
+// SessionIDFromContext returns the session ID of the call the context belongs
+// to, or an empty string if sessions aren't enabled.
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDContextKey).(string)
+	return sessionID
+}
+
+// Context returns the context of the call
+//
+// The context is cancelled when the client disconnects, when the deadline of
+// the route set using Route.Timeout is exceeded or when the server shuts down
+// without in-flight calls completing in time. Pass it to database calls and
+// other blocking operations so they stop when the response is no longer needed.
+// The context carries the call ID, the session ID, the trace context and the
+// logger of the call, see CallIDFromContext, SessionIDFromContext,
+// TraceContextFromContext and LoggerFromContext.
+func (call *Call) Context() context.Context {
+	return call.req.Context()
+}
+
+// WithContext replaces the context of the call
+//
+// Use it to add values or deadlines to the context for the rest of the call,
+// typically from a before handler. The given context should be derived from
+// Call.Context().
+func (call *Call) WithContext(ctx context.Context) *Call {
+	call.req = call.req.WithContext(ctx)
+	call.Raw.Req = call.req
+	return call
+}
+
+// Disconnected returns true if the client disconnected before the call was
+// completed, meaning any response will never be received.
+func (call *Call) Disconnected() bool {
+	// The context of the request isn't replaced by WithContext, so deadlines of
+	// handlers aren't mistaken for disconnects
+	ctx := call.requestCtx
+	return errors.Is(ctx.Err(), context.Canceled) && errors.Is(context.Cause(ctx), context.Canceled)
+}
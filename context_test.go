@@ -0,0 +1,180 @@
+package govalin_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ddliu/go-httpclient"
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+type testContextKey struct{}
+
+func TestCallContext(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/id", func(call *govalin.Call) {
+			assert.Equal(t, call.ID(), govalin.CallIDFromContext(call.Context()))
+			call.Text(govalin.CallIDFromContext(call.Context()))
+		})
+		app.Before("/value", func(call *govalin.Call) bool {
+			call.WithContext(context.WithValue(call.Context(), testContextKey{}, "govalin"))
+			return true
+		})
+		app.Get("/value", func(call *govalin.Call) {
+			value, _ := call.Context().Value(testContextKey{}).(string)
+			call.Text(value)
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		response, _ := http.Raw().Begin().WithHeader("x-govalin-id", "govalin-id").Get(http.Host + "/id")
+		body, _ := response.ToString()
+		assert.Equal(t, "govalin-id", body, "Should carry the call ID")
+		assert.Equal(t, "govalin", http.Get("/value"), "Should replace the context of the call")
+	})
+}
+
+func TestCallContextSessionID(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableSessions()
+		}).Get("/session", func(call *govalin.Call) {
+			call.Text(govalin.SessionIDFromContext(call.Context()))
+		})
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/session")
+		body, _ := response.ToString()
+		assert.NotEmpty(t, body)
+		assert.Equal(t, response.Cookies()[0].Value, body, "Should carry the session ID")
+	})
+}
+
+func TestRouteTimeout(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/slow", func(call *govalin.Call) {
+			select {
+			case <-call.Context().Done():
+				call.Status(503)
+				call.Text(call.Context().Err().Error())
+			case <-time.After(time.Second):
+				call.Text("done")
+			}
+		}, func(route *govalin.Route) {
+			route.Timeout(10 * time.Millisecond)
+		})
+		app.Get("/deadline", func(call *govalin.Call) {
+			_, hasDeadline := call.Context().Deadline()
+			assert.False(t, hasDeadline, "Should only set deadlines on routes with a timeout")
+			call.Text("done")
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Equal(t, context.DeadlineExceeded.Error(), http.Get("/slow"))
+		assert.Equal(t, "done", http.Get("/deadline"))
+	})
+}
+
+func TestRouteTimeoutAfterHandler(t *testing.T) {
+	logs := &logBuffer{}
+	afterErrs := make(chan error, 1)
+	completeErrs := make(chan error, 1)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableStartupLog(false)
+			config.AccessLog(func(accessLogConfig *govalin.AccessLogConfiguration) {
+				accessLogConfig.
+					Handler(slog.NewJSONHandler(logs, nil)).
+					Fields(govalin.AccessLogFieldStatus)
+			})
+		})
+		app.After("/timed", func(call *govalin.Call) {
+			afterErrs <- call.Context().Err()
+			call.OnComplete(func() {
+				completeErrs <- call.Context().Err()
+			})
+		})
+
+		return app.Get("/timed", func(call *govalin.Call) {
+			call.Text("ok")
+		}, func(route *govalin.Route) {
+			route.Timeout(time.Second)
+		})
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Equal(t, "ok", http.Get("/timed"))
+		assert.NoError(t, <-afterErrs, "Should not cancel the context of after handlers")
+		assert.NoError(t, <-completeErrs, "Should not cancel the context of OnComplete funcs")
+
+		records := logs.records(t, 1)
+		assert.Equal(t, float64(200), records[0]["status"], "Should not log calls as disconnected")
+	})
+}
+
+func TestClientDisconnect(t *testing.T) {
+	disconnected := make(chan bool, 1)
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/wait", func(call *govalin.Call) {
+			<-call.Context().Done()
+			disconnected <- call.Disconnected()
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		_, err := http.Raw().Begin().WithOption(httpclient.OPT_TIMEOUT_MS, 50).Get(http.Host + "/wait")
+		assert.Error(t, err)
+
+		select {
+		case result := <-disconnected:
+			assert.True(t, result, "Should detect the disconnected client")
+		case <-time.After(time.Second):
+			assert.Fail(t, "Call context was not cancelled when the client disconnected")
+		}
+	})
+}
+
+func TestShutdownCancelsCalls(t *testing.T) {
+	cause := make(chan error, 1)
+	started := make(chan bool)
+	listening := make(chan bool)
+
+	app := govalin.New(func(config *govalin.Config) {
+		config.EnableStartupLog(false)
+		config.EnableAccessLog(false)
+		config.ServerShutdownTimeout(10)
+		config.Events(func(serverEvents *govalin.ServerEvents) {
+			serverEvents.AddOnServerStartup(func() { listening <- true })
+		})
+	})
+	app.Get("/wait", func(call *govalin.Call) {
+		started <- true
+		<-call.Context().Done()
+		cause <- context.Cause(call.Context())
+	})
+
+	listener, _ := net.Listen("tcp", "localhost:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	go func() { _ = app.Start(uint16(port)) }()
+	<-listening
+
+	go func() { _, _ = httpclient.Get(fmt.Sprintf("http://localhost:%d/wait", port)) }()
+	<-started
+
+	assert.ErrorIs(t, app.Shutdown(), context.DeadlineExceeded, "Should time out waiting for the call")
+
+	select {
+	case err := <-cause:
+		assert.ErrorIs(t, err, govalin.ErrServerShutdown)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Call context was not cancelled on shutdown")
+	}
+}
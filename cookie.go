@@ -0,0 +1,98 @@
+package govalin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var errMissingKeyring = errors.New("signed and encrypted cookies require a keyring, configure one using Config.Keyring")
+
+// Get or set a signed Cookie by name and value
+//
+// Works like Cookie, but the value is signed using the keyring of the config,
+// so the client can read the value but not change it. The expiry of the cookie
+// is signed as well. Returns the cookie with the verified value, or
+// ErrInvalidValue if the cookie has been tampered with and ErrExpiredValue if
+// it has expired.
+func (call *Call) SignedCookie(name string, cookies ...*http.Cookie) (*http.Cookie, error) {
+	keyring := call.config.server.keyring
+	if keyring == nil {
+		return nil, errMissingKeyring
+	}
+
+	if len(cookies) > 0 {
+		cookie := *cookies[0]
+		cookie.Name = name
+		cookie.Value = keyring.Sign(name, []byte(cookies[0].Value), cookieExpiry(cookies[0]))
+		http.SetCookie(call.w, &cookie)
+
+		cookie.Value = cookies[0].Value
+		return &cookie, nil
+	}
+
+	cookie, cookieErr := call.req.Cookie(name)
+	if cookieErr != nil {
+		return nil, cookieErr
+	}
+
+	value, verifyErr := keyring.Verify(name, cookie.Value)
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
+	cookie.Value = string(value)
+
+	return cookie, nil
+}
+
+// Get or set an encrypted Cookie by name and value
+//
+// Works like Cookie, but the value is encrypted using the keyring of the
+// config, so the client can neither read nor change the value. The expiry of
+// the cookie is encrypted as well. Returns the cookie with the decrypted value,
+// or ErrInvalidValue if the cookie has been tampered with and ErrExpiredValue
+// if it has expired.
+func (call *Call) EncryptedCookie(name string, cookies ...*http.Cookie) (*http.Cookie, error) {
+	keyring := call.config.server.keyring
+	if keyring == nil {
+		return nil, errMissingKeyring
+	}
+
+	if len(cookies) > 0 {
+		encrypted, encryptErr := keyring.Encrypt(name, []byte(cookies[0].Value), cookieExpiry(cookies[0]))
+		if encryptErr != nil {
+			return nil, encryptErr
+		}
+
+		cookie := *cookies[0]
+		cookie.Name = name
+		cookie.Value = encrypted
+		http.SetCookie(call.w, &cookie)
+
+		cookie.Value = cookies[0].Value
+		return &cookie, nil
+	}
+
+	cookie, cookieErr := call.req.Cookie(name)
+	if cookieErr != nil {
+		return nil, cookieErr
+	}
+
+	value, decryptErr := keyring.Decrypt(name, cookie.Value)
+	if decryptErr != nil {
+		return nil, decryptErr
+	}
+	cookie.Value = string(value)
+
+	return cookie, nil
+}
+
+// cookieExpiry returns when the cookie expires, preferring MaxAge over Expires
+// like clients do. Returns the zero time for session cookies.
+func cookieExpiry(cookie *http.Cookie) time.Time {
+	if cookie.MaxAge > 0 {
+		return time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+	}
+
+	return cookie.Expires
+}
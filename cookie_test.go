@@ -0,0 +1,100 @@
+package govalin_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedAndEncryptedCookies(t *testing.T) {
+	keyring, _ := govalin.NewKeyring(newKey, oldKey)
+	oldKeyring, _ := govalin.NewKeyring(oldKey)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Keyring(keyring)
+		}).
+			Get("/set", func(call *govalin.Call) {
+				_, _ = call.SignedCookie("theme", &http.Cookie{Value: "dark", Path: "/"})
+				_, _ = call.EncryptedCookie("token", &http.Cookie{Value: "secret", Path: "/", MaxAge: 60})
+			}).
+			Get("/get", func(call *govalin.Call) {
+				theme, themeErr := call.SignedCookie("theme")
+				token, tokenErr := call.EncryptedCookie("token")
+				if themeErr != nil || tokenErr != nil {
+					call.Status(http.StatusBadRequest)
+					call.Text("invalid")
+					return
+				}
+
+				call.Text(theme.Value + " " + token.Value)
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := govalinHTTP.GetResponse("/set")
+		cookies := map[string]*http.Cookie{}
+		for _, cookie := range response.Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		assert.Contains(t, cookies["theme"].Value, ".")
+		assert.NotContains(t, cookies["token"].Value, "secret")
+		assert.Equal(t, 60, cookies["token"].MaxAge)
+
+		assert.Equal(t, "dark secret", govalinHTTP.Get("/get"))
+
+		get := func(theme string, token string) int {
+			response, err := govalinHTTP.Raw().Begin().
+				WithCookie(&http.Cookie{Name: "theme", Value: theme}).
+				WithCookie(&http.Cookie{Name: "token", Value: token}).
+				Get(govalinHTTP.Host + "/get")
+			assert.NoError(t, err)
+
+			return response.StatusCode
+		}
+
+		oldToken, _ := oldKeyring.Encrypt("token", []byte("secret"), time.Time{})
+		assert.Equal(t, 200, get(oldKeyring.Sign("theme", []byte("light"), time.Time{}), oldToken),
+			"Should accept cookies created using rotated keys")
+		assert.Equal(t, 400, get("light", cookies["token"].Value), "Should refuse unsigned cookies")
+		assert.Equal(t, 400, get(cookies["theme"].Value, cookies["theme"].Value), "Should refuse cookies of other names")
+		expiredTheme := keyring.Sign("theme", []byte("dark"), time.Now().Add(-time.Second))
+		assert.Equal(t, 400, get(expiredTheme, cookies["token"].Value), "Should refuse expired cookies")
+	})
+}
+
+func TestSignedSessionCookie(t *testing.T) {
+	keyring, _ := govalin.NewKeyring(newKey)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.EnableSessions(func(sessionConfig *govalin.SessionConfiguration) {
+				sessionConfig.Keyring(keyring)
+			})
+		}).
+			Get("/session", func(call *govalin.Call) {
+				visits, _ := call.SessionAttrOrDefault("visits", 0).(int)
+				_, _ = call.SessionAttr("visits", visits+1)
+				call.JSON(visits + 1)
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := govalinHTTP.GetResponse("/session")
+		sessionCookie := response.Cookies()[0]
+		sessionID, verifyErr := keyring.Verify("govalin-session", sessionCookie.Value)
+		assert.NoError(t, verifyErr, "Should sign the session cookie")
+		assert.NotEmpty(t, sessionID)
+
+		assert.Equal(t, "2", govalinHTTP.Get("/session"), "Should keep signed sessions")
+
+		response, _ = govalinHTTP.Raw().Begin().
+			WithCookie(&http.Cookie{Name: "govalin-session", Value: string(sessionID)}).
+			Get(govalinHTTP.Host + "/session")
+		body, _ := response.ToString()
+		assert.Equal(t, "1", body, "Should replace unsigned session cookies")
+		assert.Len(t, response.Cookies(), 1)
+	})
+}
@@ -0,0 +1,136 @@
+package govalin
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/internal/validation"
+)
+
+const (
+	contentEncodingGzip     = "gzip"
+	contentEncodingDeflate  = "deflate"
+	contentEncodingIdentity = "identity"
+)
+
+// decodeBody replaces the body of the request with a decompressing reader when
+// the body is encoded using the Content-Encoding header, so handlers reading
+// the body get the decoded content. Limits of the body, such as the max body
+// read size, apply to the decoded content. Returns a 415 Unsupported Media Type
+// validation error for unsupported encodings and a 400 Bad Request validation
+// error for invalid encoded bodies.
+func (call *Call) decodeBody() error {
+	if call.bodyDecoded {
+		return call.bodyDecodeErr
+	}
+	call.bodyDecoded = true
+
+	encodings := []string{}
+	for _, value := range call.req.Header.Values(headers.ContentEncoding) {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding == "" || encoding == contentEncodingIdentity {
+				continue
+			}
+			if encoding != contentEncodingGzip && encoding != contentEncodingDeflate {
+				call.w.Header().Set(headers.AcceptEncoding, contentEncodingGzip+", "+contentEncodingDeflate)
+				call.bodyDecodeErr = validation.NewError(validation.NewErrorResponse(
+					http.StatusUnsupportedMediaType,
+					validation.NewParameterErrorDetail(
+						headers.ContentEncoding,
+						"Unsupported content encoding '"+encoding+"'. "+
+							"Must be '"+contentEncodingGzip+"' or '"+contentEncodingDeflate+"'",
+					),
+				))
+				return call.bodyDecodeErr
+			}
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	if len(encodings) == 0 {
+		return nil
+	}
+
+	body := &decodedBody{original: call.req.Body, reader: call.req.Body}
+
+	// Encodings are listed in the order they were applied, so decode them in reverse
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, decoderErr := newDecoder(encodings[i], body.reader)
+		if decoderErr != nil {
+			slog.Debug("Failed to decode request body", "encoding", encodings[i], "err", decoderErr)
+			call.bodyDecodeErr = newInvalidEncodedBodyError()
+			return call.bodyDecodeErr
+		}
+		body.reader = decoder
+		body.decoders = append(body.decoders, decoder)
+	}
+
+	call.req.Body = body
+	call.req.ContentLength = -1
+	call.req.Header.Del(headers.ContentEncoding)
+	call.req.Header.Del(headers.ContentLength)
+
+	return nil
+}
+
+// newDecoder returns a reader decoding given encoding. Deflate is specified as
+// zlib wrapped deflate data, but raw deflate data is sent by some clients, so
+// both are accepted.
+func newDecoder(encoding string, reader io.Reader) (io.ReadCloser, error) {
+	if encoding == contentEncodingGzip {
+		return gzip.NewReader(reader)
+	}
+
+	bufferedReader := bufio.NewReader(reader)
+	header, _ := bufferedReader.Peek(2)
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(bufferedReader)
+	}
+
+	return flate.NewReader(bufferedReader), nil
+}
+
+// decodedBody reads the decoded content of an encoded request body, failing
+// with a validation error when the encoded body is invalid.
+type decodedBody struct {
+	original io.ReadCloser
+	reader   io.Reader
+	decoders []io.ReadCloser
+}
+
+func (body *decodedBody) Read(data []byte) (int, error) {
+	read, readErr := body.reader.Read(data)
+	if readErr != nil && !errors.Is(readErr, io.EOF) {
+		slog.Debug("Failed to decode request body", "err", readErr)
+		return read, newInvalidEncodedBodyError()
+	}
+
+	return read, readErr
+}
+
+func (body *decodedBody) Close() error {
+	for _, decoder := range body.decoders {
+		_ = decoder.Close()
+	}
+
+	return body.original.Close()
+}
+
+func newInvalidEncodedBodyError() error {
+	return validation.NewError(validation.NewErrorResponse(
+		http.StatusBadRequest,
+		validation.NewParameterErrorDetail(
+			headers.ContentEncoding,
+			"The body isn't valid for the given '"+headers.ContentEncoding+"'",
+		),
+	))
+}
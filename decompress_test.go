@@ -0,0 +1,115 @@
+package govalin_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"strings"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipped(content string) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	_, _ = writer.Write([]byte(content))
+	_ = writer.Close()
+
+	return buffer
+}
+
+func TestRequestDecompression(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.ServerMaxBodyReadSize(64)
+		}).
+			Post("/json", func(call *govalin.Call) {
+				var body map[string]string
+				if err := call.BodyAs(&body); err != nil {
+					call.Error(err)
+					return
+				}
+
+				call.Text(body["device"])
+			}).
+			Post("/form", func(call *govalin.Call) {
+				device, err := call.FormParam("device")
+				if err != nil {
+					call.Error(err)
+					return
+				}
+
+				call.Text(device)
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		post := func(path string, contentType string, encoding string, body *bytes.Buffer) (int, string) {
+			response, err := govalinHTTP.Raw().Do(
+				"POST",
+				govalinHTTP.Host+path,
+				map[string]string{"Content-Type": contentType, "Content-Encoding": encoding},
+				body,
+			)
+			assert.NoError(t, err)
+			responseBody, _ := response.ToString()
+
+			return response.StatusCode, responseBody
+		}
+
+		status, body := post("/json", "application/json", "gzip", gzipped(`{"device":"sensor"}`))
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "sensor", body)
+
+		zlibBody := &bytes.Buffer{}
+		zlibWriter := zlib.NewWriter(zlibBody)
+		_, _ = zlibWriter.Write([]byte("device=sensor"))
+		_ = zlibWriter.Close()
+		status, body = post("/form", "application/x-www-form-urlencoded", "deflate", zlibBody)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "sensor", body)
+
+		rawBody := &bytes.Buffer{}
+		flateWriter, _ := flate.NewWriter(rawBody, flate.DefaultCompression)
+		_, _ = flateWriter.Write([]byte(`{"device":"raw"}`))
+		_ = flateWriter.Close()
+		status, body = post("/json", "application/json", "deflate", rawBody)
+		assert.Equal(t, 200, status, "Should accept raw deflate data")
+		assert.Equal(t, "raw", body)
+
+		status, body = post("/json", "application/json", "identity", bytes.NewBufferString(`{"device":"plain"}`))
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "plain", body)
+
+		bomb := gzipped("device=" + strings.Repeat("a", 1<<20))
+		status, body = post("/form", "application/x-www-form-urlencoded", "gzip", bomb)
+		assert.Equal(t, 413, status, "Should limit the decoded size")
+		assert.Contains(t, body, "max size of 64 bytes")
+
+		status, _ = post("/json", "application/json", "gzip", gzipped(`{"device":"`+strings.Repeat("a", 1<<20)+`"}`))
+		assert.NotEqual(t, 200, status, "Should limit the decoded size")
+
+		status, body = post("/json", "application/json", "gzip", bytes.NewBufferString("not gzip"))
+		assert.Equal(t, 400, status)
+		assert.Contains(t, body, "Content-Encoding")
+
+		corrupt := gzipped(`{"device":"sensor"}`).Bytes()
+		corrupt[len(corrupt)-5] ^= 0xff
+		status, _ = post("/json", "application/json", "gzip", bytes.NewBuffer(corrupt))
+		assert.Equal(t, 400, status, "Should fail on invalid checksums")
+
+		response, _ := govalinHTTP.Raw().Do(
+			"POST",
+			govalinHTTP.Host+"/json",
+			map[string]string{"Content-Type": "application/json", "Content-Encoding": "br"},
+			bytes.NewBufferString("{}"),
+		)
+		responseBody, _ := response.ToString()
+		assert.Equal(t, 415, response.StatusCode)
+		assert.Equal(t, "gzip, deflate", response.Header.Get("Accept-Encoding"))
+		assert.Contains(t, responseBody, "Unsupported content encoding 'br'")
+	})
+}
@@ -1,6 +1,10 @@
 package govalin
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/pkkummermo/govalin/internal/validation"
+)
 
 type govalinErrorType string
 
@@ -24,3 +28,14 @@ func newErrorFromType(errorType govalinErrorType, err error) error {
 		originalError: err,
 	}
 }
+
+// NewHTTPError returns an error which is written by Call.Error as an error
+// response with given status and optional detail.
+func NewHTTPError(status int, detail ...string) error {
+	errorResponse := validation.NewErrorResponse(status)
+	if len(detail) > 0 {
+		errorResponse.Detail = detail[0]
+	}
+
+	return validation.NewError(errorResponse)
+}
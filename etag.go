@@ -0,0 +1,209 @@
+package govalin
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+// ETagMode sets whether ETags are generated for responses written using Text,
+// HTML and JSON.
+type ETagMode int
+
+const (
+	etagModeDefault ETagMode = iota
+	// ETagDisabled disables generated ETags.
+	ETagDisabled
+	// ETagStrong generates strong ETags, promising byte for byte identical
+	// responses for equal ETags.
+	ETagStrong
+	// ETagWeak generates weak ETags, only promising semantically equivalent
+	// responses for equal ETags, such as when responses may be compressed.
+	ETagWeak
+)
+
+// ETag sets the ETag of the current representation of the resource and
+// evaluates the conditional headers of the request against it
+//
+// Returns false if the call has been responded to, in which case the handler
+// should return. Safe requests with a matching If-None-Match header are
+// responded to with 304 Not Modified. A non-matching If-Match header, or a
+// matching If-None-Match header on other requests, is responded to with 412
+// Precondition Failed. Call it before modifying a resource on PUT, PATCH and
+// DELETE to support optimistic concurrency. Quotes are added to the tag if it
+// isn't quoted already.
+//
+// The conditional headers are evaluated against the first ETag of the call, so
+// calling ETag again with the tag of the modified resource publishes it without
+// failing the preconditions. See SetETag to only set the tag.
+func (call *Call) ETag(etag string) bool {
+	call.SetETag(etag)
+
+	return call.checkPreconditions()
+}
+
+// SetETag sets the ETag of the response without evaluating the conditional
+// headers of the request, such as to publish the tag of a resource after
+// modifying it. Quotes are added to the tag if it isn't quoted already.
+func (call *Call) SetETag(etag string) {
+	if !strings.HasPrefix(etag, `"`) && !strings.HasPrefix(etag, `W/"`) {
+		etag = `"` + etag + `"`
+	}
+	call.w.Header().Set(headers.ETag, etag)
+}
+
+// LastModified sets the modification time of the current representation of the
+// resource and evaluates the conditional headers of the request against it
+//
+// Returns false if the call has been responded to, in which case the handler
+// should return. Safe requests where the resource hasn't been modified since
+// the If-Modified-Since header are responded to with 304 Not Modified. Requests
+// where the resource has been modified since the If-Unmodified-Since header
+// are responded to with 412 Precondition Failed. The ETag headers take
+// precedence when given.
+func (call *Call) LastModified(lastModified time.Time) bool {
+	call.w.Header().Set(headers.LastModified, lastModified.UTC().Format(http.TimeFormat))
+
+	return call.checkPreconditions()
+}
+
+// checkPreconditions evaluates the conditional headers of the request against
+// the ETag and Last-Modified headers of the response, responding with 304 Not
+// Modified or 412 Precondition Failed when appropriate. Returns false if the
+// call has been responded to.
+//
+// The validators are kept from the first evaluation, as the conditions apply to
+// the resource before the handler modifies it. Validators which weren't known
+// yet are added as they're set.
+func (call *Call) checkPreconditions() bool {
+	if call.statusWritten {
+		return false
+	}
+
+	if call.checkedETag == "" {
+		call.checkedETag = call.w.Header().Get(headers.ETag)
+	}
+	if call.checkedModified == "" {
+		call.checkedModified = call.w.Header().Get(headers.LastModified)
+	}
+
+	switch preconditionStatus(call.req, call.checkedETag, call.checkedModified) {
+	case http.StatusNotModified:
+		call.status = http.StatusNotModified
+		call.sendStatusOrDefault()
+		return false
+	case http.StatusPreconditionFailed:
+		call.Error(NewHTTPError(
+			http.StatusPreconditionFailed,
+			"The resource doesn't match the conditions of the request",
+		))
+		return false
+	default:
+		return true
+	}
+}
+
+// etagMode returns the ETag mode of the call, preferring the mode of the route.
+func (call *Call) etagMode() ETagMode {
+	if call.route != nil && call.route.etagMode != etagModeDefault {
+		return call.route.etagMode
+	}
+
+	return call.config.server.etagMode
+}
+
+// writeBody writes a response body of given content type, generating an ETag
+// for successful responses to safe requests when enabled.
+func (call *Call) writeBody(contentType string, body []byte) error {
+	mode := call.etagMode()
+	if mode != ETagDisabled && mode != etagModeDefault && isSafeMethod(call.req.Method) &&
+		!call.statusWritten && (call.status == 0 || (call.status >= 200 && call.status < 300)) &&
+		call.w.Header().Get(headers.ETag) == "" {
+		call.w.Header().Set(headers.ETag, hashETag(body, mode == ETagWeak))
+
+		if !call.checkPreconditions() {
+			return nil
+		}
+	}
+
+	call.w.Header().Add(headers.ContentType, contentType)
+	call.sendStatusOrDefault()
+
+	_, writeErr := call.w.Write(body)
+	return writeErr
+}
+
+// preconditionStatus evaluates the conditional headers of the request in the
+// order given by RFC 9110, returning 304, 412 or 0 if the request should be
+// handled. Headers are skipped while the validator they're compared with is
+// unknown, so they can be evaluated once it's set.
+func preconditionStatus(req *http.Request, etag string, lastModifiedHeader string) int {
+	lastModified, lastModifiedErr := http.ParseTime(lastModifiedHeader)
+	hasLastModified := lastModifiedErr == nil
+
+	if ifMatch := req.Header.Get(headers.IfMatch); ifMatch != "" {
+		if etag != "" && !etagMatches(ifMatch, etag, true) {
+			return http.StatusPreconditionFailed
+		}
+	} else if ifUnmodifiedSince, parseErr := http.ParseTime(req.Header.Get(headers.IfUnmodifiedSince)); parseErr == nil &&
+		hasLastModified && lastModified.After(ifUnmodifiedSince) {
+		return http.StatusPreconditionFailed
+	}
+
+	if ifNoneMatch := req.Header.Get(headers.IfNoneMatch); ifNoneMatch != "" {
+		if etag == "" || !etagMatches(ifNoneMatch, etag, false) {
+			return 0
+		}
+		if isSafeMethod(req.Method) {
+			return http.StatusNotModified
+		}
+		return http.StatusPreconditionFailed
+	}
+
+	if ifModifiedSince, parseErr := http.ParseTime(req.Header.Get(headers.IfModifiedSince)); parseErr == nil &&
+		hasLastModified && isSafeMethod(req.Method) && !lastModified.After(ifModifiedSince) {
+		return http.StatusNotModified
+	}
+
+	return 0
+}
+
+// etagMatches compares the list of ETags of a conditional header with given
+// ETag, using strong comparison for If-Match and weak comparison otherwise.
+// A '*' matches any current representation, including ones with weak ETags.
+func etagMatches(headerValue string, etag string, strong bool) bool {
+	for _, candidate := range strings.Split(headerValue, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strong && !strings.HasPrefix(etag, "W/") && candidate == etag {
+			return true
+		}
+		if !strong && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hashETag returns an ETag based on the SHA-256 hash of the body.
+func hashETag(body []byte, weak bool) string {
+	hash := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(hash[:16]) + `"`
+
+	if weak {
+		return "W/" + etag
+	}
+
+	return etag
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
@@ -0,0 +1,229 @@
+package govalin_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETags(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.ETags(govalin.ETagStrong)
+		}).
+			Get("/strong", func(call *govalin.Call) {
+				call.JSON(map[string]string{"govalin": "strong"})
+			}).
+			Get("/weak", func(call *govalin.Call) {
+				call.Text("weak")
+			}, func(route *govalin.Route) {
+				route.ETag(govalin.ETagWeak)
+			}).
+			Get("/disabled", func(call *govalin.Call) {
+				call.Text("disabled")
+			}, func(route *govalin.Route) {
+				route.ETag(govalin.ETagDisabled)
+			}).
+			Get("/created", func(call *govalin.Call) {
+				call.Status(http.StatusNotFound)
+				call.Text("not found")
+			}).
+			Post("/post", func(call *govalin.Call) {
+				call.Text("post")
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := govalinHTTP.GetResponse("/strong")
+		etag := response.Header.Get("ETag")
+		assert.True(t, strings.HasPrefix(etag, `"`), "Should generate a strong ETag")
+		assert.Equal(t, etag, govalinHTTP.GetResponse("/strong").Header.Get("ETag"), "Should be stable")
+
+		response, _ = govalinHTTP.Raw().Begin().WithHeader("If-None-Match", etag).Get(govalinHTTP.Host + "/strong")
+		body, _ := response.ToString()
+		assert.Equal(t, 304, response.StatusCode)
+		assert.Empty(t, body)
+		assert.Equal(t, etag, response.Header.Get("ETag"))
+
+		response, _ = govalinHTTP.Raw().Begin().WithHeader("If-None-Match", `"other"`).Get(govalinHTTP.Host + "/strong")
+		assert.Equal(t, 200, response.StatusCode)
+
+		response, _ = govalinHTTP.Raw().Begin().WithHeader("If-Match", `"other"`).Get(govalinHTTP.Host + "/strong")
+		assert.Equal(t, 412, response.StatusCode)
+
+		response = govalinHTTP.GetResponse("/weak")
+		weakETag := response.Header.Get("ETag")
+		assert.True(t, strings.HasPrefix(weakETag, `W/"`), "Should generate a weak ETag")
+
+		response, _ = govalinHTTP.Raw().Begin().WithHeader("If-None-Match", weakETag).Get(govalinHTTP.Host + "/weak")
+		assert.Equal(t, 304, response.StatusCode)
+
+		assert.Empty(t, govalinHTTP.GetResponse("/disabled").Header.Get("ETag"))
+		assert.Empty(t, govalinHTTP.GetResponse("/created").Header.Get("ETag"), "Should skip unsuccessful responses")
+		assert.Empty(t, govalinHTTP.PostResponse("/post", "").Header.Get("ETag"), "Should skip unsafe methods")
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	version := "1"
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/article", func(call *govalin.Call) {
+			if !call.LastModified(modified) {
+				return
+			}
+
+			call.Text("article")
+		})
+		app.Put("/article", func(call *govalin.Call) {
+			if !call.ETag(version) {
+				return
+			}
+
+			version = "2"
+			call.Text("updated")
+		})
+		app.Delete("/weak", func(call *govalin.Call) {
+			if !call.ETag(`W/"1"`) {
+				return
+			}
+
+			call.Text("deleted")
+		})
+
+		return app
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := govalinHTTP.GetResponse("/article")
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "Mon, 01 Jan 2024 12:00:00 GMT", response.Header.Get("Last-Modified"))
+
+		response, _ = govalinHTTP.Raw().Begin().
+			WithHeader("If-Modified-Since", "Mon, 01 Jan 2024 12:00:00 GMT").
+			Get(govalinHTTP.Host + "/article")
+		assert.Equal(t, 304, response.StatusCode)
+
+		response, _ = govalinHTTP.Raw().Begin().
+			WithHeader("If-Modified-Since", "Mon, 01 Jan 2024 11:59:59 GMT").
+			Get(govalinHTTP.Host + "/article")
+		assert.Equal(t, 200, response.StatusCode)
+
+		response, _ = govalinHTTP.Raw().Do(
+			http.MethodPut,
+			govalinHTTP.Host+"/article",
+			map[string]string{"If-Match": `"1"`},
+			strings.NewReader("update"),
+		)
+		body, _ := response.ToString()
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "updated", body)
+
+		response, _ = govalinHTTP.Raw().Do(
+			http.MethodPut,
+			govalinHTTP.Host+"/article",
+			map[string]string{"If-Match": `"1"`},
+			strings.NewReader("update"),
+		)
+		body, _ = response.ToString()
+		assert.Equal(t, 412, response.StatusCode, "Should refuse updates of stale versions")
+		assert.Contains(t, body, "doesn't match the conditions")
+		assert.Equal(t, "2", version)
+
+		response, _ = govalinHTTP.Raw().Do(
+			http.MethodPut,
+			govalinHTTP.Host+"/article",
+			map[string]string{"If-None-Match": "*"},
+			strings.NewReader("create"),
+		)
+		assert.Equal(t, 412, response.StatusCode, "Should refuse creating existing resources")
+
+		response, _ = govalinHTTP.Raw().Do(
+			http.MethodDelete,
+			govalinHTTP.Host+"/weak",
+			map[string]string{"If-Match": "*"},
+			nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, 200, response.StatusCode, "Should match any weak ETag with '*'")
+
+		response, _ = govalinHTTP.Raw().Do(
+			http.MethodDelete,
+			govalinHTTP.Host+"/weak",
+			map[string]string{"If-Match": `W/"1"`},
+			nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, 412, response.StatusCode, "Should not match weak ETags using strong comparison")
+	})
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	version := 1
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/counter", func(call *govalin.Call) {
+			if !call.ETag(fmt.Sprintf("v%d", version)) {
+				return
+			}
+
+			call.Text(fmt.Sprint(version))
+		})
+		app.Put("/counter", func(call *govalin.Call) {
+			if !call.ETag(fmt.Sprintf("v%d", version)) {
+				return
+			}
+
+			version++
+			if !call.ETag(fmt.Sprintf("v%d", version)) {
+				return
+			}
+
+			call.Text(fmt.Sprint(version))
+		})
+		app.Patch("/counter", func(call *govalin.Call) {
+			if !call.ETag(fmt.Sprintf("v%d", version)) {
+				return
+			}
+
+			version++
+			call.SetETag(fmt.Sprintf("v%d", version))
+			call.Text(fmt.Sprint(version))
+		})
+
+		return app
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := govalinHTTP.GetResponse("/counter")
+		etag := response.Header.Get("ETag")
+		assert.Equal(t, `"v1"`, etag)
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodPut, govalinHTTP.Host+"/counter",
+			map[string]string{"If-Match": etag}, strings.NewReader("increment"))
+		body, _ := response.ToString()
+		assert.Equal(t, 200, response.StatusCode, "Should not evaluate the preconditions against the new ETag")
+		assert.Equal(t, "2", body)
+		etag = response.Header.Get("ETag")
+		assert.Equal(t, `"v2"`, etag, "Should publish the ETag of the updated resource")
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodPatch, govalinHTTP.Host+"/counter",
+			map[string]string{"If-Match": etag}, strings.NewReader("increment"))
+		body, _ = response.ToString()
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "3", body)
+		etag = response.Header.Get("ETag")
+		assert.Equal(t, `"v3"`, etag)
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodPut, govalinHTTP.Host+"/counter",
+			map[string]string{"If-Match": `"v2"`}, strings.NewReader("increment"))
+		_ = response.Body.Close()
+		assert.Equal(t, 412, response.StatusCode, "Should refuse updates of stale versions")
+
+		response, _ = govalinHTTP.Raw().Begin().WithHeader("If-None-Match", etag).Get(govalinHTTP.Host + "/counter")
+		assert.Equal(t, 304, response.StatusCode, "Should accept the published ETag")
+		assert.Equal(t, 3, version)
+	})
+}
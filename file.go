@@ -0,0 +1,250 @@
+package govalin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/internal/http/writers"
+)
+
+const (
+	dispositionAttachment = "attachment"
+	dispositionInline     = "inline"
+)
+
+// SendFileFunc configures how a file is sent by SendFile and SendFSFile.
+type SendFileFunc func(config *SendFileConfig)
+
+// SendFileConfig contains configuration for sending a file.
+type SendFileConfig struct {
+	disposition string
+	filename    string
+	contentType string
+	etag        bool
+}
+
+func newSendFileConfig() *SendFileConfig {
+	return &SendFileConfig{
+		disposition: "",
+		filename:    "",
+		contentType: "",
+		etag:        true,
+	}
+}
+
+// Attachment makes the client download the file instead of displaying it. The
+// filename defaults to the name of the file.
+func (config *SendFileConfig) Attachment(filename ...string) *SendFileConfig {
+	config.disposition = dispositionAttachment
+	if len(filename) > 0 {
+		config.filename = filename[0]
+	}
+
+	return config
+}
+
+// Inline makes the client display the file if possible, while using the given
+// filename if the user saves it.
+func (config *SendFileConfig) Inline(filename ...string) *SendFileConfig {
+	config.disposition = dispositionInline
+	if len(filename) > 0 {
+		config.filename = filename[0]
+	}
+
+	return config
+}
+
+// ContentType sets the content type of the file. Defaults to detecting the
+// content type from the file extension and content.
+func (config *SendFileConfig) ContentType(contentType string) *SendFileConfig {
+	config.contentType = contentType
+
+	return config
+}
+
+// ETag sets whether to send a weak ETag based on the size and modification
+// time of the file, letting clients revalidate using If-None-Match. Enabled by
+// default.
+func (config *SendFileConfig) ETag(enabled bool) *SendFileConfig {
+	config.etag = enabled
+
+	return config
+}
+
+// SendFile sends the file at given path as the response
+//
+// Range requests, including multiple ranges, and conditional requests using
+// If-Modified-Since and If-None-Match are handled, responding with 206 Partial
+// Content, 304 Not Modified or 416 Range Not Satisfiable as appropriate. The
+// content type is detected from the file extension and content. Returns an
+// error which can be passed to Call.Error if the file can't be opened, such as
+// a 404 Not Found error if it doesn't exist.
+func (call *Call) SendFile(path string, configFuncs ...SendFileFunc) error {
+	file, openErr := os.Open(path)
+	if openErr != nil {
+		return fileError(path, openErr)
+	}
+	defer file.Close()
+
+	return call.SendFSFile(file, configFuncs...)
+}
+
+// SendFSFile sends the given file as the response, such as a file opened from
+// an embedded or bundled fs.FS. Behaves like SendFile, but range requests are
+// only supported for files implementing io.Seeker. The file is not closed.
+func (call *Call) SendFSFile(file fs.File, configFuncs ...SendFileFunc) error {
+	config := newSendFileConfig()
+	for _, configFunc := range configFuncs {
+		configFunc(config)
+	}
+
+	info, statErr := file.Stat()
+	if statErr != nil {
+		return fileError("", statErr)
+	}
+	if info.IsDir() {
+		return NewHTTPError(http.StatusNotFound, fmt.Sprintf("'%s' is a directory", info.Name()))
+	}
+
+	responseHeaders := call.w.Header()
+
+	if config.disposition != "" {
+		filename := config.filename
+		if filename == "" {
+			filename = info.Name()
+		}
+		responseHeaders.Set(headers.ContentDisposition, ContentDisposition(config.disposition, filename))
+	}
+
+	if config.contentType != "" {
+		responseHeaders.Set(headers.ContentType, config.contentType)
+	}
+
+	if config.etag && responseHeaders.Get(headers.ETag) == "" {
+		responseHeaders.Set(headers.ETag, fmt.Sprintf(
+			`W/"%s-%s"`,
+			strconv.FormatInt(info.Size(), 16),
+			strconv.FormatInt(info.ModTime().UnixNano(), 16),
+		))
+	}
+
+	seeker, seekable := file.(io.ReadSeeker)
+	if !seekable {
+		return call.sendUnseekableFile(file, info)
+	}
+
+	recorder := &statusRecorder{Wrapper: writers.Wrapper{ResponseWriter: call.w}}
+	http.ServeContent(recorder, call.req, info.Name(), info.ModTime(), seeker)
+
+	call.status = recorder.status
+	call.statusWritten = true
+
+	return nil
+}
+
+// sendUnseekableFile sends the full file, as ranges can't be served without seeking.
+func (call *Call) sendUnseekableFile(file fs.File, info fs.FileInfo) error {
+	responseHeaders := call.w.Header()
+
+	if !info.ModTime().IsZero() {
+		responseHeaders.Set(headers.LastModified, info.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	if !call.checkPreconditions() {
+		return nil
+	}
+
+	if responseHeaders.Get(headers.ContentType) == "" {
+		responseHeaders.Set(headers.ContentType, mimeTypeByName(info.Name()))
+	}
+	responseHeaders.Set(headers.AcceptRanges, "none")
+	responseHeaders.Set(headers.ContentLength, strconv.FormatInt(info.Size(), 10))
+
+	return call.Stream(responseHeaders.Get(headers.ContentType), file)
+}
+
+// ContentDisposition formats a Content-Disposition header value with given
+// disposition type and filename as specified by RFC 6266. Non-ASCII filenames
+// are encoded using the filename* parameter, with an ASCII fallback for older
+// clients.
+func ContentDisposition(disposition string, filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' || r == '"' || r == '\\' || r == '%' {
+			return '_'
+		}
+		return r
+	}, filename)
+
+	value := fmt.Sprintf(`%s; filename="%s"`, disposition, fallback)
+	if fallback != filename {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+
+	return value
+}
+
+// encodeRFC5987 percent-encodes all bytes which are not attr-chars.
+func encodeRFC5987(value string) string {
+	var builder strings.Builder
+
+	for _, b := range []byte(value) {
+		if isAttrChar(b) {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+
+	return builder.String()
+}
+
+func isAttrChar(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') ||
+		strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+func mimeTypeByName(name string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
+}
+
+func fileError(path string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewHTTPError(http.StatusNotFound, fmt.Sprintf("The file '%s' doesn't exist", filepath.Base(path)))
+	}
+
+	return fmt.Errorf("failed to open file '%s'. %w", path, err)
+}
+
+// statusRecorder records the status written to the response.
+type statusRecorder struct {
+	writers.Wrapper
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+
+	return recorder.ResponseWriter.Write(data)
+}
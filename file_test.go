@@ -0,0 +1,110 @@
+package govalin_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("0123456789"), 0o600))
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/file", func(call *govalin.Call) {
+			if err := call.SendFile(path); err != nil {
+				call.Error(err)
+			}
+		})
+		app.Get("/download", func(call *govalin.Call) {
+			if err := call.SendFile(path, func(config *govalin.SendFileConfig) {
+				config.Attachment("rapport ø.txt")
+			}); err != nil {
+				call.Error(err)
+			}
+		})
+		app.Get("/missing", func(call *govalin.Call) {
+			if err := call.SendFile(filepath.Join(dir, "missing.txt")); err != nil {
+				call.Error(err)
+			}
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/file")
+		body, _ := response.ToString()
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "0123456789", body)
+		assert.Contains(t, response.Header.Get("Content-Type"), "text/plain")
+		assert.Equal(t, "bytes", response.Header.Get("Accept-Ranges"))
+		etag := response.Header.Get("ETag")
+		assert.NotEmpty(t, etag)
+
+		response, _ = http.Raw().Begin().WithHeader("Range", "bytes=2-4").Get(http.Host + "/file")
+		body, _ = response.ToString()
+		assert.Equal(t, 206, response.StatusCode)
+		assert.Equal(t, "234", body)
+
+		response, _ = http.Raw().Begin().WithHeader("Range", "bytes=0-1,8-9").Get(http.Host + "/file")
+		body, _ = response.ToString()
+		assert.Equal(t, 206, response.StatusCode)
+		assert.Contains(t, response.Header.Get("Content-Type"), "multipart/byteranges")
+		assert.Contains(t, body, "01")
+		assert.Contains(t, body, "89")
+
+		response, _ = http.Raw().Begin().WithHeader("Range", "bytes=20-30").Get(http.Host + "/file")
+		assert.Equal(t, 416, response.StatusCode)
+
+		response, _ = http.Raw().Begin().WithHeader("If-None-Match", etag).Get(http.Host + "/file")
+		assert.Equal(t, 304, response.StatusCode)
+
+		response, _ = http.Raw().Begin().
+			WithHeader("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT")).
+			Get(http.Host + "/file")
+		assert.Equal(t, 304, response.StatusCode)
+
+		response = http.GetResponse("/download")
+		assert.Equal(
+			t,
+			`attachment; filename="rapport _.txt"; filename*=UTF-8''rapport%20%C3%B8.txt`,
+			response.Header.Get("Content-Disposition"),
+		)
+
+		assert.Equal(t, 404, http.GetResponse("/missing").StatusCode)
+	})
+}
+
+func TestSendFSFile(t *testing.T) {
+	files := fstest.MapFS{
+		"data.json": &fstest.MapFile{Data: []byte(`{"govalin":true}`), ModTime: time.Now()},
+	}
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/data", func(call *govalin.Call) {
+			file, _ := files.Open("data.json")
+			defer file.Close()
+
+			if err := call.SendFSFile(file, func(config *govalin.SendFileConfig) {
+				config.Inline()
+			}); err != nil {
+				call.Error(err)
+			}
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/data")
+		body, _ := response.ToString()
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, `{"govalin":true}`, body)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+		assert.Equal(t, `inline; filename="data.json"`, response.Header.Get("Content-Disposition"))
+	})
+}
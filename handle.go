@@ -0,0 +1,293 @@
+package govalin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/validation"
+	"github.com/pkkummermo/govalin/openapi"
+)
+
+const (
+	pathTag   = "path"
+	queryTag  = "query"
+	headerTag = "header"
+)
+
+// TypedHandlerFunc handles a call using a typed request and response.
+type TypedHandlerFunc[Req, Resp any] func(ctx context.Context, call *Call, req Req) (Resp, error)
+
+// Empty is used as the request or response type of typed handlers without a
+// body.
+type Empty struct{}
+
+// Handle creates a handler from a typed handler function
+//
+// The request is bound into a new Req before calling the function. A JSON body
+// is unmarshalled into it, and fields tagged with `path:"name"`, `query:"name"`
+// or `header:"name"` are set from the path params, query params and headers of
+// the call. Fields bound from params should be tagged with `json:"-"` to keep
+// them out of the body. The request is then validated using its `govalin`
+// struct tags. Use Empty as Req for requests without any input.
+//
+// The returned Resp is rendered as JSON with the success status of the route,
+// see Route.SuccessStatus, unless the function has written the response itself.
+// Responses of type Empty are sent without a body, defaulting to 204 No Content.
+// Errors are sent through Call.Error, use NewHTTPError to respond with a
+// specific status.
+func Handle[Req, Resp any](handlerFunc TypedHandlerFunc[Req, Resp]) HandlerFunc {
+	return func(call *Call) {
+		var req Req
+		if bindErr := bindRequest(call, &req); bindErr != nil {
+			call.Error(bindErr)
+			return
+		}
+
+		resp, handlerErr := handlerFunc(call.Context(), call, req)
+		if handlerErr != nil {
+			call.Error(handlerErr)
+			return
+		}
+
+		if call.statusWritten {
+			return
+		}
+
+		_, isEmpty := any(resp).(Empty)
+		if call.status == 0 {
+			call.status = successStatus(call.route, isEmpty)
+		}
+
+		if isEmpty {
+			call.sendStatusOrDefault()
+			return
+		}
+
+		call.JSON(resp)
+	}
+}
+
+// Describe documents the request and response types of a typed handler function
+// for generated OpenAPI documents. Use it together with Handle.
+//
+//	app.Post("/users", govalin.Handle(createUser), govalin.Describe(createUser))
+func Describe[Req, Resp any](_ TypedHandlerFunc[Req, Resp]) RouteFunc {
+	return func(route *Route) {
+		route.requestType = reflect.TypeFor[Req]()
+		route.responseType = reflect.TypeFor[Resp]()
+	}
+}
+
+func successStatus(route *Route, isEmpty bool) int {
+	switch {
+	case route != nil && route.successStatus != 0:
+		return route.successStatus
+	case isEmpty:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
+
+func bindRequest(call *Call, req any) error {
+	reqValue := reflect.ValueOf(req).Elem()
+	if reqValue.Type() == reflect.TypeFor[Empty]() {
+		return nil
+	}
+
+	hasBody := call.req.ContentLength > 0 || len(call.req.TransferEncoding) > 0
+	if hasBody {
+		if bodyErr := call.BodyAs(req); bodyErr != nil {
+			return bodyErr
+		}
+	}
+
+	if reqValue.Kind() == reflect.Struct {
+		if bindErr := bindParams(call, reqValue); bindErr != nil {
+			return bindErr
+		}
+	}
+
+	if err := validation.ValidateTags(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindParams sets the fields of given struct tagged with a param tag.
+func bindParams(call *Call, structValue reflect.Value) error {
+	details := []validation.ErrorDetail{}
+
+	for i := range structValue.NumField() {
+		field := structValue.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, values := paramValues(call, field)
+		if len(values) == 0 {
+			continue
+		}
+
+		if !setParamValue(structValue.Field(i), values) {
+			details = append(details, validation.NewParameterErrorDetail(
+				name,
+				fmt.Sprintf("Incorrect type. '%s' is not of type '%s'", strings.Join(values, ","), field.Type),
+			))
+		}
+	}
+
+	if len(details) > 0 {
+		return validation.NewError(validation.NewErrorResponse(http.StatusBadRequest, details...))
+	}
+
+	return nil
+}
+
+// paramValues returns the name and values of the param the field is bound to.
+func paramValues(call *Call, field reflect.StructField) (string, []string) {
+	if name, ok := field.Tag.Lookup(pathTag); ok {
+		if value, exists := call.pathParams[name]; exists {
+			return name, []string{value}
+		}
+		return name, nil
+	}
+
+	if name, ok := field.Tag.Lookup(queryTag); ok {
+		return name, call.URL().Query()[name]
+	}
+
+	if name, ok := field.Tag.Lookup(headerTag); ok {
+		return name, call.req.Header.Values(name)
+	}
+
+	return "", nil
+}
+
+// setParamValue parses the values into given field. Returns false if the values
+// can't be parsed as the type of the field.
+func setParamValue(fieldValue reflect.Value, values []string) bool {
+	if fieldValue.Kind() == reflect.Pointer {
+		pointer := reflect.New(fieldValue.Type().Elem())
+		if !setParamValue(pointer.Elem(), values) {
+			return false
+		}
+		fieldValue.Set(pointer)
+		return true
+	}
+
+	if fieldValue.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fieldValue.Type(), len(values), len(values))
+		for i, value := range values {
+			if !setParamValue(slice.Index(i), []string{value}) {
+				return false
+			}
+		}
+		fieldValue.Set(slice)
+		return true
+	}
+
+	value := values[0]
+
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(value)
+	case reflect.Bool:
+		parsed, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			return false
+		}
+		fieldValue.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, parseErr := strconv.ParseInt(value, 10, fieldValue.Type().Bits())
+		if parseErr != nil {
+			return false
+		}
+		fieldValue.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, parseErr := strconv.ParseUint(value, 10, fieldValue.Type().Bits())
+		if parseErr != nil {
+			return false
+		}
+		fieldValue.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, parseErr := strconv.ParseFloat(value, fieldValue.Type().Bits())
+		if parseErr != nil {
+			return false
+		}
+		fieldValue.SetFloat(parsed)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// typedParams returns the documentation of the params bound by the request type.
+func typedParams(requestType reflect.Type, generator *openapi.SchemaGenerator) []*openapi.Parameter {
+	params := []*openapi.Parameter{}
+	if requestType.Kind() != reflect.Struct {
+		return params
+	}
+
+	for i := range requestType.NumField() {
+		field := requestType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		for _, location := range []struct{ tag, in string }{
+			{pathTag, openapi.InPath},
+			{queryTag, openapi.InQuery},
+			{headerTag, openapi.InHeader},
+		} {
+			name, ok := field.Tag.Lookup(location.tag)
+			if !ok {
+				continue
+			}
+
+			params = append(params, &openapi.Parameter{
+				Name:     name,
+				In:       location.in,
+				Required: location.in == openapi.InPath || isRequired(field),
+				Schema:   generator.SchemaFor(field.Type),
+			})
+		}
+	}
+
+	return params
+}
+
+// hasBodyFields returns true if the request type is read from the body.
+func hasBodyFields(requestType reflect.Type) bool {
+	if requestType == reflect.TypeFor[Empty]() {
+		return false
+	}
+	if requestType.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := range requestType.NumField() {
+		field := requestType.Field(i)
+		if field.IsExported() && validation.JSONFieldName(field) != "-" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range validation.ParseTag(field.Tag.Get(validation.TagName)) {
+		if rule.Name == validation.TagRequired {
+			return true
+		}
+	}
+
+	return false
+}
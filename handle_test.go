@@ -0,0 +1,123 @@
+package govalin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type createPetRequest struct {
+	Owner  string `json:"-"    path:"owner"`
+	DryRun bool   `json:"-"    query:"dryRun"`
+	Name   string `json:"name" govalin:"required,minLength=2"`
+}
+
+type petResponse struct {
+	Owner  string `json:"owner"`
+	Name   string `json:"name"`
+	DryRun bool   `json:"dryRun"`
+}
+
+type getPetRequest struct {
+	ID int `json:"-" path:"id"`
+}
+
+func createPet(_ context.Context, _ *govalin.Call, req createPetRequest) (petResponse, error) {
+	return petResponse{Owner: req.Owner, Name: req.Name, DryRun: req.DryRun}, nil
+}
+
+func getPet(_ context.Context, _ *govalin.Call, req getPetRequest) (petResponse, error) {
+	if req.ID != 1 {
+		return petResponse{}, govalin.NewHTTPError(http.StatusNotFound, "No such pet")
+	}
+
+	return petResponse{Name: "Fido"}, nil
+}
+
+func deletePet(_ context.Context, _ *govalin.Call, _ govalin.Empty) (govalin.Empty, error) {
+	return govalin.Empty{}, nil
+}
+
+func failingPet(_ context.Context, _ *govalin.Call, _ govalin.Empty) (petResponse, error) {
+	return petResponse{}, errors.New("database is down")
+}
+
+func TestHandle(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Post("/owners/{owner}/pets", govalin.Handle(createPet), func(route *govalin.Route) {
+			route.SuccessStatus(http.StatusCreated)
+		})
+		app.Get("/pets/{id}", govalin.Handle(getPet))
+		app.Delete("/pets/{id}", govalin.Handle(deletePet))
+		app.Get("/failing", govalin.Handle(failingPet))
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		response, _ := http.Raw().Begin().
+			PostJson(http.Host+"/owners/govalin/pets?dryRun=true", map[string]string{"name": "Fido"})
+		body, _ := response.ToString()
+		assert.Equal(t, 201, response.StatusCode, "Should use the success status of the route")
+		assert.JSONEq(t, `{"owner":"govalin","name":"Fido","dryRun":true}`, body)
+
+		response, _ = http.Raw().Begin().PostJson(http.Host+"/owners/govalin/pets", map[string]string{"name": "F"})
+		body, _ = response.ToString()
+		assert.Equal(t, 400, response.StatusCode)
+		assert.Contains(t, body, "Must be at least 2 characters long")
+
+		response, _ = http.Raw().Begin().PostJson(http.Host+"/owners/govalin/pets?dryRun=maybe", map[string]string{"name": "Fido"})
+		body, _ = response.ToString()
+		assert.Equal(t, 400, response.StatusCode)
+		assert.Contains(t, body, "Incorrect type. 'maybe' is not of type 'bool'")
+
+		response = http.GetResponse("/pets/1")
+		body, _ = response.ToString()
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Contains(t, body, "Fido")
+
+		response = http.GetResponse("/pets/2")
+		body, _ = response.ToString()
+		assert.Equal(t, 404, response.StatusCode, "Should send errors through Call.Error")
+		assert.Contains(t, body, "No such pet")
+
+		response = http.DeleteResponse("/pets/1")
+		body, _ = response.ToString()
+		assert.Equal(t, 204, response.StatusCode)
+		assert.Empty(t, body)
+
+		assert.Equal(t, 500, http.GetResponse("/failing").StatusCode)
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	app := govalin.New()
+	app.Post("/owners/{owner}/pets", govalin.Handle(createPet), govalin.Describe(createPet), func(route *govalin.Route) {
+		route.SuccessStatus(http.StatusCreated)
+	})
+	app.Delete("/pets/{id}", govalin.Handle(deletePet), govalin.Describe(deletePet))
+
+	document := app.OpenAPI()
+
+	createOperation := document.Paths["/owners/{owner}/pets"].Post
+	assert.Equal(t, "owner", createOperation.Parameters[0].Name)
+	assert.Equal(t, "dryRun", createOperation.Parameters[1].Name)
+	assert.Equal(t, openapi.SchemaType{openapi.TypeBoolean}, createOperation.Parameters[1].Schema.Type)
+	assert.NotNil(t, createOperation.RequestBody)
+	assert.Equal(
+		t,
+		"#/components/schemas/petResponse",
+		createOperation.Responses["201"].Content["application/json"].Schema.Ref,
+	)
+	assert.NotNil(t, createOperation.Responses["400"])
+	assert.NotContains(t, document.Components.Schemas["createPetRequest"].Properties, "owner")
+
+	deleteOperation := document.Paths["/pets/{id}"].Delete
+	assert.Nil(t, deleteOperation.RequestBody)
+	assert.NotNil(t, deleteOperation.Responses["204"])
+	assert.Nil(t, deleteOperation.Responses["200"])
+}
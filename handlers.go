@@ -10,8 +10,8 @@ import (
 type pathHandler struct {
 	PathFragment string
 	PathMatcher  routing.PathMatcher
-	Before       BeforeFunc
-	After        AfterFunc
+	Before       []BeforeFunc
+	After        []AfterFunc
 	Head         HandlerFunc
 	Get          HandlerFunc
 	Post         HandlerFunc
@@ -19,6 +19,7 @@ type pathHandler struct {
 	Put          HandlerFunc
 	Delete       HandlerFunc
 	Options      HandlerFunc
+	Routes       map[string]*Route
 }
 
 func newPathHandlerFromPathFragment(pathFragment string) (pathHandler, error) {
@@ -39,6 +40,7 @@ func newPathHandlerFromPathFragment(pathFragment string) (pathHandler, error) {
 		Put:          nil,
 		Delete:       nil,
 		Options:      nil,
+		Routes:       map[string]*Route{},
 	}, nil
 }
 
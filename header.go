@@ -0,0 +1,319 @@
+package govalin
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+// MediaRange is a media range of the Accept header, such as 'text/*;q=0.8'.
+type MediaRange struct {
+	Type    string            // Type is the lowercased type, or '*'
+	Subtype string            // Subtype is the lowercased subtype, or '*'
+	Params  map[string]string // Params are the parameters other than the quality
+	Quality float64           // Quality is the relative preference from 0 to 1
+}
+
+// String returns the media range without parameters, such as 'text/*'.
+func (mediaRange MediaRange) String() string {
+	return mediaRange.Type + "/" + mediaRange.Subtype
+}
+
+// Matches returns whether the media range includes given content type.
+// Parameters of the content type are ignored.
+func (mediaRange MediaRange) Matches(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mainType, subtype, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+
+	return (mediaRange.Type == "*" || mediaRange.Type == mainType) &&
+		(mediaRange.Subtype == "*" || mediaRange.Subtype == subtype)
+}
+
+// specificity ranks exact media ranges before wildcards, and ranges with
+// parameters before those without.
+func (mediaRange MediaRange) specificity() int {
+	switch {
+	case mediaRange.Type == "*":
+		return 0
+	case mediaRange.Subtype == "*":
+		return 1
+	case len(mediaRange.Params) == 0:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// LanguageRange is a language range of the Accept-Language header, such as
+// 'en-GB;q=0.8'.
+type LanguageRange struct {
+	Tag     string  // Tag is the language tag, or '*'
+	Quality float64 // Quality is the relative preference from 0 to 1
+}
+
+// Matches returns whether the language range includes given language tag, using
+// the basic filtering of RFC 4647. The range 'en' matches both 'en' and 'en-GB'.
+func (languageRange LanguageRange) Matches(tag string) bool {
+	if languageRange.Tag == "*" {
+		return true
+	}
+
+	return strings.EqualFold(languageRange.Tag, tag) ||
+		(len(tag) > len(languageRange.Tag) && tag[len(languageRange.Tag)] == '-' &&
+			strings.EqualFold(languageRange.Tag, tag[:len(languageRange.Tag)]))
+}
+
+// ForwardedElement is a single element of the Forwarded header, added by each
+// proxy forwarding the request. Values are unquoted, and are empty when not
+// given. Node values, such as For, may be an IP address with an optional port,
+// an obfuscated identifier such as '_proxy1' or 'unknown'. IPv6 addresses are
+// enclosed in brackets, such as '[2001:db8::1]:8080'.
+type ForwardedElement struct {
+	For   string // For is the node making the request to the proxy
+	By    string // By is the node receiving the request at the proxy
+	Host  string // Host is the Host header of the request received by the proxy
+	Proto string // Proto is the protocol used to make the request to the proxy
+}
+
+// Accept returns the media ranges of the Accept headers of the request, sorted
+// by preference. Ranges are sorted by quality, then more specific ranges before
+// wildcards. Ranges with a quality of 0 are not acceptable. Returns nil if the
+// header isn't given.
+func (call *Call) Accept() []MediaRange {
+	var mediaRanges []MediaRange
+
+	for _, element := range splitHeaderElements(call.HeaderValues(headers.Accept), ',') {
+		params := splitHeaderElements([]string{element}, ';')
+		mainType, subtype, found := strings.Cut(strings.ToLower(params[0]), "/")
+		if !found || mainType == "" || subtype == "" {
+			continue
+		}
+
+		mediaRange := MediaRange{Type: mainType, Subtype: subtype, Params: map[string]string{}, Quality: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(param, "=")
+			key = strings.ToLower(strings.TrimSpace(key))
+			if key == "q" {
+				mediaRange.Quality = parseQuality(value)
+				continue
+			}
+			mediaRange.Params[key] = unquoteHeaderValue(strings.TrimSpace(value))
+		}
+
+		mediaRanges = append(mediaRanges, mediaRange)
+	}
+
+	slices.SortStableFunc(mediaRanges, func(a MediaRange, b MediaRange) int {
+		if byQuality := cmp.Compare(b.Quality, a.Quality); byQuality != 0 {
+			return byQuality
+		}
+		return cmp.Compare(b.specificity(), a.specificity())
+	})
+
+	return mediaRanges
+}
+
+// Accepts returns the offered content type preferred by the Accept headers of
+// the request, or an empty string if none of them are acceptable. The quality
+// of an offer is given by the most specific media range matching it, and ties
+// are resolved by the order of the offers. Returns the first offer if the
+// header isn't given.
+func (call *Call) Accepts(offers ...string) string {
+	mediaRanges := call.Accept()
+	if len(mediaRanges) == 0 {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	return bestOffer(offers, func(offer string) float64 {
+		quality := 0.0
+		specificity := -1
+		for _, mediaRange := range mediaRanges {
+			if mediaRange.Matches(offer) && mediaRange.specificity() > specificity {
+				quality = mediaRange.Quality
+				specificity = mediaRange.specificity()
+			}
+		}
+		return quality
+	})
+}
+
+// AcceptLanguage returns the language ranges of the Accept-Language headers of
+// the request, sorted by quality. Ranges with a quality of 0 are not
+// acceptable. Returns nil if the header isn't given.
+func (call *Call) AcceptLanguage() []LanguageRange {
+	var languageRanges []LanguageRange
+
+	for _, element := range splitHeaderElements(call.HeaderValues(headers.AcceptLanguage), ',') {
+		params := splitHeaderElements([]string{element}, ';')
+		if params[0] == "" {
+			continue
+		}
+
+		languageRange := LanguageRange{Tag: params[0], Quality: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.EqualFold(strings.TrimSpace(key), "q") {
+				languageRange.Quality = parseQuality(value)
+			}
+		}
+
+		languageRanges = append(languageRanges, languageRange)
+	}
+
+	slices.SortStableFunc(languageRanges, func(a LanguageRange, b LanguageRange) int {
+		return cmp.Compare(b.Quality, a.Quality)
+	})
+
+	return languageRanges
+}
+
+// AcceptsLanguage returns the offered language tag preferred by the
+// Accept-Language headers of the request, or an empty string if none of them
+// are acceptable. The quality of an offer is given by the longest language
+// range matching it, and ties are resolved by the order of the offers. Returns
+// the first offer if the header isn't given.
+func (call *Call) AcceptsLanguage(offers ...string) string {
+	languageRanges := call.AcceptLanguage()
+	if len(languageRanges) == 0 {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	return bestOffer(offers, func(offer string) float64 {
+		quality := 0.0
+		length := -1
+		for _, languageRange := range languageRanges {
+			tagLength := len(languageRange.Tag)
+			if languageRange.Tag == "*" {
+				tagLength = 0
+			}
+			if languageRange.Matches(offer) && tagLength > length {
+				quality = languageRange.Quality
+				length = tagLength
+			}
+		}
+		return quality
+	})
+}
+
+// Forwarded returns the elements of the Forwarded headers of the request, in
+// the order they were added by proxies. The last element is added by the proxy
+// closest to the server. Returns nil if the header isn't given.
+//
+// The header can be set by any client, so it should only be trusted when added
+// by known proxies.
+func (call *Call) Forwarded() []ForwardedElement {
+	var elements []ForwardedElement
+
+	for _, element := range splitHeaderElements(call.HeaderValues(headers.Forwarded), ',') {
+		forwarded := ForwardedElement{}
+		for _, pair := range splitHeaderElements([]string{element}, ';') {
+			key, value, _ := strings.Cut(pair, "=")
+			value = unquoteHeaderValue(strings.TrimSpace(value))
+
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "for":
+				forwarded.For = value
+			case "by":
+				forwarded.By = value
+			case "host":
+				forwarded.Host = value
+			case "proto":
+				forwarded.Proto = strings.ToLower(value)
+			}
+		}
+
+		if forwarded != (ForwardedElement{}) {
+			elements = append(elements, forwarded)
+		}
+	}
+
+	return elements
+}
+
+// bestOffer returns the offer with the highest quality above 0, preferring
+// earlier offers on ties.
+func bestOffer(offers []string, quality func(offer string) float64) string {
+	best := ""
+	bestQuality := 0.0
+	for _, offer := range offers {
+		if offerQuality := quality(offer); offerQuality > bestQuality {
+			best = offer
+			bestQuality = offerQuality
+		}
+	}
+
+	return best
+}
+
+// splitHeaderElements splits the header values by given separator, ignoring
+// separators within quoted strings. Elements are trimmed, and empty elements
+// are skipped, except for the first element when splitting by ';' so it can be
+// used as the value before the parameters.
+func splitHeaderElements(values []string, separator byte) []string {
+	var elements []string
+
+	for _, value := range values {
+		start := 0
+		quoted := false
+		for i := 0; i < len(value); i++ {
+			switch {
+			case quoted && value[i] == '\\':
+				i++
+			case value[i] == '"':
+				quoted = !quoted
+			case !quoted && value[i] == separator:
+				elements = appendHeaderElement(elements, value[start:i], separator)
+				start = i + 1
+			}
+		}
+		elements = appendHeaderElement(elements, value[start:], separator)
+	}
+
+	return elements
+}
+
+func appendHeaderElement(elements []string, element string, separator byte) []string {
+	element = strings.TrimSpace(element)
+	if element == "" && (separator != ';' || len(elements) > 0) {
+		return elements
+	}
+
+	return append(elements, element)
+}
+
+// unquoteHeaderValue removes the quotes and escapes of a quoted string, or
+// returns the value as is if it isn't quoted.
+func unquoteHeaderValue(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+
+	var unquoted strings.Builder
+	for i := 1; i < len(value)-1; i++ {
+		if value[i] == '\\' && i+1 < len(value)-1 {
+			i++
+		}
+		unquoted.WriteByte(value[i])
+	}
+
+	return unquoted.String()
+}
+
+// parseQuality parses a quality value, treating invalid values as 0.
+func parseQuality(value string) float64 {
+	quality, parseErr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if parseErr != nil || quality < 0 {
+		return 0
+	}
+
+	return min(quality, 1)
+}
@@ -0,0 +1,119 @@
+package govalin_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+// getWithHeaders requests the url with given, possibly repeated, headers and
+// returns the response and its body.
+func getWithHeaders(t *testing.T, url string, requestHeaders http.Header) (*http.Response, string) {
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	req.Header = requestHeaders
+
+	response, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+
+	return response, string(body)
+}
+
+func TestMultiValueHeaders(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+		})
+		app.Before("/*", func(call *govalin.Call) bool {
+			call.Header("X-Plugin", "first")
+			call.Header("X-Removed", "removed")
+			return true
+		})
+
+		return app.
+			Get("/headers", func(call *govalin.Call) {
+				call.SetHeader("X-Plugin", "replaced", "twice")
+				call.RemoveHeader("X-Removed")
+				call.Text(strings.Join(call.HeaderValues("x-values"), "|"))
+			}).
+			Get("/query", func(call *govalin.Call) {
+				call.Text(fmt.Sprintf("%q %q", call.QueryParams("tag"), call.QueryParams("missing")))
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, body := getWithHeaders(t, govalinHTTP.Host+"/headers", http.Header{"X-Values": {"a", "b, c"}})
+		assert.Equal(t, "a|b, c", body)
+		assert.Equal(t, []string{"replaced", "twice"}, response.Header.Values("X-Plugin"))
+		assert.Empty(t, response.Header.Values("X-Removed"))
+
+		assert.Equal(t, `["a" "b"] []`, govalinHTTP.Get("/query?tag=a&tag=b"))
+	})
+}
+
+func TestContentNegotiation(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+		}).
+			Get("/accept", func(call *govalin.Call) {
+				ranges := []string{}
+				for _, mediaRange := range call.Accept() {
+					ranges = append(ranges, fmt.Sprintf("%s;q=%g", mediaRange, mediaRange.Quality))
+				}
+				call.Text(strings.Join(ranges, ",") + " " + call.Accepts("application/json", "text/html"))
+			}).
+			Get("/language", func(call *govalin.Call) {
+				tags := []string{}
+				for _, languageRange := range call.AcceptLanguage() {
+					tags = append(tags, languageRange.Tag)
+				}
+				call.Text(strings.Join(tags, ",") + " " + call.AcceptsLanguage("en-US", "nb-NO", "de"))
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		_, body := getWithHeaders(t, govalinHTTP.Host+"/accept", http.Header{
+			"Accept": {"*/*;q=0.1, application/json;q=0.4", `text/html;level="1, 2";q=0.5, text/*`},
+		})
+		assert.Equal(t, "text/*;q=1,text/html;q=0.5,application/json;q=0.4,*/*;q=0.1 text/html", body)
+
+		_, body = getWithHeaders(t, govalinHTTP.Host+"/accept", http.Header{"Accept": {"text/*, text/html;q=0"}})
+		assert.True(t, strings.HasSuffix(body, " "), "Should not accept refused types")
+
+		_, body = getWithHeaders(t, govalinHTTP.Host+"/accept", http.Header{})
+		assert.Equal(t, " application/json", body, "Should accept the first offer without header")
+
+		_, body = getWithHeaders(t, govalinHTTP.Host+"/language", http.Header{
+			"Accept-Language": {"nb;q=0.9, en;q=0.8, en-US;q=0.7, *;q=0.1"},
+		})
+		assert.Equal(t, "nb,en,en-US,* nb-NO", body)
+	})
+}
+
+func TestForwarded(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+		}).
+			Get("/forwarded", func(call *govalin.Call) {
+				call.JSON(call.Forwarded())
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		_, body := getWithHeaders(t, govalinHTTP.Host+"/forwarded", http.Header{
+			"Forwarded": {
+				`for=192.0.2.60;proto=HTTPS;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"`,
+				`for=_proxy;host="example.com;v=1", invalid`,
+			},
+		})
+		assert.JSONEq(t, `[
+			{"For":"192.0.2.60","By":"203.0.113.43","Host":"","Proto":"https"},
+			{"For":"[2001:db8:cafe::17]:4711","By":"","Host":"","Proto":""},
+			{"For":"_proxy","By":"","Host":"example.com;v=1","Proto":""}
+		]`, body)
+	})
+}
@@ -5,8 +5,12 @@ const (
 	ApplicationFormURLEncoded = "application/x-www-form-urlencoded"
 	// ApplicationJSON is the content type for JSON data.
 	ApplicationJSON = "application/json"
+	// ApplicationNDJSON is the content type for newline delimited JSON data.
+	ApplicationNDJSON = "application/x-ndjson"
 	// MultiPartFormData is the content type for multipart form data.
 	MultipartFormData = "multipart/form-data"
+	// TextEventStream is the content type for server-sent events.
+	TextEventStream = "text/event-stream"
 	// TextHTML is the content type for HTML data.
 	TextHTML = "text/html"
 	// TextPlain is the content type for plain text data.
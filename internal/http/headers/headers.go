@@ -36,6 +36,7 @@ const (
 	ETag                          = "ETag"
 	Expect                        = "Expect"
 	Expires                       = "Expires"
+	Forwarded                     = "Forwarded"
 	From                          = "From"
 	Host                          = "Host"
 	IfMatch                       = "If-Match"
@@ -43,6 +44,7 @@ const (
 	IfNoneMatch                   = "If-None-Match"
 	IfRange                       = "If-Range"
 	IfUnmodifiedSince             = "If-Unmodified-Since"
+	LastEventID                   = "Last-Event-ID"
 	LastModified                  = "Last-Modified"
 	Link                          = "Link"
 	Location                      = "Location"
@@ -52,6 +54,10 @@ const (
 	ProxyAuthenticate             = "Proxy-Authenticate"
 	ProxyAuthorization            = "Proxy-Authorization"
 	Range                         = "Range"
+	RateLimitLimit                = "RateLimit-Limit"
+	RateLimitPolicy               = "RateLimit-Policy"
+	RateLimitRemaining            = "RateLimit-Remaining"
+	RateLimitReset                = "RateLimit-Reset"
 	Referer                       = "Referer"
 	RefererPolicy                 = "Referer-Policy"
 	RetryAfter                    = "Retry-After"
@@ -71,6 +77,7 @@ const (
 	XAccelBuffering               = "X-Accel-Buffering"
 	XContentTypeOptions           = "X-Content-Type-Options"
 	XForwardedFor                 = "X-Forwarded-For"
+	XForwardedHost                = "X-Forwarded-Host"
 	XForwardedProto               = "X-Forwarded-Proto"
 	XFrameOptions                 = "X-Frame-Options"
 	XHttpMethodOverride           = "X-HTTP-Method-Override"
@@ -0,0 +1,47 @@
+package writers
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// Wrapper is embedded by response writer wrappers to pass flushing and
+// hijacking through to the wrapped writer, which is required by features
+// like websockets that type assert the response writer.
+type Wrapper struct {
+	http.ResponseWriter
+}
+
+// Unwrap returns the wrapped response writer. Used by http.ResponseController.
+func (wrapper *Wrapper) Unwrap() http.ResponseWriter {
+	return wrapper.ResponseWriter
+}
+
+// Flush flushes buffered data of the wrapped writer if supported.
+func (wrapper *Wrapper) Flush() {
+	_ = http.NewResponseController(wrapper.ResponseWriter).Flush()
+}
+
+// Hijack lets the caller take over the connection of the wrapped writer.
+func (wrapper *Wrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(wrapper.ResponseWriter).Hijack()
+}
+
+// Find returns the first writer of type T found by unwrapping given writer.
+func Find[T http.ResponseWriter](w http.ResponseWriter) (T, bool) {
+	for w != nil {
+		if found, ok := w.(T); ok {
+			return found, true
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = unwrapper.Unwrap()
+	}
+
+	var notFound T
+	return notFound, false
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql/driver"
 	"encoding/base64"
@@ -61,6 +62,13 @@ type Store interface {
 	RemoveSessionData(sessionID string) error
 }
 
+// Pinger is implemented by stores which can check whether their back-end is
+// reachable, such as stores backed by a database.
+type Pinger interface {
+	// Ping returns an error if the back-end of the store can't be reached.
+	Ping(ctx context.Context) error
+}
+
 // CreateNewSessionID generates a random session id. Also
 // check for collisions so we don't accidentally assign an existing session id.
 func CreateNewSessionID(s Store) (string, error) {
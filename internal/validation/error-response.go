@@ -13,6 +13,12 @@ var defaultErrorMessages = map[int]string{
 	404: "Not found",
 	405: "Method not allowed",
 	409: "Conflict",
+	410: "Gone",
+	412: "Precondition failed",
+	413: "Request entity too large",
+	415: "Unsupported media type",
+	423: "Locked",
+	429: "Too many requests",
 	500: "Server error",
 	501: "Not implemented",
 	502: "Bad gateway",
@@ -0,0 +1,228 @@
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TagName is the struct tag holding validation rules, for example
+// `govalin:"required,minLength=3,maxLength=20"`. It isn't named `validate`, so
+// tags written for other validation libraries, which use rules of the same names
+// with other meanings, aren't misread.
+const TagName = "govalin"
+
+// Supported validation tag rule names.
+const (
+	TagRequired  = "required"
+	TagMinLength = "minLength"
+	TagMaxLength = "maxLength"
+	TagMin       = "min"
+	TagMax       = "max"
+	TagEmail     = "email"
+	TagOneOf     = "oneOf"
+)
+
+// TagRule is a single parsed rule from a validation struct tag.
+type TagRule struct {
+	Name  string
+	Value string
+}
+
+// Int returns the rule value as an integer.
+func (rule TagRule) Int() (int, error) {
+	return strconv.Atoi(rule.Value)
+}
+
+// Values returns the rule value split on spaces, as used by oneOf.
+func (rule TagRule) Values() []string {
+	return strings.Fields(rule.Value)
+}
+
+// ParseTag parses a validation struct tag into its rules.
+func ParseTag(tag string) []TagRule {
+	rules := []TagRule{}
+
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(part, "=")
+		rules = append(rules, TagRule{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+
+	return rules
+}
+
+// ValidateTags validates the fields of given struct, or pointer to struct, based
+// on the validation struct tags. Nested structs are validated recursively and the
+// field names in the returned error use the JSON names of the fields.
+func ValidateTags(data interface{}) *Error {
+	return validateTagsWithPrefix(reflect.ValueOf(data), "")
+}
+
+func validateTagsWithPrefix(value reflect.Value, prefix string) *Error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonName := JSONFieldName(field)
+		if jsonName == "-" {
+			continue
+		}
+		fieldName := prefix + jsonName
+
+		fieldValue := value.Field(i)
+		// Embedded structs without a JSON name are flattened by encoding/json
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			if err := validateTagsWithPrefix(fieldValue, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, rule := range ParseTag(field.Tag.Get(TagName)) {
+			if err := validateTagRule(rule, fieldValue, fieldName); err != nil {
+				return err
+			}
+		}
+
+		if err := validateTagsWithPrefix(fieldValue, fieldName+"."); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateTagRule(rule TagRule, value reflect.Value, fieldName string) *Error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			if rule.Name == TagRequired {
+				return newTagError(fieldName, "This field is required")
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch rule.Name {
+	case TagRequired:
+		if value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "" {
+			return newTagError(fieldName, "This field is required")
+		}
+		if (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0 {
+			return newTagError(fieldName, "This field is required")
+		}
+	case TagMinLength, TagMaxLength:
+		limit, err := rule.Int()
+		if err != nil || !hasLength(value) {
+			return nil
+		}
+		if rule.Name == TagMinLength && lengthOf(value) < limit {
+			return newTagError(fieldName, fmt.Sprintf("Must be at least %d characters long", limit))
+		}
+		if rule.Name == TagMaxLength && lengthOf(value) > limit {
+			return newTagError(fieldName, fmt.Sprintf("Must be at most %d characters long", limit))
+		}
+	case TagMin, TagMax:
+		limit, err := strconv.ParseFloat(rule.Value, 64)
+		number, isNumber := numberOf(value)
+		if err != nil || !isNumber {
+			return nil
+		}
+		if rule.Name == TagMin && number < limit {
+			return newTagError(fieldName, fmt.Sprintf("Must be at least %s", rule.Value))
+		}
+		if rule.Name == TagMax && number > limit {
+			return newTagError(fieldName, fmt.Sprintf("Must be at most %s", rule.Value))
+		}
+	case TagEmail:
+		if value.Kind() == reflect.String && value.String() != "" && !strings.Contains(value.String(), "@") {
+			return newTagError(fieldName, "Must be a valid email address")
+		}
+	case TagOneOf:
+		if value.Kind() != reflect.String || value.String() == "" {
+			return nil
+		}
+		for _, allowed := range rule.Values() {
+			if value.String() == allowed {
+				return nil
+			}
+		}
+		return newTagError(fieldName, fmt.Sprintf("Must be one of '%s'", strings.Join(rule.Values(), "', '")))
+	}
+
+	return nil
+}
+
+// JSONFieldName returns the name used for the field when serialized as JSON.
+// Returns "-" if the field is ignored by encoding/json.
+func JSONFieldName(field reflect.StructField) string {
+	jsonTag := field.Tag.Get("json")
+	if jsonTag == "-" {
+		return "-"
+	}
+
+	name, _, _ := strings.Cut(jsonTag, ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+func hasLength(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+func lengthOf(value reflect.Value) int {
+	if value.Kind() == reflect.String {
+		return len([]rune(value.String()))
+	}
+
+	return value.Len()
+}
+
+func numberOf(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func newTagError(fieldName string, reason string) *Error {
+	return NewError(NewErrorResponse(
+		http.StatusBadRequest,
+		NewParameterErrorDetail(fieldName, reason),
+	))
+}
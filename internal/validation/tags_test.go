@@ -0,0 +1,51 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/pkkummermo/govalin/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedAddress struct {
+	Street string `json:"street" govalin:"required"`
+}
+
+type taggedPerson struct {
+	Name    *string        `json:"name"    govalin:"required"`
+	Tags    []string       `json:"tags"    govalin:"maxLength=2"`
+	Address *taggedAddress `json:"address"`
+	Score   float64        `json:"score"   govalin:"min=0.5"`
+}
+
+func TestParseTag(t *testing.T) {
+	assert.Equal(t, []validation.TagRule{
+		{Name: "required"},
+		{Name: "minLength", Value: "3"},
+		{Name: "oneOf", Value: "a b"},
+	}, validation.ParseTag("required, minLength=3,,oneOf=a b"))
+	assert.Empty(t, validation.ParseTag(""))
+}
+
+func TestValidateTags(t *testing.T) {
+	name := "govalin"
+
+	assert.Nil(t, validation.ValidateTags(&taggedPerson{Name: &name, Score: 1}))
+	assert.Nil(t, validation.ValidateTags("not a struct"))
+
+	err := validation.ValidateTags(taggedPerson{Score: 1})
+	assert.NotNil(t, err)
+	assert.Equal(t, "name", err.ErrorResponse.Details[0].Field, "Should require nil pointers")
+
+	err = validation.ValidateTags(taggedPerson{Name: &name, Tags: []string{"a", "b", "c"}, Score: 1})
+	assert.NotNil(t, err)
+	assert.Equal(t, "tags", err.ErrorResponse.Details[0].Field)
+
+	err = validation.ValidateTags(taggedPerson{Name: &name, Address: &taggedAddress{}, Score: 1})
+	assert.NotNil(t, err)
+	assert.Equal(t, "address.street", err.ErrorResponse.Details[0].Field, "Should validate nested structs")
+
+	err = validation.ValidateTags(taggedPerson{Name: &name, Score: 0.1})
+	assert.NotNil(t, err)
+	assert.Equal(t, "Must be at least 0.5", err.ErrorResponse.Details[0].Reason)
+}
@@ -0,0 +1,183 @@
+package govalin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// minKeySize is the min size in bytes of keys in a keyring.
+const minKeySize = 32
+
+var (
+	// ErrInvalidValue is returned when a signed or encrypted value has been
+	// tampered with, or was created using a key which isn't in the keyring.
+	ErrInvalidValue = errors.New("invalid signed or encrypted value")
+	// ErrExpiredValue is returned when a signed or encrypted value has expired.
+	ErrExpiredValue = errors.New("expired signed or encrypted value")
+)
+
+// Keyring contains the secret keys used to sign and encrypt values, such as
+// cookies
+//
+// The first key is used to sign and encrypt new values, while all keys are
+// tried when verifying and decrypting values. Keys can be rotated by adding a
+// new key first, and removing old keys once values created using them have
+// expired. Separate keys for signing and encrypting are derived from each key,
+// and values are bound to a name, so a value created for one cookie can't be
+// used as another.
+type Keyring struct {
+	signingKeys    [][]byte
+	encryptionKeys []cipher.AEAD
+}
+
+// NewKeyring creates a keyring of given keys, where the first key is used for
+// new values. Keys must be random and at least 32 bytes long.
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("a keyring requires at least one key")
+	}
+
+	keyring := &Keyring{}
+	for i, key := range keys {
+		if len(key) < minKeySize {
+			return nil, fmt.Errorf("key %d is %d bytes, but must be at least %d bytes", i, len(key), minKeySize)
+		}
+
+		block, blockErr := aes.NewCipher(deriveKey(key, "encryption"))
+		if blockErr != nil {
+			return nil, fmt.Errorf("failed to create cipher. %w", blockErr)
+		}
+		aead, aeadErr := cipher.NewGCM(block)
+		if aeadErr != nil {
+			return nil, fmt.Errorf("failed to create cipher. %w", aeadErr)
+		}
+
+		keyring.signingKeys = append(keyring.signingKeys, deriveKey(key, "signing"))
+		keyring.encryptionKeys = append(keyring.encryptionKeys, aead)
+	}
+
+	return keyring, nil
+}
+
+// Sign returns the value signed using HMAC-SHA256, bound to given name. The
+// value is readable by anyone, but can't be changed without being detected.
+// Values never expire if expiresAt is the zero time.
+func (keyring *Keyring) Sign(name string, value []byte, expiresAt time.Time) string {
+	payload := withExpiry(value, expiresAt)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signature(keyring.signingKeys[0], name, payload))
+}
+
+// Verify returns the value of a signed value bound to given name. Returns
+// ErrInvalidValue if the value has been tampered with and ErrExpiredValue if
+// it has expired.
+func (keyring *Keyring) Verify(name string, signed string) ([]byte, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(signed, ".")
+	if !found {
+		return nil, ErrInvalidValue
+	}
+
+	payload, payloadErr := base64.RawURLEncoding.DecodeString(encodedPayload)
+	givenSignature, signatureErr := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if payloadErr != nil || signatureErr != nil {
+		return nil, ErrInvalidValue
+	}
+
+	for _, key := range keyring.signingKeys {
+		if hmac.Equal(givenSignature, signature(key, name, payload)) {
+			return withoutExpiry(payload)
+		}
+	}
+
+	return nil, ErrInvalidValue
+}
+
+// Encrypt returns the value encrypted using AES-GCM, bound to given name. The
+// value can neither be read nor changed without the keys. Values never expire
+// if expiresAt is the zero time.
+func (keyring *Keyring) Encrypt(name string, value []byte, expiresAt time.Time) (string, error) {
+	aead := keyring.encryptionKeys[0]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+8+aead.Overhead())
+	if _, randErr := rand.Read(nonce); randErr != nil {
+		return "", fmt.Errorf("failed to generate nonce. %w", randErr)
+	}
+
+	encrypted := aead.Seal(nonce, nonce, withExpiry(value, expiresAt), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(encrypted), nil
+}
+
+// Decrypt returns the value of an encrypted value bound to given name. Returns
+// ErrInvalidValue if the value has been tampered with and ErrExpiredValue if
+// it has expired.
+func (keyring *Keyring) Decrypt(name string, encrypted string) ([]byte, error) {
+	data, decodeErr := base64.RawURLEncoding.DecodeString(encrypted)
+	if decodeErr != nil {
+		return nil, ErrInvalidValue
+	}
+
+	for _, aead := range keyring.encryptionKeys {
+		if len(data) < aead.NonceSize() {
+			continue
+		}
+
+		payload, openErr := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+		if openErr == nil {
+			return withoutExpiry(payload)
+		}
+	}
+
+	return nil, ErrInvalidValue
+}
+
+// deriveKey derives a key for given purpose, so the same key isn't used for
+// both signing and encrypting.
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("govalin " + purpose))
+
+	return mac.Sum(nil)
+}
+
+func signature(key []byte, name string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+// withExpiry prefixes the value with the expiry as unix seconds, or 0 if the
+// value never expires.
+func withExpiry(value []byte, expiresAt time.Time) []byte {
+	var expiry int64
+	if !expiresAt.IsZero() {
+		expiry = expiresAt.Unix()
+	}
+
+	return append(binary.BigEndian.AppendUint64(nil, uint64(expiry)), value...)
+}
+
+func withoutExpiry(payload []byte) ([]byte, error) {
+	if len(payload) < 8 {
+		return nil, ErrInvalidValue
+	}
+
+	expiry := int64(binary.BigEndian.Uint64(payload[:8]))
+	if expiry != 0 && time.Now().Unix() >= expiry {
+		return nil, ErrExpiredValue
+	}
+
+	return payload[8:], nil
+}
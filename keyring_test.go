@@ -0,0 +1,72 @@
+package govalin_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	oldKey = bytes.Repeat([]byte("o"), 32)
+	newKey = bytes.Repeat([]byte("n"), 32)
+)
+
+func TestKeyring(t *testing.T) {
+	_, keyringErr := govalin.NewKeyring([]byte("short"))
+	assert.Error(t, keyringErr, "Should refuse short keys")
+	_, keyringErr = govalin.NewKeyring()
+	assert.Error(t, keyringErr, "Should require a key")
+
+	oldKeyring, _ := govalin.NewKeyring(oldKey)
+	rotatedKeyring, _ := govalin.NewKeyring(newKey, oldKey)
+	newKeyring, _ := govalin.NewKeyring(newKey)
+
+	signed := oldKeyring.Sign("name", []byte("govalin"), time.Time{})
+	value, verifyErr := rotatedKeyring.Verify("name", signed)
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, "govalin", string(value), "Should verify values signed using old keys")
+
+	_, verifyErr = newKeyring.Verify("name", signed)
+	assert.ErrorIs(t, verifyErr, govalin.ErrInvalidValue, "Should refuse values signed using removed keys")
+	_, verifyErr = rotatedKeyring.Verify("other", signed)
+	assert.ErrorIs(t, verifyErr, govalin.ErrInvalidValue, "Should bind values to the name")
+	_, verifyErr = rotatedKeyring.Verify("name", "Z"+signed[1:])
+	assert.ErrorIs(t, verifyErr, govalin.ErrInvalidValue, "Should refuse tampered values")
+	_, verifyErr = rotatedKeyring.Verify("name", "garbage")
+	assert.ErrorIs(t, verifyErr, govalin.ErrInvalidValue)
+
+	expired := rotatedKeyring.Sign("name", []byte("govalin"), time.Now().Add(-time.Second))
+	_, verifyErr = rotatedKeyring.Verify("name", expired)
+	assert.ErrorIs(t, verifyErr, govalin.ErrExpiredValue)
+
+	encrypted, encryptErr := oldKeyring.Encrypt("name", []byte("secret"), time.Now().Add(time.Hour))
+	assert.NoError(t, encryptErr)
+	assert.NotContains(t, encrypted, "secret")
+	value, decryptErr := rotatedKeyring.Decrypt("name", encrypted)
+	assert.NoError(t, decryptErr)
+	assert.Equal(t, "secret", string(value), "Should decrypt values encrypted using old keys")
+
+	other, _ := oldKeyring.Encrypt("name", []byte("secret"), time.Time{})
+	assert.NotEqual(t, encrypted, other, "Should use random nonces")
+
+	_, decryptErr = newKeyring.Decrypt("name", encrypted)
+	assert.ErrorIs(t, decryptErr, govalin.ErrInvalidValue)
+	_, decryptErr = rotatedKeyring.Decrypt("other", encrypted)
+	assert.ErrorIs(t, decryptErr, govalin.ErrInvalidValue)
+	tampered := encrypted[:len(encrypted)-1] + strings.Map(func(r rune) rune {
+		if r == 'A' {
+			return 'B'
+		}
+		return 'A'
+	}, encrypted[len(encrypted)-1:])
+	_, decryptErr = rotatedKeyring.Decrypt("name", tampered)
+	assert.ErrorIs(t, decryptErr, govalin.ErrInvalidValue)
+
+	expired, _ = rotatedKeyring.Encrypt("name", []byte("secret"), time.Now().Add(-time.Second))
+	_, decryptErr = rotatedKeyring.Decrypt("name", expired)
+	assert.ErrorIs(t, decryptErr, govalin.ErrExpiredValue)
+}
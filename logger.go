@@ -0,0 +1,44 @@
+package govalin
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger returns a logger for the call, adding the call ID, method, route
+// pattern, session ID and trace IDs of the call to the records of the default
+// logger, so records can be correlated with the call. The route pattern is
+// added once the endpoint handler has been matched. Session IDs grant access
+// to sessions, so redact the session_id attribute when logs are widely
+// accessible, such as using ReplaceAttr of the structured logging plugin.
+func (call *Call) Logger() *slog.Logger {
+	attrs := []any{
+		slog.String("call_id", call.id),
+		slog.String("method", call.Method()),
+	}
+	if call.routePattern != "" {
+		attrs = append(attrs, slog.String("route", call.routePattern))
+	}
+	if call.session.ID != "" {
+		attrs = append(attrs, slog.String("session_id", call.session.ID))
+	}
+	if call.traceContext.TraceID != "" {
+		attrs = append(attrs,
+			slog.String("trace_id", call.traceContext.TraceID),
+			slog.String("span_id", call.traceContext.SpanID),
+		)
+	}
+
+	return slog.Default().With(attrs...)
+}
+
+// LoggerFromContext returns the logger of the call the context belongs to, see
+// Call.Logger. Returns the default logger if the context doesn't belong to a
+// call.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if call, ok := ctx.Value(callContextKey).(*Call); ok {
+		return call.Logger()
+	}
+
+	return slog.Default()
+}
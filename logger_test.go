@@ -0,0 +1,59 @@
+package govalin_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallLogger(t *testing.T) {
+	logs := &logBuffer{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	loadUser := func(ctx context.Context, id string) {
+		govalin.LoggerFromContext(ctx).Info("loading user", "user_id", id)
+	}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.EnableStartupLog(false)
+			config.EnableSessions()
+		}).Get("/users/{id}", func(call *govalin.Call) {
+			call.Logger().Info("handling user")
+			loadUser(call.Context(), call.PathParam("id"))
+			call.Text(call.ID() + " " + call.TraceContext().TraceID)
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, _ := govalinHTTP.Raw().Do(
+			"GET",
+			govalinHTTP.Host+"/users/42",
+			map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			nil,
+		)
+		body, _ := response.ToString()
+		callID := body[:len(body)-33]
+
+		records := logs.records(t, 2)
+		assert.Len(t, records, 2)
+		for _, record := range records {
+			assert.Equal(t, callID, record["call_id"])
+			assert.Equal(t, "GET", record["method"])
+			assert.Equal(t, "/users/{id}", record["route"])
+			assert.NotEmpty(t, record["session_id"])
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+			assert.Len(t, record["span_id"], 16)
+		}
+		assert.Equal(t, "handling user", records[0]["msg"])
+		assert.Equal(t, "loading user", records[1]["msg"])
+		assert.Equal(t, "42", records[1]["user_id"], "Should reach the logger from the context")
+	})
+
+	assert.Equal(t, slog.Default(), govalin.LoggerFromContext(context.Background()))
+}
@@ -0,0 +1,406 @@
+package govalin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/http/contenttypes"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/internal/validation"
+)
+
+// MultipartReader iterates over the parts of a multipart body as they're read
+// from the client, without spooling them to memory or disk.
+type MultipartReader struct {
+	call         *Call
+	reader       *multipart.Reader
+	maxFiles     int
+	maxFileSize  int64
+	maxFieldSize int64
+	files        int
+}
+
+// MultipartPart is a single part of a multipart body
+//
+// Reading a part fails with a 413 Request Entity Too Large validation error
+// when it exceeds the max file size for file parts, or the max body read size
+// for other fields.
+type MultipartPart struct {
+	*multipart.Part
+	call      *Call
+	limit     int64
+	remaining int64
+}
+
+// SavedFile describes a file part saved by MultipartPart.Save or
+// MultipartPart.SaveTemp.
+type SavedFile struct {
+	Path        string // Path is the path the file was saved to
+	FieldName   string // FieldName is the form field name of the part
+	Filename    string // Filename is the file name given by the client
+	ContentType string // ContentType is the content type given by the client
+	Size        int64  // Size is the number of bytes saved
+	SHA256      string // SHA256 is the hex encoded SHA-256 hash of the content
+}
+
+// MultipartReader returns a reader for streaming the parts of a multipart/form-data
+// body
+//
+// Use it instead of FormParam, File and Files to handle big uploads as they
+// arrive. The number of files and the size of each file are limited by
+// Config.ServerMaxFiles and Config.ServerMaxFileSize, or the limits of the route.
+// The body can only be read once, so it can't be combined with other methods
+// reading the body.
+func (call *Call) MultipartReader() (*MultipartReader, error) {
+	if !strings.Contains(call.Header(headers.ContentType), contenttypes.MultipartFormData) {
+		return nil, validation.NewError(validation.NewErrorResponse(
+			http.StatusBadRequest,
+			validation.NewParameterErrorDetail(
+				headers.ContentType,
+				"Missing or invalid '"+headers.ContentType+"' header. "+
+					"Must be '"+contenttypes.MultipartFormData+"'",
+			),
+		))
+	}
+
+	if decodeErr := call.decodeBody(); decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	reader, readerErr := call.req.MultipartReader()
+	if readerErr != nil {
+		slog.Error("Failed to read multipart body", "err", readerErr)
+		return nil, newInvalidFormDataError()
+	}
+
+	maxFiles, maxFileSize := call.fileLimits()
+
+	return &MultipartReader{
+		call:         call,
+		reader:       reader,
+		maxFiles:     maxFiles,
+		maxFileSize:  maxFileSize,
+		maxFieldSize: call.config.server.maxBodyReadSize,
+	}, nil
+}
+
+// NextPart returns the next part of the body, or io.EOF when there are no more
+// parts. Any unread data of the previous part is discarded.
+func (reader *MultipartReader) NextPart() (*MultipartPart, error) {
+	part, partErr := reader.reader.NextPart()
+	if errors.Is(partErr, io.EOF) {
+		return nil, io.EOF
+	}
+	if partErr != nil {
+		slog.Error("Failed to read multipart body", "err", partErr)
+		return nil, newInvalidFormDataError()
+	}
+
+	limit := reader.maxFieldSize
+	if part.FileName() != "" {
+		reader.files++
+		if reader.files > reader.maxFiles {
+			return nil, newRequestTooLargeError(
+				part.FormName(),
+				fmt.Sprintf("Too many files. A max of %d files are accepted", reader.maxFiles),
+			)
+		}
+		limit = reader.maxFileSize
+	}
+
+	return &MultipartPart{
+		Part:      part,
+		call:      reader.call,
+		limit:     limit,
+		remaining: limit,
+	}, nil
+}
+
+// Parts returns an iterator over the remaining parts of the body. Iteration
+// stops after the first error, which is yielded with a nil part.
+func (reader *MultipartReader) Parts() iter.Seq2[*MultipartPart, error] {
+	return func(yield func(*MultipartPart, error) bool) {
+		for {
+			part, partErr := reader.NextPart()
+			if errors.Is(partErr, io.EOF) {
+				return
+			}
+			if !yield(part, partErr) || partErr != nil {
+				return
+			}
+		}
+	}
+}
+
+// IsFile returns whether the part is a file.
+func (part *MultipartPart) IsFile() bool {
+	return part.FileName() != ""
+}
+
+// Read reads the content of the part, failing once the size limit of the part
+// is exceeded.
+func (part *MultipartPart) Read(data []byte) (int, error) {
+	if part.remaining <= 0 {
+		// Parts which are exactly the size of the limit are allowed, so only
+		// fail when there's more data to read
+		read, readErr := io.ReadFull(part.Part, make([]byte, 1))
+		if read > 0 {
+			return 0, part.limitError()
+		}
+
+		return 0, readErr
+	}
+
+	if int64(len(data)) > part.remaining {
+		data = data[:part.remaining]
+	}
+
+	read, readErr := part.Part.Read(data)
+	part.remaining -= int64(read)
+
+	return read, readErr
+}
+
+// Value reads the content of the part as a string, such as the value of a
+// non-file form field.
+func (part *MultipartPart) Value() (string, error) {
+	value, readErr := io.ReadAll(part)
+	if readErr != nil {
+		return "", part.readError(readErr)
+	}
+
+	return string(value), nil
+}
+
+// Save writes the content of the part to a file at given path while hashing it.
+// The file is removed again if the part can't be read completely, such as when
+// it exceeds the max file size.
+func (part *MultipartPart) Save(path string) (*SavedFile, error) {
+	file, createErr := os.Create(path)
+	if createErr != nil {
+		return nil, fmt.Errorf("failed to create file '%s'. %w", path, createErr)
+	}
+
+	return part.saveTo(file)
+}
+
+// SaveTemp writes the content of the part to a temporary file while hashing it.
+// The file is removed when the call has completed, so it must be moved or copied
+// by the handler to be kept.
+func (part *MultipartPart) SaveTemp() (*SavedFile, error) {
+	file, createErr := os.CreateTemp("", "govalin-upload-*")
+	if createErr != nil {
+		return nil, fmt.Errorf("failed to create temporary file. %w", createErr)
+	}
+
+	path := file.Name()
+	part.call.OnComplete(func() {
+		if removeErr := os.Remove(path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			slog.Error("Failed to remove temporary upload", "path", path, "err", removeErr)
+		}
+	})
+
+	return part.saveTo(file)
+}
+
+func (part *MultipartPart) saveTo(file *os.File) (*SavedFile, error) {
+	hash := sha256.New()
+
+	size, copyErr := io.Copy(io.MultiWriter(file, hash), part)
+	closeErr := file.Close()
+	if copyErr != nil || closeErr != nil {
+		_ = os.Remove(file.Name())
+
+		if copyErr != nil {
+			return nil, part.readError(copyErr)
+		}
+		return nil, fmt.Errorf("failed to save file '%s'. %w", file.Name(), closeErr)
+	}
+
+	return &SavedFile{
+		Path:        file.Name(),
+		FieldName:   part.FormName(),
+		Filename:    part.FileName(),
+		ContentType: part.Header.Get(headers.ContentType),
+		Size:        size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// readError returns validation errors as is, and a form data validation error
+// for errors reading the body.
+func (part *MultipartPart) readError(err error) error {
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		return validationErr
+	}
+
+	slog.Error("Failed to read multipart part", "err", err)
+	return newInvalidFormDataError()
+}
+
+func (part *MultipartPart) limitError() error {
+	if part.IsFile() {
+		return newRequestTooLargeError(
+			part.FormName(),
+			fmt.Sprintf("The file '%s' exceeds the max file size of %d bytes", part.FileName(), part.limit),
+		)
+	}
+
+	return newRequestTooLargeError(
+		part.FormName(),
+		fmt.Sprintf("The field exceeds the max size of %d bytes", part.limit),
+	)
+}
+
+// fileLimits returns the max number of files and max file size for the call,
+// preferring the limits of the route.
+func (call *Call) fileLimits() (int, int64) {
+	maxFiles := call.config.server.maxFiles
+	maxFileSize := call.config.server.maxFileSize
+
+	if call.route != nil {
+		if call.route.maxFiles > 0 {
+			maxFiles = call.route.maxFiles
+		}
+		if call.route.maxFileSize > 0 {
+			maxFileSize = call.route.maxFileSize
+		}
+	}
+
+	return maxFiles, maxFileSize
+}
+
+// parseMultipartForm parses the multipart body, spooling files to disk, while
+// enforcing the file limits of the call.
+//
+// The parts are streamed through a MultipartReader, which stops each part at its
+// size limit, before being handed to the standard library for spooling. Files
+// exceeding the limits are rejected as they're read instead of after having been
+// written to disk in full.
+func (call *Call) parseMultipartForm() error {
+	if call.req.MultipartForm != nil {
+		return nil
+	}
+
+	reader, readerErr := call.MultipartReader()
+	if readerErr != nil {
+		return readerErr
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		pipeWriter.CloseWithError(copyMultipartParts(reader, writer))
+	}()
+
+	form, parseErr := multipart.NewReader(pipeReader, writer.Boundary()).ReadForm(0)
+	// Stop the copying of parts if parsing failed early, and wait for it to
+	// finish so the body isn't read after the call has completed
+	_ = pipeReader.Close()
+	<-copied
+
+	if parseErr != nil {
+		var validationErr *validation.Error
+		if errors.As(parseErr, &validationErr) {
+			return validationErr
+		}
+
+		slog.Error("Failed to parse form data", "err", parseErr)
+		return newInvalidFormDataError()
+	}
+
+	if formErr := call.req.ParseForm(); formErr != nil {
+		_ = form.RemoveAll()
+		slog.Error("Failed to parse form data", "err", formErr)
+		return newInvalidFormDataError()
+	}
+
+	if call.req.PostForm == nil {
+		call.req.PostForm = make(url.Values)
+	}
+	for key, values := range form.Value {
+		call.req.Form[key] = append(call.req.Form[key], values...)
+		call.req.PostForm[key] = append(call.req.PostForm[key], values...)
+	}
+	call.req.MultipartForm = form
+
+	return nil
+}
+
+// copyMultipartParts writes the parts of given reader to the writer, failing
+// once a part exceeds its limits.
+func copyMultipartParts(reader *MultipartReader, writer *multipart.Writer) error {
+	for part, partErr := range reader.Parts() {
+		if partErr != nil {
+			return partErr
+		}
+
+		partWriter, createErr := writer.CreatePart(part.Header)
+		if createErr != nil {
+			return createErr
+		}
+		if _, copyErr := io.Copy(partWriter, part); copyErr != nil {
+			return copyErr
+		}
+	}
+
+	return writer.Close()
+}
+
+// limitedBody limits the size of a request body like http.MaxBytesReader, but
+// without forcing the connection to close, letting the server discard the rest
+// of small bodies and reuse the connection.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (body *limitedBody) Read(data []byte) (int, error) {
+	if body.remaining < 0 {
+		return 0, &http.MaxBytesError{Limit: body.limit}
+	}
+
+	// Read a byte more than the limit to detect bodies exceeding it
+	if int64(len(data)) > body.remaining+1 {
+		data = data[:body.remaining+1]
+	}
+
+	read, readErr := body.ReadCloser.Read(data)
+	body.remaining -= int64(read)
+	if body.remaining < 0 {
+		return read + int(body.remaining), &http.MaxBytesError{Limit: body.limit}
+	}
+
+	return read, readErr
+}
+
+func newInvalidFormDataError() error {
+	return validation.NewError(validation.NewErrorResponse(
+		http.StatusBadRequest,
+		validation.NewParameterErrorDetail(
+			"formData",
+			"Invalid form data",
+		),
+	))
+}
+
+func newRequestTooLargeError(field string, reason string) error {
+	return validation.NewError(validation.NewErrorResponse(
+		http.StatusRequestEntityTooLarge,
+		validation.NewParameterErrorDetail(field, reason),
+	))
+}
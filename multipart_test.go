@@ -0,0 +1,259 @@
+package govalin_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+type multipartFile struct {
+	field    string
+	filename string
+	content  string
+}
+
+// postMultipart posts a multipart body with given fields and files and returns
+// the status and body of the response.
+func postMultipart(
+	t *testing.T,
+	http govalintesting.GovalinHTTP,
+	path string,
+	fields map[string]string,
+	files ...multipartFile,
+) (int, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		assert.NoError(t, writer.WriteField(key, value))
+	}
+	for _, file := range files {
+		fileWriter, _ := writer.CreateFormFile(file.field, file.filename)
+		_, _ = io.WriteString(fileWriter, file.content)
+	}
+	assert.NoError(t, writer.Close())
+
+	response, err := http.Raw().Do(
+		"POST",
+		http.Host+path,
+		map[string]string{"Content-Type": writer.FormDataContentType()},
+		body,
+	)
+	assert.NoError(t, err)
+	responseBody, _ := response.ToString()
+
+	return response.StatusCode, responseBody
+}
+
+func TestMultipartReader(t *testing.T) {
+	dir := t.TempDir()
+	tempFiles := []string{}
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Post("/upload", func(call *govalin.Call) {
+			reader, err := call.MultipartReader()
+			if err != nil {
+				call.Error(err)
+				return
+			}
+
+			results := []string{}
+			for part, partErr := range reader.Parts() {
+				if partErr != nil {
+					call.Error(partErr)
+					return
+				}
+
+				if !part.IsFile() {
+					value, valueErr := part.Value()
+					if valueErr != nil {
+						call.Error(valueErr)
+						return
+					}
+					results = append(results, part.FormName()+"="+value)
+					continue
+				}
+
+				saved, saveErr := part.Save(filepath.Join(dir, part.FileName()))
+				if saveErr != nil {
+					call.Error(saveErr)
+					return
+				}
+				results = append(results, fmt.Sprintf("%s:%d:%s", saved.Filename, saved.Size, saved.SHA256))
+			}
+
+			call.Text(strings.Join(results, ","))
+		}, func(route *govalin.Route) {
+			route.MaxFiles(2).MaxFileSize(10)
+		})
+		app.Post("/temp", func(call *govalin.Call) {
+			reader, _ := call.MultipartReader()
+			part, _ := reader.NextPart()
+			saved, err := part.SaveTemp()
+			if err != nil {
+				call.Error(err)
+				return
+			}
+			tempFiles = append(tempFiles, saved.Path)
+
+			_, statErr := os.Stat(saved.Path)
+			assert.NoError(t, statErr, "Should keep the file during the call")
+			call.Text(saved.FieldName)
+		})
+		app.Post("/text", func(call *govalin.Call) {
+			_, err := call.MultipartReader()
+			call.Error(err)
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		hash := sha256.Sum256([]byte("govalin"))
+		status, body := postMultipart(
+			t, http, "/upload",
+			map[string]string{"name": "gopher"},
+			multipartFile{field: "file", filename: "a.txt", content: "govalin"},
+		)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "name=gopher,a.txt:7:"+hex.EncodeToString(hash[:]), body)
+		saved, _ := os.ReadFile(filepath.Join(dir, "a.txt"))
+		assert.Equal(t, "govalin", string(saved))
+
+		status, body = postMultipart(
+			t, http, "/upload", nil,
+			multipartFile{field: "file", filename: "exact.txt", content: "0123456789"},
+		)
+		assert.Equal(t, 200, status, "Should accept files of exactly the max size")
+		assert.Contains(t, body, "exact.txt:10:")
+
+		status, body = postMultipart(
+			t, http, "/upload", nil,
+			multipartFile{field: "file", filename: "big.txt", content: "0123456789a"},
+		)
+		assert.Equal(t, 413, status)
+		assert.Contains(t, body, "The file 'big.txt' exceeds the max file size of 10 bytes")
+		_, statErr := os.Stat(filepath.Join(dir, "big.txt"))
+		assert.ErrorIs(t, statErr, os.ErrNotExist, "Should remove partially saved files")
+
+		status, body = postMultipart(
+			t, http, "/upload", nil,
+			multipartFile{field: "file", filename: "1.txt", content: "1"},
+			multipartFile{field: "file", filename: "2.txt", content: "2"},
+			multipartFile{field: "file", filename: "3.txt", content: "3"},
+		)
+		assert.Equal(t, 413, status)
+		assert.Contains(t, body, "Too many files. A max of 2 files are accepted")
+
+		status, body = postMultipart(
+			t, http, "/upload",
+			map[string]string{"name": strings.Repeat("a", 4097)},
+		)
+		assert.Equal(t, 413, status)
+		assert.Contains(t, body, "The field exceeds the max size of 4096 bytes")
+
+		status, body = postMultipart(
+			t, http, "/temp", nil,
+			multipartFile{field: "upload", filename: "temp.txt", content: "temporary"},
+		)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "upload", body)
+		assert.Len(t, tempFiles, 1)
+		_, statErr = os.Stat(tempFiles[0])
+		assert.ErrorIs(t, statErr, os.ErrNotExist, "Should remove temporary files after the call")
+
+		assert.Contains(t, http.Post("/text", "name=gopher"), "Must be 'multipart/form-data'")
+	})
+}
+
+func TestMultipartFormLimits(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Post("/files", func(call *govalin.Call) {
+			files, err := call.Files("file")
+			if err != nil {
+				call.Error(err)
+				return
+			}
+
+			call.Text(fmt.Sprint(len(files)))
+		}, func(route *govalin.Route) {
+			route.MaxFiles(2).MaxFileSize(10)
+		})
+		app.Post("/default", func(call *govalin.Call) {
+			file, err := call.File("file")
+			if err != nil {
+				call.Error(err)
+				return
+			}
+
+			call.Text(file.Filename)
+		})
+		app.Post("/fields", func(call *govalin.Call) {
+			file, err := call.File("file")
+			if err != nil {
+				call.Error(err)
+				return
+			}
+			name, _ := call.FormParam("name")
+
+			call.Text(file.Filename + ":" + name)
+		}, func(route *govalin.Route) {
+			route.MaxFileSize(10)
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		status, body := postMultipart(
+			t, http, "/files", nil,
+			multipartFile{field: "file", filename: "1.txt", content: "1"},
+			multipartFile{field: "file", filename: "2.txt", content: "2"},
+		)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "2", body)
+
+		status, body = postMultipart(
+			t, http, "/files", nil,
+			multipartFile{field: "file", filename: "big.txt", content: "0123456789a"},
+		)
+		assert.Equal(t, 413, status)
+		assert.Contains(t, body, "The file 'big.txt' exceeds the max file size of 10 bytes")
+
+		status, body = postMultipart(
+			t, http, "/files", nil,
+			multipartFile{field: "file", filename: "1.txt", content: "1"},
+			multipartFile{field: "file", filename: "2.txt", content: "2"},
+			multipartFile{field: "file", filename: "3.txt", content: "3"},
+		)
+		assert.Equal(t, 413, status)
+		assert.Contains(t, body, "Too many files. A max of 2 files are accepted")
+
+		status, body = postMultipart(
+			t, http, "/files", nil,
+			multipartFile{field: "file", filename: "huge.txt", content: strings.Repeat("a", 8192)},
+		)
+		assert.Equal(t, 413, status, "Should stop reading files as they exceed the max file size")
+		assert.Contains(t, body, "The file 'huge.txt' exceeds the max file size of 10 bytes")
+
+		status, body = postMultipart(
+			t, http, "/default", nil,
+			multipartFile{field: "file", filename: "default.txt", content: strings.Repeat("a", 8192)},
+		)
+		assert.Equal(t, 200, status, "Should not limit files by the max body read size")
+		assert.Equal(t, "default.txt", body)
+
+		status, body = postMultipart(
+			t, http, "/fields", map[string]string{"name": "gopher"},
+			multipartFile{field: "file", filename: "a.txt", content: "govalin"},
+		)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "a.txt:gopher", body, "Should parse both files and fields")
+	})
+}
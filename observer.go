@@ -0,0 +1,61 @@
+package govalin
+
+// CallPhase is a phase of handling a call, reported to call observers.
+type CallPhase string
+
+const (
+	// CallPhaseBefore is the phase running the before handlers of a call.
+	CallPhaseBefore CallPhase = "before"
+	// CallPhaseHandler is the phase running the endpoint handler of a call.
+	CallPhaseHandler CallPhase = "handler"
+	// CallPhaseAfter is the phase running the after handlers of a call.
+	CallPhaseAfter CallPhase = "after"
+	// CallPhaseSessionStore is a call to the session store, such as loading
+	// the session of a call. The operation is the name of the store method.
+	CallPhaseSessionStore CallPhase = "session_store"
+	// CallPhaseWebsocket is an open websocket connection of a call, lasting from
+	// the upgrade of the connection until it closes.
+	CallPhaseWebsocket CallPhase = "websocket"
+)
+
+// CallObserver observes the handling of calls
+//
+// Observers are used by plugins instrumenting calls, such as tracing and
+// metrics, and are added using ServerEvents.AddCallObserver. OnCallStart is
+// called when a call starts, before the session of the call is loaded, and can
+// use Call.WithContext and Call.OnComplete to follow the rest of the call.
+// OnCallPhase is called when a phase of the call starts, and returns a function
+// which is called with the error of the phase, if any, when the phase ends.
+type CallObserver interface {
+	OnCallStart(call *Call)
+	OnCallPhase(call *Call, phase CallPhase, operation string) func(err error)
+}
+
+// startPhase notifies the call observers of the start of a phase, returning a
+// function ending the phase.
+func (call *Call) startPhase(phase CallPhase, operation string) func(err error) {
+	observers := call.config.server.events.callObservers
+	if len(observers) == 0 {
+		return func(error) {}
+	}
+
+	endFuncs := make([]func(err error), len(observers))
+	for i, observer := range observers {
+		endFuncs[i] = observer.OnCallPhase(call, phase, operation)
+	}
+
+	return func(err error) {
+		for i := len(endFuncs) - 1; i >= 0; i-- {
+			if endFuncs[i] != nil {
+				endFuncs[i](err)
+			}
+		}
+	}
+}
+
+// RoutePattern returns the path pattern of the route handling the call, such
+// as '/users/{id}'. Returns an empty string until the endpoint handler has been
+// matched, and for calls not matching any route.
+func (call *Call) RoutePattern() string {
+	return call.routePattern
+}
@@ -0,0 +1,79 @@
+package govalin_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (observer *recordingObserver) record(event string) {
+	observer.mutex.Lock()
+	defer observer.mutex.Unlock()
+
+	observer.events = append(observer.events, event)
+}
+
+func (observer *recordingObserver) OnCallStart(call *govalin.Call) {
+	observer.record("start " + call.URL().Path)
+	call.OnComplete(func() {
+		observer.record("complete " + call.RoutePattern())
+	})
+}
+
+func (observer *recordingObserver) OnCallPhase(_ *govalin.Call, phase govalin.CallPhase, operation string) func(err error) {
+	observer.record(string(phase) + " " + operation)
+	return func(err error) {
+		observer.record("end " + string(phase))
+	}
+}
+
+func TestCallObserver(t *testing.T) {
+	observer := &recordingObserver{}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.EnableSessions()
+			config.Events(func(serverEvents *govalin.ServerEvents) {
+				serverEvents.AddCallObserver(observer)
+			})
+		}).Get("/pets/{id}", func(call *govalin.Call) {
+			observer.record("handle " + call.RoutePattern())
+			call.Text("pet")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, "pet", govalinHTTP.Get("/pets/1"))
+	})
+
+	// A session cookie left by other tests might be looked up before the new
+	// session is created, so only the end of the session phases is compared.
+	assert.Equal(t, "start /pets/1", observer.events[0])
+	assert.Contains(t, observer.events, "session_store CreateSession")
+
+	lastSessionEvent := 0
+	for i, event := range observer.events {
+		if event == "end session_store" {
+			lastSessionEvent = i
+		}
+	}
+	assert.Equal(t, []string{
+		"session_store GetSession",
+		"end session_store",
+		"before ",
+		"end before",
+		"handler ",
+		"handle /pets/{id}",
+		"end handler",
+		"after ",
+		"end after",
+		"complete /pets/{id}",
+	}, observer.events[lastSessionEvent-1:])
+}
@@ -0,0 +1,314 @@
+package govalin
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/http/contenttypes"
+	"github.com/pkkummermo/govalin/internal/validation"
+	"github.com/pkkummermo/govalin/openapi"
+)
+
+const (
+	defaultOpenAPITitle   = "govalin API"
+	defaultOpenAPIVersion = "1.0.0"
+)
+
+var pathParamRegexp = regexp.MustCompile(`\{([^/{}]+)\}`)
+
+// documentedMethods lists the methods in the order they appear in generated documents.
+var documentedMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+}
+
+type routeResponse struct {
+	status      int
+	description string
+	obj         any
+}
+
+// Summary sets a short summary of what the route does.
+func (route *Route) Summary(summary string) *Route {
+	route.summary = summary
+	return route
+}
+
+// Description sets a verbose explanation of the route.
+func (route *Route) Description(description string) *Route {
+	route.description = description
+	return route
+}
+
+// OperationID sets a unique identifier of the route.
+func (route *Route) OperationID(operationID string) *Route {
+	route.operationID = operationID
+	return route
+}
+
+// Tags adds tags for grouping of routes.
+func (route *Route) Tags(tags ...string) *Route {
+	route.tags = append(route.tags, tags...)
+	return route
+}
+
+// Deprecated marks the route as deprecated.
+func (route *Route) Deprecated() *Route {
+	route.deprecated = true
+	return route
+}
+
+// Hidden excludes the route from generated documents.
+func (route *Route) Hidden() *Route {
+	route.hidden = true
+	return route
+}
+
+// Body documents the JSON request body using the type of given object.
+func (route *Route) Body(obj any) *Route {
+	route.body = obj
+	route.hasBody = true
+	return route
+}
+
+// Response documents a response for given status using the type of given
+// object as JSON body. Use nil for responses without body.
+func (route *Route) Response(status int, obj any, description ...string) *Route {
+	response := routeResponse{
+		status:      status,
+		description: http.StatusText(status),
+		obj:         obj,
+	}
+	if len(description) > 0 {
+		response.description = description[0]
+	}
+
+	route.responses = append(route.responses, response)
+	return route
+}
+
+// PathParam documents a path parameter. Path parameters are documented
+// automatically, so this is only needed to add a description.
+func (route *Route) PathParam(name string, description string) *Route {
+	return route.Param(&openapi.Parameter{
+		Name:        name,
+		In:          openapi.InPath,
+		Description: description,
+		Required:    true,
+		Schema:      &openapi.Schema{Type: openapi.SchemaType{openapi.TypeString}},
+	})
+}
+
+// QueryParam documents an optional string query parameter.
+func (route *Route) QueryParam(name string, description string) *Route {
+	return route.Param(&openapi.Parameter{
+		Name:        name,
+		In:          openapi.InQuery,
+		Description: description,
+		Schema:      &openapi.Schema{Type: openapi.SchemaType{openapi.TypeString}},
+	})
+}
+
+// HeaderParam documents an optional string request header.
+func (route *Route) HeaderParam(name string, description string) *Route {
+	return route.Param(&openapi.Parameter{
+		Name:        name,
+		In:          openapi.InHeader,
+		Description: description,
+		Schema:      &openapi.Schema{Type: openapi.SchemaType{openapi.TypeString}},
+	})
+}
+
+// Param documents a parameter with full control of the parameter definition.
+func (route *Route) Param(param *openapi.Parameter) *Route {
+	route.params = append(route.params, param)
+	return route
+}
+
+// Security adds a security requirement to the route referring to a security
+// scheme by name.
+func (route *Route) Security(scheme string, scopes ...string) *Route {
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	route.security = append(route.security, openapi.SecurityRequirement{scheme: scopes})
+	return route
+}
+
+// OpenAPI generates an OpenAPI document based on the registered routes and
+// their documentation.
+//
+// Request and response types are reflected into JSON schemas, including the
+// constraints given by `govalin` struct tags. Routes containing wildcards are
+// not included, nor are routes marked as hidden.
+func (server *App) OpenAPI() *openapi.Document {
+	document := openapi.New(defaultOpenAPITitle, defaultOpenAPIVersion)
+	generator := openapi.NewSchemaGenerator(document.Components.Schemas)
+
+	for _, pathHandler := range server.pathHandlers {
+		if strings.Contains(pathHandler.PathFragment, "*") {
+			continue
+		}
+
+		path := "/" + strings.TrimLeft(pathHandler.PathFragment, "/")
+
+		for _, method := range documentedMethods {
+			route, documented := pathHandler.Routes[method]
+			if !documented || route.hidden {
+				continue
+			}
+
+			pathItem, exists := document.Paths[path]
+			if !exists {
+				pathItem = &openapi.PathItem{}
+				document.Paths[path] = pathItem
+			}
+
+			pathItem.SetOperation(method, route.operation(path, generator))
+		}
+	}
+
+	return document
+}
+
+func (route *Route) operation(path string, generator *openapi.SchemaGenerator) *openapi.Operation {
+	operation := &openapi.Operation{
+		OperationID: route.operationID,
+		Summary:     route.summary,
+		Description: route.description,
+		Tags:        route.tags,
+		Parameters:  []*openapi.Parameter{},
+		Responses:   map[string]*openapi.Response{},
+		Security:    route.security,
+		Deprecated:  route.deprecated,
+		Roles:       route.roles,
+	}
+
+	// Explicitly documented params take precedence over params of typed handlers
+	params := route.params
+	if route.requestType != nil {
+		for _, param := range typedParams(route.requestType, generator) {
+			if findParam(params, param.In, param.Name) == nil {
+				params = append(params, param)
+			}
+		}
+	}
+
+	// Path params are always documented, using the documented definition if available
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		param := findParam(params, openapi.InPath, match[1])
+		if param == nil {
+			param = &openapi.Parameter{
+				Name:     match[1],
+				In:       openapi.InPath,
+				Required: true,
+				Schema:   &openapi.Schema{Type: openapi.SchemaType{openapi.TypeString}},
+			}
+		}
+		operation.Parameters = append(operation.Parameters, param)
+	}
+
+	for _, param := range params {
+		if param.In != openapi.InPath {
+			operation.Parameters = append(operation.Parameters, param)
+		}
+	}
+
+	var bodySchema *openapi.Schema
+	switch {
+	case route.hasBody:
+		bodySchema = generator.Schema(route.body)
+	case route.requestType != nil && hasBodyFields(route.requestType):
+		bodySchema = generator.SchemaFor(route.requestType)
+	}
+
+	if bodySchema != nil {
+		operation.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content: map[string]*openapi.MediaType{
+				contenttypes.ApplicationJSON: {Schema: bodySchema},
+			},
+		}
+	}
+
+	for _, response := range route.responses {
+		operation.Responses[strconv.Itoa(response.status)] = responseFor(response, generator)
+	}
+
+	if route.responseType != nil {
+		isEmpty := route.responseType == reflect.TypeFor[Empty]()
+		status := successStatus(route, isEmpty)
+
+		if _, documented := operation.Responses[strconv.Itoa(status)]; !documented {
+			response := routeResponse{status: status, description: http.StatusText(status)}
+			if !isEmpty {
+				response.obj = reflect.New(route.responseType).Elem().Interface()
+			}
+			operation.Responses[strconv.Itoa(status)] = responseFor(response, generator)
+		}
+	}
+
+	// Bodies and typed requests are validated, so document the validation error response
+	validated := bodySchema != nil || (route.requestType != nil && route.requestType != reflect.TypeFor[Empty]())
+	badRequest := strconv.Itoa(http.StatusBadRequest)
+	if _, documented := operation.Responses[badRequest]; validated && !documented {
+		operation.Responses[badRequest] = responseFor(routeResponse{
+			status:      http.StatusBadRequest,
+			description: http.StatusText(http.StatusBadRequest),
+			obj:         validation.ErrorResponse{},
+		}, generator)
+	}
+
+	// Routes with roles are protected by the access manager, so document its error responses
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		if _, documented := operation.Responses[strconv.Itoa(status)]; len(route.roles) > 0 && !documented {
+			operation.Responses[strconv.Itoa(status)] = responseFor(routeResponse{
+				status:      status,
+				description: http.StatusText(status),
+				obj:         validation.ErrorResponse{},
+			}, generator)
+		}
+	}
+
+	// An operation must have at least a single response
+	if len(route.responses) == 0 && route.responseType == nil {
+		operation.Responses[strconv.Itoa(http.StatusOK)] = &openapi.Response{
+			Description: http.StatusText(http.StatusOK),
+		}
+	}
+
+	return operation
+}
+
+func findParam(params []*openapi.Parameter, in string, name string) *openapi.Parameter {
+	for _, param := range params {
+		if param.In == in && param.Name == name {
+			return param
+		}
+	}
+
+	return nil
+}
+
+func responseFor(response routeResponse, generator *openapi.SchemaGenerator) *openapi.Response {
+	openAPIResponse := &openapi.Response{
+		Description: response.description,
+	}
+
+	if response.obj != nil {
+		openAPIResponse.Content = map[string]*openapi.MediaType{
+			contenttypes.ApplicationJSON: {Schema: generator.Schema(response.obj)},
+		}
+	}
+
+	return openAPIResponse
+}
@@ -0,0 +1,303 @@
+// Package openapi contains the OpenAPI 3.1 document model used by govalin, and
+// a schema generator which reflects Go types into JSON schemas.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Document is the root object of an OpenAPI document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+}
+
+// Info provides metadata about the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server describes a server hosting the API.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag adds metadata to a tag used by operations.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem describes the operations available on a single path.
+type PathItem struct {
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Parameters  []*Parameter `json:"parameters,omitempty"`
+	Get         *Operation   `json:"get,omitempty"`
+	Put         *Operation   `json:"put,omitempty"`
+	Post        *Operation   `json:"post,omitempty"`
+	Delete      *Operation   `json:"delete,omitempty"`
+	Options     *Operation   `json:"options,omitempty"`
+	Head        *Operation   `json:"head,omitempty"`
+	Patch       *Operation   `json:"patch,omitempty"`
+}
+
+// Operation returns the operation for the given HTTP method, or nil if the
+// path item has no such operation.
+func (pathItem *PathItem) Operation(method string) *Operation {
+	switch method {
+	case http.MethodGet:
+		return pathItem.Get
+	case http.MethodPut:
+		return pathItem.Put
+	case http.MethodPost:
+		return pathItem.Post
+	case http.MethodDelete:
+		return pathItem.Delete
+	case http.MethodOptions:
+		return pathItem.Options
+	case http.MethodHead:
+		return pathItem.Head
+	case http.MethodPatch:
+		return pathItem.Patch
+	default:
+		return nil
+	}
+}
+
+// SetOperation sets the operation for the given HTTP method.
+func (pathItem *PathItem) SetOperation(method string, operation *Operation) {
+	switch method {
+	case http.MethodGet:
+		pathItem.Get = operation
+	case http.MethodPut:
+		pathItem.Put = operation
+	case http.MethodPost:
+		pathItem.Post = operation
+	case http.MethodDelete:
+		pathItem.Delete = operation
+	case http.MethodOptions:
+		pathItem.Options = operation
+	case http.MethodHead:
+		pathItem.Head = operation
+	case http.MethodPatch:
+		pathItem.Patch = operation
+	}
+}
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Roles       []string              `json:"x-roles,omitempty"` // Roles are the roles permitted to call the operation
+}
+
+// Parameter locations.
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InCookie = "cookie"
+)
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Deprecated  bool    `json:"deprecated,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes a single request body.
+type RequestBody struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType provides the schema for a given content type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response describes a single response from an API operation.
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header describes a single response header.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// Components holds reusable objects for the document.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	Parameters      map[string]*Parameter      `json:"parameters,omitempty"`
+	RequestBodies   map[string]*RequestBody    `json:"requestBodies,omitempty"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme defines a security scheme that can be used by the operations.
+type SecurityScheme struct {
+	Type             string `json:"type"`
+	Description      string `json:"description,omitempty"`
+	Name             string `json:"name,omitempty"`
+	In               string `json:"in,omitempty"`
+	Scheme           string `json:"scheme,omitempty"`
+	BearerFormat     string `json:"bearerFormat,omitempty"`
+	OpenIDConnectURL string `json:"openIdConnectUrl,omitempty"`
+}
+
+// SecurityRequirement lists the required security schemes and their scopes.
+type SecurityRequirement map[string][]string
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"` // OpenAPI 3.0 only
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+}
+
+// UnmarshalJSON accepts the boolean schemas of JSON schema in addition to schema
+// objects, where true accepts any value and false accepts none.
+func (schema *Schema) UnmarshalJSON(data []byte) error {
+	var boolean bool
+	if err := json.Unmarshal(data, &boolean); err == nil {
+		*schema = Schema{}
+		if !boolean {
+			schema.Not = &Schema{}
+		}
+		return nil
+	}
+
+	// Alias the type to avoid recursing into this method
+	type schemaAlias Schema
+	var alias schemaAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*schema = Schema(alias)
+
+	return nil
+}
+
+// SchemaType is the JSON schema type keyword. OpenAPI 3.1 allows both a single
+// type and a list of types, where the latter is used for nullable values.
+type SchemaType []string
+
+// MarshalJSON writes a single type as a string and multiple types as an array.
+func (schemaType SchemaType) MarshalJSON() ([]byte, error) {
+	if len(schemaType) == 1 {
+		return json.Marshal(schemaType[0])
+	}
+
+	return json.Marshal([]string(schemaType))
+}
+
+// UnmarshalJSON accepts both a single type string and an array of types.
+func (schemaType *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*schemaType = SchemaType{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*schemaType = multiple
+
+	return nil
+}
+
+// Is returns true if the schema type contains the given type.
+func (schemaType SchemaType) Is(typeName string) bool {
+	for _, t := range schemaType {
+		if t == typeName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JSON schema type names.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeArray   = "array"
+	TypeObject  = "object"
+	TypeNull    = "null"
+)
+
+// New returns an empty OpenAPI document with the given title and version.
+func New(title string, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   title,
+			Version: version,
+		},
+		Paths: map[string]*PathItem{},
+		Components: &Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+	}
+}
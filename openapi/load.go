@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads an OpenAPI 3 document from given JSON or YAML file.
+func Load(path string) (*Document, error) {
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document '%s'. %w", path, readErr)
+	}
+
+	return Parse(data)
+}
+
+// Parse parses an OpenAPI 3 document from given JSON or YAML data.
+func Parse(data []byte) (*Document, error) {
+	jsonData := data
+
+	// YAML is a superset of JSON, but decoding JSON directly keeps number precision
+	if !json.Valid(data) {
+		var yamlDocument any
+		if yamlErr := yaml.Unmarshal(data, &yamlDocument); yamlErr != nil {
+			return nil, fmt.Errorf("failed to parse OpenAPI document as JSON or YAML. %w", yamlErr)
+		}
+
+		var marshalErr error
+		jsonData, marshalErr = json.Marshal(normalizeYAML(yamlDocument))
+		if marshalErr != nil {
+			return nil, fmt.Errorf("failed to convert YAML OpenAPI document. %w", marshalErr)
+		}
+	}
+
+	var document Document
+	if unmarshalErr := json.Unmarshal(jsonData, &document); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document. %w", unmarshalErr)
+	}
+
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version '%s', must be 3.x", document.OpenAPI)
+	}
+
+	if document.Paths == nil {
+		document.Paths = map[string]*PathItem{}
+	}
+	if document.Components == nil {
+		document.Components = &Components{}
+	}
+
+	return &document, nil
+}
+
+// normalizeYAML converts maps with non-string keys, such as response status
+// codes, into maps which can be marshalled as JSON.
+func normalizeYAML(value any) any {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		for key, child := range typedValue {
+			typedValue[key] = normalizeYAML(child)
+		}
+		return typedValue
+	case map[any]any:
+		normalized := make(map[string]any, len(typedValue))
+		for key, child := range typedValue {
+			normalized[fmt.Sprint(key)] = normalizeYAML(child)
+		}
+		return normalized
+	case []any:
+		for i, child := range typedValue {
+			typedValue[i] = normalizeYAML(child)
+		}
+		return typedValue
+	default:
+		return value
+	}
+}
@@ -0,0 +1,222 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkkummermo/govalin/internal/validation"
+)
+
+const componentSchemaRefPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	invalidNameChars  = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// SchemaGenerator reflects Go types into JSON schemas.
+//
+// Named struct types are registered as reusable component schemas and
+// referenced using $ref, which also makes recursive types possible. Constraints
+// from the `govalin` struct tags are added to the generated schemas.
+type SchemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewSchemaGenerator creates a generator which registers named schemas into
+// the given component schema map.
+func NewSchemaGenerator(schemas map[string]*Schema) *SchemaGenerator {
+	if schemas == nil {
+		schemas = map[string]*Schema{}
+	}
+
+	return &SchemaGenerator{
+		schemas: schemas,
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// Schemas returns the registered component schemas.
+func (generator *SchemaGenerator) Schemas() map[string]*Schema {
+	return generator.schemas
+}
+
+// Schema returns the schema for the type of given object. A reflect.Type may
+// be given directly.
+func (generator *SchemaGenerator) Schema(obj any) *Schema {
+	if reflectType, isType := obj.(reflect.Type); isType {
+		return generator.SchemaFor(reflectType)
+	}
+
+	return generator.SchemaFor(reflect.TypeOf(obj))
+}
+
+// SchemaFor returns the schema for given type.
+func (generator *SchemaGenerator) SchemaFor(reflectType reflect.Type) *Schema {
+	if reflectType == nil {
+		return &Schema{}
+	}
+
+	for reflectType.Kind() == reflect.Pointer {
+		reflectType = reflectType.Elem()
+	}
+
+	switch {
+	case reflectType == timeType:
+		return &Schema{Type: SchemaType{TypeString}, Format: "date-time"}
+	case reflectType == rawMessageType:
+		return &Schema{}
+	case reflectType.Implements(textMarshalerType) || reflect.PointerTo(reflectType).Implements(textMarshalerType):
+		return &Schema{Type: SchemaType{TypeString}}
+	}
+
+	switch reflectType.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{TypeBoolean}}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: SchemaType{TypeInteger}, Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: SchemaType{TypeInteger}, Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: SchemaType{TypeInteger}, Minimum: floatPtr(0)}
+	case reflect.Float32:
+		return &Schema{Type: SchemaType{TypeNumber}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: SchemaType{TypeNumber}, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: SchemaType{TypeString}}
+	case reflect.Slice, reflect.Array:
+		if reflectType.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{TypeString}, Format: "byte"}
+		}
+		return &Schema{Type: SchemaType{TypeArray}, Items: generator.SchemaFor(reflectType.Elem())}
+	case reflect.Map:
+		return &Schema{Type: SchemaType{TypeObject}, AdditionalProperties: generator.SchemaFor(reflectType.Elem())}
+	case reflect.Struct:
+		if reflectType.Name() == "" {
+			return generator.structSchema(reflectType)
+		}
+		return &Schema{Ref: componentSchemaRefPrefix + generator.register(reflectType)}
+	default:
+		return &Schema{}
+	}
+}
+
+// register adds the named struct type as a component schema and returns its name.
+func (generator *SchemaGenerator) register(reflectType reflect.Type) string {
+	if name, exists := generator.names[reflectType]; exists {
+		return name
+	}
+
+	name := invalidNameChars.ReplaceAllString(reflectType.Name(), "_")
+	if _, taken := generator.schemas[name]; taken {
+		pkgPath := strings.Split(reflectType.PkgPath(), "/")
+		name = invalidNameChars.ReplaceAllString(pkgPath[len(pkgPath)-1]+"."+reflectType.Name(), "_")
+	}
+
+	// Register before generating the schema to support recursive types
+	generator.names[reflectType] = name
+	generator.schemas[name] = &Schema{}
+	*generator.schemas[name] = *generator.structSchema(reflectType)
+
+	return name
+}
+
+func (generator *SchemaGenerator) structSchema(reflectType reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       SchemaType{TypeObject},
+		Properties: map[string]*Schema{},
+	}
+
+	generator.addStructFields(schema, reflectType)
+
+	return schema
+}
+
+func (generator *SchemaGenerator) addStructFields(schema *Schema, reflectType reflect.Type) {
+	for i := 0; i < reflectType.NumField(); i++ {
+		field := reflectType.Field(i)
+
+		jsonName := validation.JSONFieldName(field)
+		if jsonName == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		// Embedded structs without a JSON name are flattened by encoding/json
+		if field.Anonymous && field.Tag.Get("json") == "" && fieldType.Kind() == reflect.Struct {
+			generator.addStructFields(schema, fieldType)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		propertySchema := generator.SchemaFor(field.Type)
+		for _, rule := range validation.ParseTag(field.Tag.Get(validation.TagName)) {
+			if rule.Name == validation.TagRequired {
+				schema.Required = append(schema.Required, jsonName)
+				continue
+			}
+			applyTagRule(propertySchema, rule)
+		}
+
+		schema.Properties[jsonName] = propertySchema
+	}
+}
+
+// applyTagRule adds the constraint of given validation rule to the schema.
+func applyTagRule(schema *Schema, rule validation.TagRule) {
+	isArray := schema.Type.Is(TypeArray)
+
+	switch rule.Name {
+	case validation.TagMinLength, validation.TagMaxLength:
+		limit, err := rule.Int()
+		if err != nil {
+			return
+		}
+		switch {
+		case rule.Name == validation.TagMinLength && isArray:
+			schema.MinItems = &limit
+		case rule.Name == validation.TagMaxLength && isArray:
+			schema.MaxItems = &limit
+		case rule.Name == validation.TagMinLength:
+			schema.MinLength = &limit
+		default:
+			schema.MaxLength = &limit
+		}
+	case validation.TagMin, validation.TagMax:
+		limit, err := strconv.ParseFloat(rule.Value, 64)
+		if err != nil {
+			return
+		}
+		if rule.Name == validation.TagMin {
+			schema.Minimum = &limit
+		} else {
+			schema.Maximum = &limit
+		}
+	case validation.TagEmail:
+		schema.Format = "email"
+	case validation.TagOneOf:
+		for _, value := range rule.Values() {
+			schema.Enum = append(schema.Enum, value)
+		}
+	}
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
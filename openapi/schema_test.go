@@ -0,0 +1,78 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type embedded struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type tree struct {
+	embedded
+	Name     string            `json:"name"     govalin:"required,oneOf=oak pine"`
+	Children []*tree           `json:"children" govalin:"maxLength=10"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Data     []byte            `json:"data"`
+	Ignored  string            `json:"-"`
+	private  string
+}
+
+func TestSchemaPrimitives(t *testing.T) {
+	generator := openapi.NewSchemaGenerator(nil)
+
+	assert.Equal(t, openapi.SchemaType{openapi.TypeString}, generator.Schema("").Type)
+	assert.Equal(t, openapi.SchemaType{openapi.TypeBoolean}, generator.Schema(true).Type)
+	assert.Equal(t, "int64", generator.Schema(1).Format)
+	assert.Equal(t, "double", generator.Schema(1.0).Format)
+	assert.Equal(t, openapi.SchemaType{openapi.TypeArray}, generator.Schema([]string{}).Type)
+	assert.Equal(t, openapi.SchemaType{openapi.TypeString}, generator.Schema([]string{}).Items.Type)
+	assert.Equal(t, "date-time", generator.Schema(time.Time{}).Format)
+	assert.Equal(t, "byte", generator.Schema([]byte{}).Format)
+	assert.Equal(t, openapi.SchemaType{openapi.TypeString}, generator.Schema(reflect.TypeOf("")).Type)
+	assert.Empty(t, generator.Schemas(), "Should not register primitive types")
+}
+
+func TestSchemaStructs(t *testing.T) {
+	generator := openapi.NewSchemaGenerator(nil)
+
+	schema := generator.Schema(&tree{})
+	assert.Equal(t, "#/components/schemas/tree", schema.Ref)
+
+	treeSchema := generator.Schemas()["tree"]
+	assert.Equal(t, []string{"name"}, treeSchema.Required)
+	assert.Equal(t, []any{"oak", "pine"}, treeSchema.Properties["name"].Enum)
+	assert.Equal(t, "#/components/schemas/tree", treeSchema.Properties["children"].Items.Ref, "Should support recursion")
+	assert.Equal(t, 10, *treeSchema.Properties["children"].MaxItems)
+	assert.Equal(t, "date-time", treeSchema.Properties["createdAt"].Format, "Should flatten embedded structs")
+	assert.Equal(t, openapi.SchemaType{openapi.TypeObject}, treeSchema.Properties["labels"].Type)
+	assert.NotContains(t, treeSchema.Properties, "Ignored")
+	assert.NotContains(t, treeSchema.Properties, "-")
+	assert.NotContains(t, treeSchema.Properties, "private")
+
+	anonymous := generator.Schema(struct {
+		Value int `json:"value"`
+	}{})
+	assert.Empty(t, anonymous.Ref, "Should inline anonymous structs")
+	assert.Contains(t, anonymous.Properties, "value")
+}
+
+func TestSchemaTypeJSON(t *testing.T) {
+	single, _ := json.Marshal(openapi.SchemaType{openapi.TypeString})
+	multiple, _ := json.Marshal(openapi.SchemaType{openapi.TypeString, openapi.TypeNull})
+
+	assert.Equal(t, `"string"`, string(single))
+	assert.Equal(t, `["string","null"]`, string(multiple))
+
+	var schema openapi.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":["integer","null"]}`), &schema))
+	assert.True(t, schema.Type.Is(openapi.TypeNull))
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"integer"}`), &schema))
+	assert.Equal(t, openapi.SchemaType{openapi.TypeInteger}, schema.Type)
+}
@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSchemaDepth guards against self referencing schemas.
+const maxSchemaDepth = 64
+
+// SchemaError describes a value which doesn't match its schema.
+type SchemaError struct {
+	Field  string
+	Reason string
+}
+
+func (schemaError SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", schemaError.Field, schemaError.Reason)
+}
+
+// ResolveSchema returns the schema for given local reference, such as
+// "#/components/schemas/User". Returns nil if the reference can't be resolved.
+func (document *Document) ResolveSchema(ref string) *Schema {
+	if !strings.HasPrefix(ref, componentSchemaRefPrefix) || document.Components == nil {
+		return nil
+	}
+
+	return document.Components.Schemas[strings.TrimPrefix(ref, componentSchemaRefPrefix)]
+}
+
+// ValidateValue validates a decoded JSON value against given schema, resolving
+// references against the components of the document. Field is used as the
+// prefix of the field names of the returned errors.
+func (document *Document) ValidateValue(schema *Schema, value any, field string) []SchemaError {
+	return document.validateValue(schema, value, field, 0)
+}
+
+func (document *Document) validateValue(schema *Schema, value any, field string, depth int) []SchemaError {
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		resolved := document.ResolveSchema(schema.Ref)
+		if resolved == nil {
+			return []SchemaError{{Field: field, Reason: fmt.Sprintf("Unresolvable schema reference '%s'", schema.Ref)}}
+		}
+		return document.validateValue(resolved, value, field, depth+1)
+	}
+
+	errs := []SchemaError{}
+
+	if schema.Not != nil && len(document.validateValue(schema.Not, value, field, depth+1)) == 0 {
+		errs = append(errs, SchemaError{Field: field, Reason: "Must not match the disallowed schema"})
+	}
+
+	for _, allOf := range schema.AllOf {
+		errs = append(errs, document.validateValue(allOf, value, field, depth+1)...)
+	}
+
+	if len(schema.AnyOf) > 0 && document.countMatches(schema.AnyOf, value, field, depth) == 0 {
+		errs = append(errs, SchemaError{Field: field, Reason: "Must match at least one of the allowed schemas"})
+	}
+
+	if len(schema.OneOf) > 0 && document.countMatches(schema.OneOf, value, field, depth) != 1 {
+		errs = append(errs, SchemaError{Field: field, Reason: "Must match exactly one of the allowed schemas"})
+	}
+
+	if value == nil {
+		if len(schema.Type) > 0 && !schema.Type.Is(TypeNull) && !schema.Nullable {
+			errs = append(errs, SchemaError{Field: field, Reason: "Must not be null"})
+		}
+		return errs
+	}
+
+	if len(schema.Type) > 0 && !typeMatches(schema.Type, value) {
+		return append(errs, SchemaError{
+			Field:  field,
+			Reason: fmt.Sprintf("Must be of type '%s'", strings.Join(schema.Type, "' or '")),
+		})
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		errs = append(errs, SchemaError{Field: field, Reason: fmt.Sprintf("Must be one of %v", schema.Enum)})
+	}
+
+	switch typedValue := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(typedValue)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			errs = append(errs, SchemaError{
+				Field:  field,
+				Reason: fmt.Sprintf("Must be at least %d characters long", *schema.MinLength),
+			})
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			errs = append(errs, SchemaError{
+				Field:  field,
+				Reason: fmt.Sprintf("Must be at most %d characters long", *schema.MaxLength),
+			})
+		}
+		if schema.Pattern != "" {
+			if pattern, compileErr := regexp.Compile(schema.Pattern); compileErr == nil && !pattern.MatchString(typedValue) {
+				errs = append(errs, SchemaError{Field: field, Reason: fmt.Sprintf("Must match pattern '%s'", schema.Pattern)})
+			}
+		}
+		if !formatMatches(schema.Format, typedValue) {
+			errs = append(errs, SchemaError{Field: field, Reason: fmt.Sprintf("Must be a valid '%s'", schema.Format)})
+		}
+	case float64:
+		if schema.Minimum != nil && typedValue < *schema.Minimum {
+			errs = append(errs, SchemaError{Field: field, Reason: fmt.Sprintf("Must be at least %v", *schema.Minimum)})
+		}
+		if schema.Maximum != nil && typedValue > *schema.Maximum {
+			errs = append(errs, SchemaError{Field: field, Reason: fmt.Sprintf("Must be at most %v", *schema.Maximum)})
+		}
+	case []any:
+		if schema.MinItems != nil && len(typedValue) < *schema.MinItems {
+			errs = append(errs, SchemaError{Field: field, Reason: fmt.Sprintf("Must have at least %d items", *schema.MinItems)})
+		}
+		if schema.MaxItems != nil && len(typedValue) > *schema.MaxItems {
+			errs = append(errs, SchemaError{Field: field, Reason: fmt.Sprintf("Must have at most %d items", *schema.MaxItems)})
+		}
+		for i, item := range typedValue {
+			errs = append(errs, document.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), depth+1)...)
+		}
+	case map[string]any:
+		for _, required := range schema.Required {
+			if _, exists := typedValue[required]; !exists {
+				errs = append(errs, SchemaError{Field: joinField(field, required), Reason: "This field is required"})
+			}
+		}
+		// Iterate sorted keys to give a stable order of errors
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			property := typedValue[key]
+			if propertySchema, exists := schema.Properties[key]; exists {
+				errs = append(errs, document.validateValue(propertySchema, property, joinField(field, key), depth+1)...)
+				continue
+			}
+			if schema.AdditionalProperties != nil {
+				if schema.AdditionalProperties.Not != nil && isEmptySchema(schema.AdditionalProperties.Not) {
+					errs = append(errs, SchemaError{Field: joinField(field, key), Reason: "Unknown field"})
+					continue
+				}
+				errs = append(
+					errs,
+					document.validateValue(schema.AdditionalProperties, property, joinField(field, key), depth+1)...,
+				)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (document *Document) countMatches(schemas []*Schema, value any, field string, depth int) int {
+	matches := 0
+	for _, schema := range schemas {
+		if len(document.validateValue(schema, value, field, depth+1)) == 0 {
+			matches++
+		}
+	}
+
+	return matches
+}
+
+// CoerceParam converts raw parameter values into the JSON type described by the
+// schema, so they can be validated using ValidateValue. Values which can't be
+// converted are returned as strings, which fails validation on the type.
+func (document *Document) CoerceParam(schema *Schema, values []string) any {
+	if schema != nil && schema.Ref != "" {
+		schema = document.ResolveSchema(schema.Ref)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	if schema == nil {
+		return values[0]
+	}
+
+	if schema.Type.Is(TypeArray) {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+
+		items := make([]any, 0, len(values))
+		for _, value := range values {
+			items = append(items, document.CoerceParam(schema.Items, []string{value}))
+		}
+		return items
+	}
+
+	switch {
+	case schema.Type.Is(TypeInteger) || schema.Type.Is(TypeNumber):
+		if number, parseErr := strconv.ParseFloat(values[0], 64); parseErr == nil {
+			return number
+		}
+	case schema.Type.Is(TypeBoolean):
+		if boolean, parseErr := strconv.ParseBool(values[0]); parseErr == nil {
+			return boolean
+		}
+	}
+
+	return values[0]
+}
+
+func typeMatches(schemaType SchemaType, value any) bool {
+	for _, typeName := range schemaType {
+		switch typeName {
+		case TypeString:
+			if _, isString := value.(string); isString {
+				return true
+			}
+		case TypeNumber:
+			if _, isNumber := value.(float64); isNumber {
+				return true
+			}
+		case TypeInteger:
+			if number, isNumber := value.(float64); isNumber && number == math.Trunc(number) {
+				return true
+			}
+		case TypeBoolean:
+			if _, isBool := value.(bool); isBool {
+				return true
+			}
+		case TypeArray:
+			if _, isArray := value.([]any); isArray {
+				return true
+			}
+		case TypeObject:
+			if _, isObject := value.(map[string]any); isObject {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func formatMatches(format string, value string) bool {
+	var err error
+
+	switch format {
+	case "email":
+		_, err = mail.ParseAddress(value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse(time.DateOnly, value)
+	case "uuid":
+		if !uuidRegexp.MatchString(value) {
+			return false
+		}
+	}
+
+	return err == nil
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func enumContains(enum []any, value any) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isEmptySchema(schema *Schema) bool {
+	return reflect.DeepEqual(*schema, Schema{})
+}
+
+func joinField(field string, name string) string {
+	if field == "" {
+		return name
+	}
+
+	return field + "." + name
+}
@@ -0,0 +1,111 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/pkkummermo/govalin/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+const petstoreYAML = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      responses:
+        200:
+          description: The pet
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          maxLength: 5
+        age:
+          type: integer
+          minimum: 0
+        nickname:
+          type: string
+          nullable: true
+        tags:
+          type: array
+          maxItems: 2
+          items:
+            type: string
+            format: email
+      additionalProperties: false
+`
+
+func TestParse(t *testing.T) {
+	document, err := openapi.Parse([]byte(petstoreYAML))
+	assert.NoError(t, err)
+	assert.Equal(t, "Petstore", document.Info.Title)
+	assert.NotNil(t, document.Paths["/pets/{id}"].Get.Responses["200"], "Should convert integer status keys")
+
+	document, err = openapi.Parse([]byte(`{"openapi":"3.1.0","info":{"title":"JSON","version":"1"}}`))
+	assert.NoError(t, err)
+	assert.NotNil(t, document.Paths)
+
+	_, err = openapi.Parse([]byte(`{"swagger":"2.0"}`))
+	assert.Error(t, err)
+
+	_, err = openapi.Load("does-not-exist.yaml")
+	assert.Error(t, err)
+}
+
+func TestValidateValue(t *testing.T) {
+	document, _ := openapi.Parse([]byte(petstoreYAML))
+	pet := &openapi.Schema{Ref: "#/components/schemas/Pet"}
+
+	assert.Empty(t, document.ValidateValue(pet, map[string]any{
+		"name":     "Fido",
+		"age":      float64(3),
+		"nickname": nil,
+		"tags":     []any{"fido@govalin.io"},
+	}, ""))
+
+	assert.Equal(t, []openapi.SchemaError{
+		{Field: "name", Reason: "This field is required"},
+		{Field: "age", Reason: "Must be of type 'integer'"},
+		{Field: "color", Reason: "Unknown field"},
+		{Field: "tags", Reason: "Must have at most 2 items"},
+		{Field: "tags[0]", Reason: "Must be a valid 'email'"},
+	}, document.ValidateValue(pet, map[string]any{
+		"age":   1.5,
+		"color": "brown",
+		"tags":  []any{"fido", "a@b.c", "d@e.f"},
+	}, ""))
+
+	assert.Equal(t, []openapi.SchemaError{
+		{Field: "body", Reason: "Must be of type 'object'"},
+	}, document.ValidateValue(pet, "Fido", "body"))
+
+	oneOf := &openapi.Schema{OneOf: []*openapi.Schema{
+		{Type: openapi.SchemaType{openapi.TypeString}},
+		{Type: openapi.SchemaType{openapi.TypeInteger}},
+	}}
+	assert.Empty(t, document.ValidateValue(oneOf, "text", "value"))
+	assert.NotEmpty(t, document.ValidateValue(oneOf, true, "value"))
+}
+
+func TestCoerceParam(t *testing.T) {
+	document, _ := openapi.Parse([]byte(petstoreYAML))
+
+	integer := &openapi.Schema{Type: openapi.SchemaType{openapi.TypeInteger}}
+	assert.InDelta(t, 42.0, document.CoerceParam(integer, []string{"42"}), 0)
+	assert.Equal(t, "abc", document.CoerceParam(integer, []string{"abc"}))
+	assert.Nil(t, document.CoerceParam(integer, []string{}))
+
+	array := &openapi.Schema{Type: openapi.SchemaType{openapi.TypeArray}, Items: integer}
+	assert.Equal(t, []any{1.0, 2.0}, document.CoerceParam(array, []string{"1,2"}))
+	assert.Equal(t, []any{1.0, 2.0}, document.CoerceParam(array, []string{"1", "2"}))
+
+	boolean := &openapi.Schema{Type: openapi.SchemaType{openapi.TypeBoolean}}
+	assert.Equal(t, true, document.CoerceParam(boolean, []string{"true"}))
+}
@@ -0,0 +1,89 @@
+package govalin_test
+
+import (
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPIUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"  govalin:"required,minLength=2,maxLength=20"`
+	Email string `json:"email" govalin:"email"`
+	Age   int    `json:"age"   govalin:"min=0,max=150"`
+}
+
+func TestOpenAPIDocumentsRoutes(t *testing.T) {
+	app := govalin.New(func(config *govalin.Config) {
+		config.EnableStartupLog(false)
+	})
+
+	app.Route("/users", func() {
+		app.Get("/{id}", func(_ *govalin.Call) {}, func(route *govalin.Route) {
+			route.Summary("Get user").
+				Tags("users").
+				PathParam("id", "The user ID").
+				Response(200, openAPIUser{})
+		})
+		app.Post("", func(_ *govalin.Call) {}, func(route *govalin.Route) {
+			route.Summary("Create user").
+				Body(openAPIUser{}).
+				Response(201, openAPIUser{}).
+				Security("bearer")
+		})
+	})
+
+	document := app.OpenAPI()
+
+	assert.Equal(t, openapi.Version, document.OpenAPI)
+	assert.Contains(t, document.Paths, "/users/{id}")
+	assert.Contains(t, document.Paths, "/users")
+
+	getUser := document.Paths["/users/{id}"].Get
+	assert.Equal(t, "Get user", getUser.Summary)
+	assert.Equal(t, []string{"users"}, getUser.Tags)
+	assert.Len(t, getUser.Parameters, 1)
+	assert.Equal(t, "The user ID", getUser.Parameters[0].Description)
+	assert.Equal(
+		t,
+		"#/components/schemas/openAPIUser",
+		getUser.Responses["200"].Content["application/json"].Schema.Ref,
+	)
+
+	createUser := document.Paths["/users"].Post
+	assert.NotNil(t, createUser.RequestBody)
+	assert.Contains(t, createUser.Responses, "201")
+	assert.Contains(t, createUser.Responses, "400", "Should document validation errors for bodies")
+	assert.Equal(t, []openapi.SecurityRequirement{{"bearer": []string{}}}, createUser.Security)
+
+	userSchema := document.Components.Schemas["openAPIUser"]
+	assert.Equal(t, []string{"name"}, userSchema.Required)
+	assert.Equal(t, 2, *userSchema.Properties["name"].MinLength)
+	assert.Equal(t, 20, *userSchema.Properties["name"].MaxLength)
+	assert.Equal(t, "email", userSchema.Properties["email"].Format)
+	assert.Equal(t, 150.0, *userSchema.Properties["age"].Maximum)
+	assert.Contains(t, document.Components.Schemas, "ErrorResponse")
+}
+
+func TestOpenAPIDefaultsAndExclusions(t *testing.T) {
+	app := govalin.New(func(config *govalin.Config) {
+		config.EnableStartupLog(false)
+	})
+
+	app.Get("/undocumented/{org}/{repo}", func(_ *govalin.Call) {})
+	app.Get("/hidden", func(_ *govalin.Call) {}, func(route *govalin.Route) { route.Hidden() })
+	app.Get("/wildcard/*", func(_ *govalin.Call) {})
+	app.Before("/before", func(_ *govalin.Call) bool { return true })
+
+	document := app.OpenAPI()
+
+	assert.Len(t, document.Paths, 1, "Should only document routes without wildcards which aren't hidden")
+
+	undocumented := document.Paths["/undocumented/{org}/{repo}"].Get
+	assert.Len(t, undocumented.Parameters, 2, "Should document path params automatically")
+	assert.Equal(t, "org", undocumented.Parameters[0].Name)
+	assert.True(t, undocumented.Parameters[0].Required)
+	assert.Contains(t, undocumented.Responses, "200", "Should add default response")
+}
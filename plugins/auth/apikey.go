@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+
+	"github.com/pkkummermo/govalin"
+)
+
+const (
+	apiKeyScheme        = "ApiKey"
+	defaultAPIKeyHeader = "X-Api-Key"
+)
+
+// APIKeyVerifier verifies the API key of a call, returning the authenticated
+// principal or false if the key is invalid.
+type APIKeyVerifier func(call *govalin.Call, key string) (Principal, bool)
+
+// APIKeys verifies keys against the given principals by key.
+func APIKeys(principals map[string]Principal) APIKeyVerifier {
+	hashes := make(map[[sha256.Size]byte]Principal, len(principals))
+	for key, principal := range principals {
+		hashes[sha256.Sum256([]byte(key))] = principal
+	}
+
+	return func(_ *govalin.Call, key string) (Principal, bool) {
+		// Compare hashes to not leak keys through timing
+		keyHash := sha256.Sum256([]byte(key))
+		for hash, principal := range hashes {
+			if subtle.ConstantTimeCompare(hash[:], keyHash[:]) == 1 {
+				return principal, true
+			}
+		}
+
+		return Principal{}, false
+	}
+}
+
+// APIKeyConfig contains the configuration of the API key authentication plugin
+//
+// The key is read from the header, or from the query parameter if configured
+// and the header is missing. Calls without a valid key are responded with 401
+// Unauthorized and an ApiKey challenge in the WWW-Authenticate header. The
+// principal of authenticated calls is published, see PrincipalFrom.
+type APIKeyConfig struct {
+	verifier   APIKeyVerifier
+	header     string
+	queryParam string
+	realm      string
+	optional   bool
+	paths      []string
+}
+
+// NewAPIKey creates an API key authentication plugin verifying keys using the
+// verifier, such as APIKeys.
+func NewAPIKey(verifier APIKeyVerifier) *APIKeyConfig {
+	return &APIKeyConfig{
+		verifier: verifier,
+		header:   defaultAPIKeyHeader,
+		realm:    defaultRealm,
+		paths:    []string{},
+	}
+}
+
+func (config *APIKeyConfig) Name() string {
+	return "API key authentication plugin"
+}
+
+func (config *APIKeyConfig) OnInit(_ *govalin.Config) {}
+
+func (config *APIKeyConfig) Apply(app *govalin.App) {
+	applyToPaths(app, config.paths, config.authenticate)
+}
+
+// Path requires authentication for paths matching the path, such as '/api/*'.
+// Defaults to all paths.
+func (config *APIKeyConfig) Path(path string) *APIKeyConfig {
+	config.paths = append(config.paths, path)
+	return config
+}
+
+// Header sets the header containing the key. Defaults to 'X-Api-Key'.
+func (config *APIKeyConfig) Header(header string) *APIKeyConfig {
+	config.header = header
+	return config
+}
+
+// QueryParam sets the query parameter containing the key when the header is
+// missing. Keys in URLs end up in logs and browser histories, so prefer headers
+// where possible. Disabled by default.
+func (config *APIKeyConfig) QueryParam(queryParam string) *APIKeyConfig {
+	config.queryParam = queryParam
+	return config
+}
+
+// Realm sets the realm of the challenge. Defaults to 'govalin'.
+func (config *APIKeyConfig) Realm(realm string) *APIKeyConfig {
+	config.realm = realm
+	return config
+}
+
+// Optional lets calls without credentials continue unauthenticated, such as for
+// leaving it to the access manager of the App to require authentication by
+// route, see govalin.RBAC. Calls with invalid credentials are still rejected.
+func (config *APIKeyConfig) Optional() *APIKeyConfig {
+	config.optional = true
+	return config
+}
+
+func (config *APIKeyConfig) authenticate(call *govalin.Call) bool {
+	params := map[string]string{"realm": config.realm, "header": config.header}
+
+	key := call.Header(config.header)
+	if key == "" && config.queryParam != "" {
+		key = call.QueryParam(config.queryParam)
+	}
+	if key == "" {
+		if config.optional {
+			return true
+		}
+		return unauthorized(call, apiKeyScheme, params, "Missing API key")
+	}
+
+	principal, valid := config.verifier(call, key)
+	if !valid {
+		return unauthorized(call, apiKeyScheme, params, "Invalid API key")
+	}
+
+	return authenticated(call, apiKeyScheme, principal)
+}
@@ -0,0 +1,454 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/plugins/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasic(t *testing.T) {
+	plugin := auth.NewBasic(auth.BasicUsers(map[string]string{"admin": "secret"})).
+		Path("/api/*").
+		Realm("Admin")
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(plugin)
+		})
+
+		app.Get("/public", func(call *govalin.Call) {
+			call.Text("public")
+		})
+		return app.Get("/api/whoami", func(call *govalin.Call) {
+			principal, _ := auth.PrincipalFrom(call)
+			call.Text(principal.Scheme + " " + principal.Subject + " " + strings.Join(principal.Roles, ","))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		url := govalinHTTP.Host + "/api/whoami"
+		credentials := func(username string, password string) map[string]string {
+			return map[string]string{
+				"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)),
+			}
+		}
+
+		response, _ := govalinHTTP.Raw().Do(http.MethodGet, url, nil, nil)
+		body, _ := response.ToString()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, `Basic realm="Admin", charset="UTF-8"`, response.Header.Get("WWW-Authenticate"))
+		assert.Contains(t, body, "Missing basic credentials")
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url, credentials("admin", "wrong"), nil)
+		body, _ = response.ToString()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Contains(t, body, "Invalid username or password")
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url, credentials("unknown", "secret"), nil)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url, credentials("admin", "secret"), nil)
+		body, _ = response.ToString()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "Basic admin ", body)
+
+		assert.Equal(t, "public", govalinHTTP.Get("/public"),
+			"Should not require authentication outside of the paths",
+		)
+	})
+}
+
+func TestAPIKey(t *testing.T) {
+	plugin := auth.NewAPIKey(auth.APIKeys(map[string]auth.Principal{
+		"key-1": {Subject: "service", Roles: []string{"reader"}},
+	})).QueryParam("api_key")
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(plugin)
+		})
+
+		return app.Get("/api/whoami", func(call *govalin.Call) {
+			principal, _ := auth.PrincipalFrom(call)
+			call.Text(principal.Scheme + " " + principal.Subject + " " + strings.Join(principal.Roles, ","))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		url := govalinHTTP.Host + "/api/whoami"
+
+		response, _ := govalinHTTP.Raw().Do(http.MethodGet, url, nil, nil)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, `ApiKey realm="govalin", header="X-Api-Key"`, response.Header.Get("WWW-Authenticate"))
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url, map[string]string{"X-Api-Key": "key-2"}, nil)
+		body, _ := response.ToString()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Contains(t, body, "Invalid API key")
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url, map[string]string{"X-Api-Key": "key-1"}, nil)
+		body, _ = response.ToString()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "ApiKey service reader", body)
+
+		assert.Equal(t, "ApiKey service reader", govalinHTTP.Get("/api/whoami?api_key=key-1"),
+			"Should read the key from the query parameter",
+		)
+	})
+}
+
+func encodeSegment(value any) string {
+	encoded, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func signToken(header map[string]any, claims map[string]any, sign func(signed []byte) []byte) string {
+	signed := encodeSegment(header) + "." + encodeSegment(claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func hs256(secret []byte) func(signed []byte) []byte {
+	return func(signed []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil)
+	}
+}
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("test-secret")
+	plugin := auth.NewJWT().
+		HMACSecret(secret).
+		Audience("govalin-api").
+		Issuer("https://issuer.test").
+		Path("/api/*")
+
+	now := time.Now().Unix()
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"sub":   "user-1",
+			"aud":   []string{"other", "govalin-api"},
+			"iss":   "https://issuer.test",
+			"exp":   now + 60,
+			"nbf":   now - 60,
+			"roles": []string{"admin", "editor"},
+		}
+	}
+	header := map[string]any{"alg": "HS256", "typ": "JWT"}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(plugin)
+		})
+
+		return app.Get("/api/whoami", func(call *govalin.Call) {
+			principal, _ := auth.PrincipalFrom(call)
+			call.Text(principal.Scheme + " " + principal.Subject + " " + strings.Join(principal.Roles, ","))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		url := govalinHTTP.Host + "/api/whoami"
+
+		response, _ := govalinHTTP.Raw().Do(http.MethodGet, url, nil, nil)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, `Bearer realm="govalin"`, response.Header.Get("WWW-Authenticate"))
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url, bearer(signToken(header, validClaims(), hs256(secret))), nil)
+		body, _ := response.ToString()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "Bearer user-1 admin,editor", body)
+
+		invalidTokens := map[string]struct {
+			token       string
+			description string
+		}{
+			"malformed": {"not-a-token", "the token is malformed"},
+			"wrong secret": {
+				signToken(header, validClaims(), hs256([]byte("other-secret"))),
+				"the token signature is invalid",
+			},
+			"none algorithm": {
+				signToken(map[string]any{"alg": "none"}, validClaims(), func(_ []byte) []byte { return nil }),
+				"the token algorithm is not supported",
+			},
+			"unknown key": {
+				signToken(map[string]any{"alg": "RS256"}, validClaims(), hs256(secret)),
+				"the token signing key is unknown",
+			},
+		}
+
+		claimChanges := map[string]struct {
+			claim       string
+			value       any
+			description string
+		}{
+			"expired":        {"exp", now - 1, "the token is expired"},
+			"not yet valid":  {"nbf", now + 60, "the token is not valid yet"},
+			"wrong audience": {"aud", "other", "the token audience is not accepted"},
+			"wrong issuer":   {"iss", "https://other.test", "the token issuer is not accepted"},
+			"no expiry":      {"exp", nil, "the token has no expiration time"},
+		}
+		for name, change := range claimChanges {
+			claims := validClaims()
+			claims[change.claim] = change.value
+			if change.value == nil {
+				delete(claims, change.claim)
+			}
+			invalidTokens[name] = struct {
+				token       string
+				description string
+			}{signToken(header, claims, hs256(secret)), change.description}
+		}
+
+		for name, invalid := range invalidTokens {
+			response, _ = govalinHTTP.Raw().Do(http.MethodGet, url, bearer(invalid.token), nil)
+			_ = response.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, response.StatusCode, name)
+			assert.Equal(t,
+				`Bearer realm="govalin", error="invalid_token", error_description="`+invalid.description+`"`,
+				response.Header.Get("WWW-Authenticate"),
+				name,
+			)
+		}
+	})
+}
+
+func TestJWTKeySets(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	encodeInt := func(value *big.Int, size int) string {
+		return base64.RawURLEncoding.EncodeToString(value.FillBytes(make([]byte, size)))
+	}
+
+	rsaJWKS, _ := json.Marshal(map[string]any{"keys": []map[string]any{{
+		"kty": "RSA",
+		"kid": "rsa-1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}})
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(jwksPath, rsaJWKS, 0o600))
+
+	ecJWKS, _ := json.Marshal(map[string]any{"keys": []map[string]any{{
+		"kty": "EC",
+		"kid": "ec-1",
+		"alg": "ES256",
+		"crv": "P-256",
+		"x":   encodeInt(ecKey.X, 32),
+		"y":   encodeInt(ecKey.Y, 32),
+	}}})
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(ecJWKS)
+	}))
+	defer jwksServer.Close()
+
+	plugin := auth.NewJWT().
+		JWKSFile(jwksPath).
+		JWKSURL(jwksServer.URL).
+		RolesClaim("scope").
+		Path("/api/*")
+
+	claims := map[string]any{"sub": "user-2", "exp": time.Now().Unix() + 60, "scope": "read write"}
+	rs256 := func(signed []byte) []byte {
+		hash := sha256.Sum256(signed)
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+		return signature
+	}
+	es256 := func(signed []byte) []byte {
+		hash := sha256.Sum256(signed)
+		r, s, _ := ecdsa.Sign(rand.Reader, ecKey, hash[:])
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(plugin)
+		})
+
+		return app.Get("/api/whoami", func(call *govalin.Call) {
+			principal, _ := auth.PrincipalFrom(call)
+			call.Text(principal.Scheme + " " + principal.Subject + " " + strings.Join(principal.Roles, ","))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		url := govalinHTTP.Host + "/api/whoami"
+
+		response, _ := govalinHTTP.Raw().Do(http.MethodGet, url,
+			bearer(signToken(map[string]any{"alg": "RS256", "kid": "rsa-1"}, claims, rs256)), nil,
+		)
+		body, _ := response.ToString()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "Bearer user-2 read,write", body)
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url,
+			bearer(signToken(map[string]any{"alg": "ES256", "kid": "ec-1"}, claims, es256)), nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url,
+			bearer(signToken(map[string]any{"alg": "ES256", "kid": "rsa-1"}, claims, es256)), nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode, "Should only use keys of the algorithm and key ID")
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, url,
+			bearer(signToken(map[string]any{"alg": "HS256"}, claims, hs256(rsaKey.N.Bytes()))), nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode, "Should not verify HMAC using public keys")
+	})
+}
+
+func TestJWTKeySetReload(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecJWKS, _ := json.Marshal(map[string]any{"keys": []map[string]any{{
+		"kty": "EC",
+		"kid": "ec-1",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+	}}})
+
+	fetches := make(chan bool, 2)
+	release := make(chan bool)
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches <- true
+		if len(fetches) > 1 {
+			// Fail the reload after the first fetch, once released by the test
+			<-release
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(ecJWKS)
+	}))
+	defer jwksServer.Close()
+
+	plugin := auth.NewJWT().JWKSURL(jwksServer.URL).JWKSRefresh(0).Path("/api/*")
+	claims := map[string]any{"sub": "user-3", "exp": time.Now().Unix() + 60}
+	es256 := func(signed []byte) []byte {
+		hash := sha256.Sum256(signed)
+		r, s, _ := ecdsa.Sign(rand.Reader, ecKey, hash[:])
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	knownKey := bearer(signToken(map[string]any{"alg": "ES256", "kid": "ec-1"}, claims, es256))
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(plugin)
+		})
+
+		return app.Get("/api/whoami", func(call *govalin.Call) {
+			principal, _ := auth.PrincipalFrom(call)
+			call.Text(principal.Scheme + " " + principal.Subject + " " + strings.Join(principal.Roles, ","))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		url := govalinHTTP.Host + "/api/whoami"
+		knownKeyStatus := func() int {
+			response, _ := govalinHTTP.Raw().Do(http.MethodGet, url, knownKey, nil)
+			_ = response.Body.Close()
+
+			return response.StatusCode
+		}
+
+		assert.Equal(t, http.StatusOK, knownKeyStatus())
+
+		unknownKeyStatus := make(chan int)
+		go func() {
+			unknownKey := bearer(signToken(map[string]any{"alg": "ES256", "kid": "ec-2"}, claims, es256))
+			response, _ := govalinHTTP.Raw().Do(http.MethodGet, url, unknownKey, nil)
+			_ = response.Body.Close()
+			unknownKeyStatus <- response.StatusCode
+		}()
+
+		// Wait for the unknown key to trigger a reload, which is held by the server
+		assert.Eventually(t, func() bool { return len(fetches) == 2 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, http.StatusOK, knownKeyStatus(),
+			"Should verify tokens using known keys while reloading",
+		)
+
+		close(release)
+		assert.Equal(t, http.StatusUnauthorized, <-unknownKeyStatus)
+		assert.Equal(t, http.StatusOK, knownKeyStatus(),
+			"Should keep the previous keys when reloading fails",
+		)
+	})
+}
+
+func TestOptionalWithRBAC(t *testing.T) {
+	plugin := auth.NewAPIKey(auth.APIKeys(map[string]auth.Principal{
+		"reader-key": {Subject: "reader", Roles: []string{"reader"}},
+		"admin-key":  {Subject: "admin", Roles: []string{"admin"}},
+	})).Optional()
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(plugin)
+		})
+		app.AccessManager(govalin.RBAC(auth.RolesFrom))
+
+		app.Get("/public", func(call *govalin.Call) {
+			call.Text("public")
+		})
+		return app.Delete("/api/users", func(call *govalin.Call) {
+			call.Text("deleted")
+		}, func(route *govalin.Route) {
+			route.Roles("admin")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, "public", govalinHTTP.Get("/public"), "Should let calls without credentials continue")
+
+		response, _ := govalinHTTP.Raw().Do(
+			http.MethodGet,
+			govalinHTTP.Host+"/public",
+			map[string]string{"X-Api-Key": "unknown"},
+			nil,
+		)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode, "Should reject invalid credentials")
+
+		deleteUsers := func(key string) int {
+			headers := map[string]string{}
+			if key != "" {
+				headers["X-Api-Key"] = key
+			}
+			response, deleteErr := govalinHTTP.Raw().Do(http.MethodDelete, govalinHTTP.Host+"/api/users", headers, nil)
+			assert.NoError(t, deleteErr)
+			_ = response.Body.Close()
+
+			return response.StatusCode
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, deleteUsers(""))
+		assert.Equal(t, http.StatusForbidden, deleteUsers("reader-key"))
+		assert.Equal(t, http.StatusOK, deleteUsers("admin-key"))
+	})
+}
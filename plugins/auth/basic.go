@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const basicScheme = "Basic"
+
+// BasicVerifier verifies the username and password of a call, returning the
+// authenticated principal or false if the credentials are invalid.
+type BasicVerifier func(call *govalin.Call, username string, password string) (Principal, bool)
+
+// BasicUsers verifies credentials against the given passwords by username,
+// such as for protecting internal tools.
+func BasicUsers(passwords map[string]string) BasicVerifier {
+	return func(_ *govalin.Call, username string, password string) (Principal, bool) {
+		expected, found := passwords[username]
+		// Compare hashes to not leak the length of passwords through timing
+		expectedHash := sha256.Sum256([]byte(expected))
+		passwordHash := sha256.Sum256([]byte(password))
+		if subtle.ConstantTimeCompare(expectedHash[:], passwordHash[:]) != 1 || !found {
+			return Principal{}, false
+		}
+
+		return Principal{Subject: username}, true
+	}
+}
+
+// BasicConfig contains the configuration of the HTTP Basic authentication
+// plugin
+//
+// Calls without valid credentials in the Authorization header are responded
+// with 401 Unauthorized and a Basic challenge in the WWW-Authenticate header.
+// The principal of authenticated calls is published, see PrincipalFrom.
+type BasicConfig struct {
+	verifier BasicVerifier
+	realm    string
+	optional bool
+	paths    []string
+}
+
+// NewBasic creates an HTTP Basic authentication plugin verifying credentials
+// using the verifier, such as BasicUsers.
+func NewBasic(verifier BasicVerifier) *BasicConfig {
+	return &BasicConfig{
+		verifier: verifier,
+		realm:    defaultRealm,
+		paths:    []string{},
+	}
+}
+
+func (config *BasicConfig) Name() string {
+	return "Basic authentication plugin"
+}
+
+func (config *BasicConfig) OnInit(_ *govalin.Config) {}
+
+func (config *BasicConfig) Apply(app *govalin.App) {
+	applyToPaths(app, config.paths, config.authenticate)
+}
+
+// Path requires authentication for paths matching the path, such as '/admin/*'.
+// Defaults to all paths.
+func (config *BasicConfig) Path(path string) *BasicConfig {
+	config.paths = append(config.paths, path)
+	return config
+}
+
+// Realm sets the realm of the challenge, which browsers may show when asking
+// for credentials. Defaults to 'govalin'.
+func (config *BasicConfig) Realm(realm string) *BasicConfig {
+	config.realm = realm
+	return config
+}
+
+// Optional lets calls without credentials continue unauthenticated, such as for
+// leaving it to the access manager of the App to require authentication by
+// route, see govalin.RBAC. Calls with invalid credentials are still rejected.
+func (config *BasicConfig) Optional() *BasicConfig {
+	config.optional = true
+	return config
+}
+
+func (config *BasicConfig) authenticate(call *govalin.Call) bool {
+	params := map[string]string{"realm": config.realm, "charset": "UTF-8"}
+
+	authorization := call.Header(headers.Authorization)
+	username, password, ok := parseBasic(authorization)
+	if !ok {
+		if _, isBasic := cutScheme(authorization, basicScheme); config.optional && !isBasic {
+			return true
+		}
+		return unauthorized(call, basicScheme, params, "Missing basic credentials")
+	}
+
+	principal, valid := config.verifier(call, username, password)
+	if !valid {
+		return unauthorized(call, basicScheme, params, "Invalid username or password")
+	}
+	if principal.Subject == "" {
+		principal.Subject = username
+	}
+
+	return authenticated(call, basicScheme, principal)
+}
+
+// parseBasic parses the credentials of a Basic Authorization header.
+func parseBasic(authorization string) (string, string, bool) {
+	credentials, found := cutScheme(authorization, basicScheme)
+	if !found {
+		return "", "", false
+	}
+
+	decoded, decodeErr := base64.StdEncoding.DecodeString(credentials)
+	if decodeErr != nil {
+		return "", "", false
+	}
+
+	return strings.Cut(string(decoded), ":")
+}
+
+// cutScheme returns the credentials of the Authorization header if it uses the
+// scheme, which is case-insensitive.
+func cutScheme(authorization string, scheme string) (string, bool) {
+	if len(authorization) <= len(scheme) ||
+		!strings.EqualFold(authorization[:len(scheme)], scheme) ||
+		authorization[len(scheme)] != ' ' {
+		return "", false
+	}
+
+	return strings.TrimSpace(authorization[len(scheme)+1:]), true
+}
@@ -0,0 +1,289 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	algorithmHS256 = "HS256"
+	algorithmRS256 = "RS256"
+	algorithmES256 = "ES256"
+
+	defaultJWKSRefresh = time.Minute
+	jwksFetchTimeout   = 10 * time.Second
+	maxJWKSSize        = 1 << 20
+)
+
+// verificationKey is a key verifying signatures of tokens using the algorithm.
+type verificationKey struct {
+	id        string
+	algorithm string
+	key       any // key is a []byte, *rsa.PublicKey or *ecdsa.PublicKey
+}
+
+// jwksLoader loads the keys of a JSON Web Key Set.
+type jwksLoader func(ctx context.Context) ([]byte, error)
+
+// keySet contains the keys verifying tokens, reloading the key sets of the
+// loaders when a token uses an unknown key, such as after keys are rotated.
+type keySet struct {
+	mutex      sync.RWMutex
+	static     []verificationKey
+	loaded     [][]verificationKey // loaded contains the keys of each loader
+	loaders    []jwksLoader
+	refresh    time.Duration
+	lastLoaded time.Time
+	loading    chan struct{} // loading is closed when the current reload is done
+}
+
+func newKeySet() *keySet {
+	return &keySet{
+		static:  []verificationKey{},
+		loaded:  [][]verificationKey{},
+		loaders: []jwksLoader{},
+		refresh: defaultJWKSRefresh,
+	}
+}
+
+// candidates returns the keys which may have signed a token with the key ID
+// and algorithm, loading the key sets if none are known.
+func (keys *keySet) candidates(ctx context.Context, keyID string, algorithm string) []verificationKey {
+	if found := keys.find(keyID, algorithm); len(found) > 0 {
+		return found
+	}
+
+	keys.reload(ctx)
+	return keys.find(keyID, algorithm)
+}
+
+func (keys *keySet) find(keyID string, algorithm string) []verificationKey {
+	keys.mutex.RLock()
+	defer keys.mutex.RUnlock()
+
+	found := []verificationKey{}
+	for _, key := range slices.Concat(append([][]verificationKey{keys.static}, keys.loaded...)...) {
+		if key.algorithm == algorithm && (keyID == "" || key.id == "" || key.id == keyID) {
+			found = append(found, key)
+		}
+	}
+
+	return found
+}
+
+// reload loads the key sets, unless they were loaded within the refresh
+// interval, so tokens with unknown keys can't make the app hammer the loaders.
+// The key sets are loaded without holding the lock, so tokens using known keys
+// are verified meanwhile, while calls reloading at the same time wait for the
+// result. The previous keys of a loader are kept when loading them fails.
+func (keys *keySet) reload(ctx context.Context) {
+	keys.mutex.Lock()
+	if loading := keys.loading; loading != nil {
+		keys.mutex.Unlock()
+		select {
+		case <-loading:
+		case <-ctx.Done():
+		}
+		return
+	}
+	if len(keys.loaders) == 0 || (!keys.lastLoaded.IsZero() && time.Since(keys.lastLoaded) < keys.refresh) {
+		keys.mutex.Unlock()
+		return
+	}
+	keys.lastLoaded = time.Now()
+	loading := make(chan struct{})
+	keys.loading = loading
+	loaders := keys.loaders
+	keys.mutex.Unlock()
+
+	loaded := make([][]verificationKey, len(loaders))
+	for i, loader := range loaders {
+		jwks, loadErr := loader(ctx)
+		if loadErr != nil {
+			slog.Error("Failed to load JSON Web Key Set", "err", loadErr)
+			continue
+		}
+
+		parsed, parseErr := parseJWKS(jwks)
+		if parseErr != nil {
+			slog.Error("Failed to parse JSON Web Key Set", "err", parseErr)
+			continue
+		}
+		loaded[i] = parsed
+	}
+
+	keys.mutex.Lock()
+	defer keys.mutex.Unlock()
+
+	for len(keys.loaded) < len(loaded) {
+		keys.loaded = append(keys.loaded, []verificationKey{})
+	}
+	for i, parsed := range loaded {
+		if parsed != nil {
+			keys.loaded[i] = parsed
+		}
+	}
+	keys.loading = nil
+	close(loading)
+}
+
+func jwksFromFile(path string) jwksLoader {
+	return func(_ context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+func jwksFromURL(url string) jwksLoader {
+	client := &http.Client{Timeout: jwksFetchTimeout}
+
+	return func(ctx context.Context) ([]byte, error) {
+		// Don't let a disconnecting client cancel loading keys for everyone else
+		req, reqErr := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodGet, url, nil)
+		if reqErr != nil {
+			return nil, reqErr
+		}
+
+		response, getErr := client.Do(req)
+		if getErr != nil {
+			return nil, getErr
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d from %s", response.StatusCode, url)
+		}
+
+		return io.ReadAll(io.LimitReader(response.Body, maxJWKSSize))
+	}
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	K         string `json:"k"`
+}
+
+// parseJWKS parses the signature keys of a JSON Web Key Set, see RFC 7517,
+// skipping keys of unsupported types and algorithms.
+func parseJWKS(jwks []byte) ([]verificationKey, error) {
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if unmarshalErr := json.Unmarshal(jwks, &set); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+
+	keys := []verificationKey{}
+	for _, webKey := range set.Keys {
+		if webKey.Use != "" && webKey.Use != "sig" {
+			continue
+		}
+
+		key, parseErr := parseJSONWebKey(webKey)
+		if parseErr != nil {
+			slog.Warn("Skipping JSON Web Key", "kid", webKey.KeyID, "err", parseErr)
+			continue
+		}
+		if webKey.Algorithm != "" && webKey.Algorithm != key.algorithm {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func parseJSONWebKey(webKey jsonWebKey) (verificationKey, error) {
+	key := verificationKey{id: webKey.KeyID}
+
+	switch webKey.KeyType {
+	case "oct":
+		secret, decodeErr := base64.RawURLEncoding.DecodeString(webKey.K)
+		if decodeErr != nil {
+			return key, decodeErr
+		}
+		key.algorithm, key.key = algorithmHS256, secret
+	case "RSA":
+		n, nErr := decodeBigInt(webKey.N)
+		e, eErr := decodeBigInt(webKey.E)
+		if err := errors.Join(nErr, eErr); err != nil {
+			return key, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return key, errors.New("unsupported RSA exponent")
+		}
+		key.algorithm, key.key = algorithmRS256, &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		publicKey, ecErr := parseP256Key(webKey)
+		if ecErr != nil {
+			return key, ecErr
+		}
+		key.algorithm, key.key = algorithmES256, publicKey
+	default:
+		return key, fmt.Errorf("unsupported key type '%s'", webKey.KeyType)
+	}
+
+	return key, nil
+}
+
+func parseP256Key(webKey jsonWebKey) (*ecdsa.PublicKey, error) {
+	if webKey.Curve != "P-256" {
+		return nil, fmt.Errorf("unsupported curve '%s'", webKey.Curve)
+	}
+
+	x, xErr := base64.RawURLEncoding.DecodeString(webKey.X)
+	y, yErr := base64.RawURLEncoding.DecodeString(webKey.Y)
+	if err := errors.Join(xErr, yErr); err != nil {
+		return nil, err
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid P-256 coordinates")
+	}
+
+	// Reject points which aren't on the curve
+	uncompressed := append(append([]byte{4}, x...), y...)
+	if _, pointErr := ecdh.P256().NewPublicKey(uncompressed); pointErr != nil {
+		return nil, pointErr
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+func decodeBigInt(encoded string) (*big.Int, error) {
+	decoded, decodeErr := base64.RawURLEncoding.DecodeString(encoded)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	if len(decoded) == 0 {
+		return nil, errors.New("missing key parameter")
+	}
+
+	return new(big.Int).SetBytes(decoded), nil
+}
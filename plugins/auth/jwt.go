@@ -0,0 +1,388 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const (
+	bearerScheme      = "Bearer"
+	defaultRolesClaim = "roles"
+)
+
+var (
+	errTokenMalformed        = errors.New("the token is malformed")
+	errTokenAlgorithm        = errors.New("the token algorithm is not supported")
+	errTokenUnknownKey       = errors.New("the token signing key is unknown")
+	errTokenSignature        = errors.New("the token signature is invalid")
+	errTokenExpired          = errors.New("the token is expired")
+	errTokenNotYetValid      = errors.New("the token is not valid yet")
+	errTokenAudience         = errors.New("the token audience is not accepted")
+	errTokenIssuer           = errors.New("the token issuer is not accepted")
+	errTokenMissingExpiresAt = errors.New("the token has no expiration time")
+)
+
+// JWTConfig contains the configuration of the JWT bearer authentication plugin
+//
+// Calls must have a JSON Web Token signed using HS256, RS256 or ES256 by one of
+// the configured keys in the Authorization header. The exp and nbf claims of
+// tokens are checked, as well as the aud and iss claims if audiences or an
+// issuer are configured. Calls without a valid token are responded with 401
+// Unauthorized and a Bearer challenge in the WWW-Authenticate header, see
+// RFC 6750.
+//
+// The principal of authenticated calls is published, see PrincipalFrom. Its
+// subject is the sub claim, its roles the roles claim and its claims all claims
+// of the token, with numbers as json.Number values.
+type JWTConfig struct {
+	keys          *keySet
+	audiences     []string
+	issuer        string
+	leeway        time.Duration
+	requireExpiry bool
+	rolesClaim    string
+	realm         string
+	optional      bool
+	paths         []string
+}
+
+// NewJWT creates a JWT bearer authentication plugin. Configure the keys of the
+// plugin using HMACSecret, PublicKey, JWKSFile or JWKSURL.
+func NewJWT() *JWTConfig {
+	return &JWTConfig{
+		keys:          newKeySet(),
+		audiences:     []string{},
+		requireExpiry: true,
+		rolesClaim:    defaultRolesClaim,
+		realm:         defaultRealm,
+		paths:         []string{},
+	}
+}
+
+func (config *JWTConfig) Name() string {
+	return "JWT authentication plugin"
+}
+
+func (config *JWTConfig) OnInit(_ *govalin.Config) {
+	if len(config.keys.static) == 0 && len(config.keys.loaders) == 0 {
+		slog.Error("JWT authentication plugin has no keys, so every call will be unauthorized")
+	}
+}
+
+func (config *JWTConfig) Apply(app *govalin.App) {
+	applyToPaths(app, config.paths, config.authenticate)
+}
+
+// Path requires authentication for paths matching the path, such as '/api/*'.
+// Defaults to all paths.
+func (config *JWTConfig) Path(path string) *JWTConfig {
+	config.paths = append(config.paths, path)
+	return config
+}
+
+// HMACSecret adds a secret verifying tokens signed using HS256.
+func (config *JWTConfig) HMACSecret(secret []byte) *JWTConfig {
+	config.keys.static = append(config.keys.static, verificationKey{algorithm: algorithmHS256, key: secret})
+	return config
+}
+
+// PublicKey adds an RSA key verifying tokens signed using RS256, or a P-256
+// ECDSA key verifying tokens signed using ES256. The key only verifies tokens
+// with the given key ID in their kid header, unless the key ID is empty.
+func (config *JWTConfig) PublicKey(keyID string, publicKey crypto.PublicKey) *JWTConfig {
+	key := verificationKey{id: keyID, key: publicKey}
+
+	switch typedKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		key.algorithm = algorithmRS256
+	case *ecdsa.PublicKey:
+		if typedKey.Curve != elliptic.P256() {
+			slog.Error("Ignoring ECDSA key which doesn't use the P-256 curve", "kid", keyID)
+			return config
+		}
+		key.algorithm = algorithmES256
+	default:
+		slog.Error("Ignoring public key of unsupported type", "kid", keyID)
+		return config
+	}
+
+	config.keys.static = append(config.keys.static, key)
+	return config
+}
+
+// JWKSFile adds the keys of the JSON Web Key Set in the file. The file is read
+// when the first token is verified and read again when tokens use unknown keys.
+func (config *JWTConfig) JWKSFile(path string) *JWTConfig {
+	config.keys.loaders = append(config.keys.loaders, jwksFromFile(path))
+	return config
+}
+
+// JWKSURL adds the keys of the JSON Web Key Set at the URL, such as the JWKS
+// endpoint of a local identity provider. The keys are fetched when the first
+// token is verified and fetched again when tokens use unknown keys.
+func (config *JWTConfig) JWKSURL(url string) *JWTConfig {
+	config.keys.loaders = append(config.keys.loaders, jwksFromURL(url))
+	return config
+}
+
+// JWKSRefresh sets the minimum interval between loading the key sets of
+// JWKSFile and JWKSURL. Defaults to 1 minute.
+func (config *JWTConfig) JWKSRefresh(refresh time.Duration) *JWTConfig {
+	config.keys.refresh = refresh
+	return config
+}
+
+// Audience only accepts tokens with any of the audiences in their aud claim.
+func (config *JWTConfig) Audience(audiences ...string) *JWTConfig {
+	config.audiences = append(config.audiences, audiences...)
+	return config
+}
+
+// Issuer only accepts tokens with the issuer in their iss claim.
+func (config *JWTConfig) Issuer(issuer string) *JWTConfig {
+	config.issuer = issuer
+	return config
+}
+
+// Leeway sets the allowed clock skew when checking the exp and nbf claims.
+// Defaults to 0.
+func (config *JWTConfig) Leeway(leeway time.Duration) *JWTConfig {
+	config.leeway = leeway
+	return config
+}
+
+// RequireExpiry sets whether tokens without an exp claim are rejected. Defaults
+// to true.
+func (config *JWTConfig) RequireExpiry(requireExpiry bool) *JWTConfig {
+	config.requireExpiry = requireExpiry
+	return config
+}
+
+// RolesClaim sets the claim containing the roles of the principal, either as
+// an array or as a space-separated string. Defaults to 'roles'.
+func (config *JWTConfig) RolesClaim(rolesClaim string) *JWTConfig {
+	config.rolesClaim = rolesClaim
+	return config
+}
+
+// Realm sets the realm of the challenge. Defaults to 'govalin'.
+func (config *JWTConfig) Realm(realm string) *JWTConfig {
+	config.realm = realm
+	return config
+}
+
+// Optional lets calls without credentials continue unauthenticated, such as for
+// leaving it to the access manager of the App to require authentication by
+// route, see govalin.RBAC. Calls with invalid credentials are still rejected.
+func (config *JWTConfig) Optional() *JWTConfig {
+	config.optional = true
+	return config
+}
+
+func (config *JWTConfig) authenticate(call *govalin.Call) bool {
+	params := map[string]string{"realm": config.realm}
+
+	token, found := cutScheme(call.Header(headers.Authorization), bearerScheme)
+	if !found {
+		if config.optional {
+			return true
+		}
+		return unauthorized(call, bearerScheme, params, "Missing bearer token")
+	}
+
+	claims, verifyErr := config.verify(call.Context(), token, time.Now())
+	if verifyErr != nil {
+		params["error"] = "invalid_token"
+		params["error_description"] = verifyErr.Error()
+		return unauthorized(call, bearerScheme, params, "Invalid bearer token")
+	}
+
+	subject, _ := claims["sub"].(string)
+	return authenticated(call, bearerScheme, Principal{
+		Subject: subject,
+		Roles:   rolesFromClaim(claims[config.rolesClaim]),
+		Claims:  claims,
+	})
+}
+
+// verify verifies the signature and claims of the token at now, returning its
+// claims.
+func (config *JWTConfig) verify(ctx context.Context, token string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errTokenMalformed
+	}
+
+	header := struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}{}
+	if decodeErr := decodeSegment(parts[0], &header); decodeErr != nil {
+		return nil, decodeErr
+	}
+	if !slices.Contains([]string{algorithmHS256, algorithmRS256, algorithmES256}, header.Algorithm) {
+		return nil, errTokenAlgorithm
+	}
+
+	signature, signatureErr := base64.RawURLEncoding.DecodeString(parts[2])
+	if signatureErr != nil {
+		return nil, errTokenMalformed
+	}
+
+	keys := config.keys.candidates(ctx, header.KeyID, header.Algorithm)
+	if len(keys) == 0 {
+		return nil, errTokenUnknownKey
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	if !slices.ContainsFunc(keys, func(key verificationKey) bool {
+		return verifySignature(key, signed, signature)
+	}) {
+		return nil, errTokenSignature
+	}
+
+	claims := map[string]any{}
+	if decodeErr := decodeSegment(parts[1], &claims); decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	return claims, config.verifyClaims(claims, now)
+}
+
+func (config *JWTConfig) verifyClaims(claims map[string]any, now time.Time) error {
+	expiresAt, hasExpiry, expiryErr := numericDate(claims, "exp")
+	if expiryErr != nil {
+		return expiryErr
+	}
+	if !hasExpiry && config.requireExpiry {
+		return errTokenMissingExpiresAt
+	}
+	if hasExpiry && !now.Before(expiresAt.Add(config.leeway)) {
+		return errTokenExpired
+	}
+
+	notBefore, hasNotBefore, notBeforeErr := numericDate(claims, "nbf")
+	if notBeforeErr != nil {
+		return notBeforeErr
+	}
+	if hasNotBefore && now.Add(config.leeway).Before(notBefore) {
+		return errTokenNotYetValid
+	}
+
+	if len(config.audiences) > 0 && !slices.ContainsFunc(stringsFromClaim(claims["aud"]), func(audience string) bool {
+		return slices.Contains(config.audiences, audience)
+	}) {
+		return errTokenAudience
+	}
+
+	if config.issuer != "" && claims["iss"] != config.issuer {
+		return errTokenIssuer
+	}
+
+	return nil
+}
+
+func verifySignature(key verificationKey, signed []byte, signature []byte) bool {
+	hash := sha256.Sum256(signed)
+
+	switch typedKey := key.key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, typedKey)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(typedKey, crypto.SHA256, hash[:], signature) == nil
+	case *ecdsa.PublicKey:
+		// ES256 signatures are the concatenated 32 byte R and S values
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(typedKey, hash[:], r, s)
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, target any) error {
+	decoded, decodeErr := base64.RawURLEncoding.DecodeString(segment)
+	if decodeErr != nil {
+		return errTokenMalformed
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(decoded))
+	decoder.UseNumber()
+	if unmarshalErr := decoder.Decode(target); unmarshalErr != nil {
+		return errTokenMalformed
+	}
+
+	return nil
+}
+
+// numericDate returns the time of the NumericDate claim, see RFC 7519.
+func numericDate(claims map[string]any, claim string) (time.Time, bool, error) {
+	value, found := claims[claim]
+	if !found {
+		return time.Time{}, false, nil
+	}
+
+	number, isNumber := value.(json.Number)
+	if !isNumber {
+		return time.Time{}, false, errTokenMalformed
+	}
+	seconds, parseErr := number.Float64()
+	if parseErr != nil {
+		return time.Time{}, false, errTokenMalformed
+	}
+
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), true, nil
+}
+
+// stringsFromClaim returns the strings of a claim which is either a string or
+// an array of strings.
+func stringsFromClaim(value any) []string {
+	switch typedValue := value.(type) {
+	case string:
+		return []string{typedValue}
+	case []any:
+		values := []string{}
+		for _, item := range typedValue {
+			if itemString, ok := item.(string); ok {
+				values = append(values, itemString)
+			}
+		}
+		return values
+	default:
+		return []string{}
+	}
+}
+
+// rolesFromClaim returns the roles of a claim which is either an array of
+// strings or a space-separated string, such as the scope claim.
+func rolesFromClaim(value any) []string {
+	if roles, ok := value.(string); ok {
+		return strings.Fields(roles)
+	}
+
+	return stringsFromClaim(value)
+}
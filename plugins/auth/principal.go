@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const defaultRealm = "govalin"
+
+// PrincipalAttribute is the call attribute key of the Principal authenticated
+// by the plugins.
+const PrincipalAttribute = "govalin.auth.principal"
+
+// Principal is the authenticated client of a call. It's published as a call
+// attribute, see PrincipalFrom.
+type Principal struct {
+	Scheme  string         // Scheme is the authentication scheme, such as 'Basic', 'Bearer' or 'ApiKey'
+	Subject string         // Subject identifies the client, such as the username or the 'sub' claim
+	Roles   []string       // Roles are the roles of the client
+	Claims  map[string]any // Claims are the claims of bearer tokens, nil for other schemes
+}
+
+// HasRole returns true if the principal has any of the given roles.
+func (principal Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(principal.Roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PrincipalFrom returns the principal authenticated for the call.
+func PrincipalFrom(call *govalin.Call) (Principal, bool) {
+	return govalin.Attr[Principal](call, PrincipalAttribute)
+}
+
+// RolesFrom returns the roles of the principal authenticated for the call, or
+// false if the call isn't authenticated. It lets the access manager of the App
+// authorize calls by the roles of principals, see govalin.RBAC.
+func RolesFrom(call *govalin.Call) ([]string, bool) {
+	principal, found := PrincipalFrom(call)
+	return principal.Roles, found
+}
+
+// authenticated publishes the principal on the call, letting the call continue.
+func authenticated(call *govalin.Call, scheme string, principal Principal) bool {
+	principal.Scheme = scheme
+	call.Attribute(PrincipalAttribute, principal)
+
+	return true
+}
+
+// unauthorized responds with 401 Unauthorized, challenging the client to
+// authenticate using the scheme.
+func unauthorized(call *govalin.Call, scheme string, params map[string]string, detail string) bool {
+	call.SetHeader(headers.WwwAuthenticate, challenge(scheme, params))
+	call.Error(govalin.NewHTTPError(http.StatusUnauthorized, detail))
+
+	return false
+}
+
+// challenge formats the value of a WWW-Authenticate header, see RFC 9110.
+func challenge(scheme string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	// The realm is conventionally the first parameter
+	slices.SortFunc(keys, func(a string, b string) int {
+		switch {
+		case a == "realm":
+			return -1
+		case b == "realm":
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+
+	formatted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.ReplaceAll(strings.ReplaceAll(params[key], `\`, `\\`), `"`, `\"`)
+		formatted = append(formatted, key+`="`+value+`"`)
+	}
+
+	if len(formatted) == 0 {
+		return scheme
+	}
+
+	return scheme + " " + strings.Join(formatted, ", ")
+}
+
+// applyToPaths adds the before handler to the paths, or to all paths if none
+// are given.
+func applyToPaths(app *govalin.App, paths []string, beforeFunc govalin.BeforeFunc) {
+	if len(paths) == 0 {
+		paths = []string{"*"}
+	}
+
+	for _, path := range paths {
+		app.Before(path, beforeFunc)
+	}
+}
@@ -0,0 +1,205 @@
+package compression
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+
+	defaultMinSize = 1024
+)
+
+// supportedEncodings are the supported encodings in order of preference.
+var supportedEncodings = []string{encodingGzip, encodingDeflate}
+
+// Config contains the configuration of the compression plugin
+//
+// The plugin compresses responses using gzip or deflate, negotiated using the
+// Accept-Encoding header of the request. Only responses with a content type in
+// the allowlist which are bigger than the min size are compressed. Responses
+// which are already encoded, partial content responses and responses without
+// content are sent as is. Flushed data, such as streamed responses and
+// server-sent events, is compressed and flushed immediately.
+type Config struct {
+	level        int
+	minSize      int
+	contentTypes []string
+	gzipPool     sync.Pool
+	flatePool    sync.Pool
+}
+
+// New creates a compression plugin using the default compression level,
+// compressing common text based content types bigger than 1 KiB.
+func New() *Config {
+	return &Config{
+		level:   gzip.DefaultCompression,
+		minSize: defaultMinSize,
+		contentTypes: []string{
+			"text/*",
+			"application/json",
+			"application/x-ndjson",
+			"application/javascript",
+			"application/xml",
+			"application/problem+json",
+			"application/wasm",
+			"image/svg+xml",
+		},
+	}
+}
+
+func (config *Config) Name() string {
+	return "Compression plugin"
+}
+
+func (config *Config) OnInit(_ *govalin.Config) {
+}
+
+func (config *Config) Apply(app *govalin.App) {
+	app.Before("*", func(call *govalin.Call) bool {
+		encoding := negotiateEncoding(strings.Join(call.HeaderValues(headers.AcceptEncoding), ","))
+
+		var writer *compressWriter
+		call.WrapResponseWriter(func(w http.ResponseWriter) http.ResponseWriter {
+			writer = newCompressWriter(w, config, encoding)
+			return writer
+		})
+		call.OnComplete(writer.Close)
+
+		return true
+	})
+}
+
+// Level sets the compression level, from flate.BestSpeed (1) to
+// flate.BestCompression (9). Defaults to flate.DefaultCompression.
+func (config *Config) Level(level int) *Config {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		level = flate.DefaultCompression
+	}
+	config.level = level
+
+	return config
+}
+
+// MinSize sets the min size in bytes of responses to compress. Defaults to 1024.
+func (config *Config) MinSize(minSize int) *Config {
+	config.minSize = minSize
+
+	return config
+}
+
+// ContentTypes sets the allowlist of content types to compress. A content type
+// ending with '/*' allows all subtypes, such as 'text/*'.
+func (config *Config) ContentTypes(contentTypes ...string) *Config {
+	config.contentTypes = contentTypes
+
+	return config
+}
+
+// getCompressor returns a pooled compressor for the encoding writing to w, as
+// compressors are expensive to allocate.
+func (config *Config) getCompressor(encoding string, w io.Writer) compressor {
+	pool := config.pool(encoding)
+	if pooled, ok := pool.Get().(compressor); ok {
+		pooled.Reset(w)
+		return pooled
+	}
+
+	// The level is validated when configured, so creating writers can't fail
+	if encoding == encodingGzip {
+		writer, _ := gzip.NewWriterLevel(w, config.level)
+		return writer
+	}
+	writer, _ := flate.NewWriter(w, config.level)
+	return writer
+}
+
+func (config *Config) putCompressor(encoding string, writer compressor) {
+	config.pool(encoding).Put(writer)
+}
+
+func (config *Config) pool(encoding string) *sync.Pool {
+	if encoding == encodingGzip {
+		return &config.gzipPool
+	}
+
+	return &config.flatePool
+}
+
+// allowsContentType returns whether the content type is in the allowlist.
+func (config *Config) allowsContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	for _, allowed := range config.contentTypes {
+		if prefix, isWildcard := strings.CutSuffix(allowed, "*"); isWildcard {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiateEncoding returns the supported encoding with the highest quality in
+// given Accept-Encoding header, preferring gzip on ties. Returns an empty string
+// if no supported encoding is accepted.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := map[string]float64{}
+	wildcard := -1.0
+
+	for _, value := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(value, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, qValue, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				parsed, parseErr := strconv.ParseFloat(qValue, 64)
+				if parseErr != nil {
+					parsed = 0
+				}
+				quality = parsed
+			}
+		}
+
+		if coding == "*" {
+			wildcard = quality
+		} else {
+			qualities[coding] = quality
+		}
+	}
+
+	bestEncoding := ""
+	bestQuality := 0.0
+	for _, encoding := range supportedEncodings {
+		quality, found := qualities[encoding]
+		if !found {
+			quality = wildcard
+		}
+
+		if quality > bestQuality {
+			bestEncoding = encoding
+			bestQuality = quality
+		}
+	}
+
+	return bestEncoding
+}
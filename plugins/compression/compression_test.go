@@ -0,0 +1,235 @@
+package compression_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/plugins/compression"
+	"github.com/stretchr/testify/assert"
+)
+
+var largeText = strings.Repeat("govalin ", 512)
+
+// get requests the url with given Accept-Encoding header without decompressing
+// the response, returning the response and the decoded body.
+func get(t *testing.T, url string, acceptEncoding string) (*http.Response, string) {
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+
+	response, err := http.DefaultTransport.RoundTrip(req)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	return response, decode(t, response.Header.Get("Content-Encoding"), response.Body)
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	var reader io.Reader = body
+	switch encoding {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(body)
+		assert.NoError(t, err)
+		reader = gzipReader
+	case "deflate":
+		reader = flate.NewReader(body)
+	}
+
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+
+	return string(content)
+}
+
+func TestCompression(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(compression.New())
+		}).
+			Get("/large", func(call *govalin.Call) {
+				call.Text(largeText)
+			}).
+			Get("/small", func(call *govalin.Call) {
+				call.Text("govalin")
+			}).
+			Get("/binary", func(call *govalin.Call) {
+				_ = call.Stream("image/png", strings.NewReader(largeText))
+			}).
+			Get("/stream", func(call *govalin.Call) {
+				_ = govalin.StreamNDJSON(call, slices.Values([]string{"first", "second"}))
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, body := get(t, govalinHTTP.Host+"/large", "gzip")
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", response.Header.Get("Vary"))
+		assert.Equal(t, largeText, body)
+
+		response, body = get(t, govalinHTTP.Host+"/large", "gzip;q=0.5, deflate")
+		assert.Equal(t, "deflate", response.Header.Get("Content-Encoding"), "Should prefer the highest quality")
+		assert.Equal(t, largeText, body)
+
+		response, body = get(t, govalinHTTP.Host+"/large", "br, *;q=0.1")
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"), "Should use wildcards")
+		assert.Equal(t, largeText, body)
+
+		response, body = get(t, govalinHTTP.Host+"/large", "gzip;q=0, identity")
+		assert.Empty(t, response.Header.Get("Content-Encoding"), "Should not use refused encodings")
+		assert.Equal(t, "Accept-Encoding", response.Header.Get("Vary"))
+		assert.Equal(t, largeText, body)
+
+		response, body = get(t, govalinHTTP.Host+"/small", "gzip")
+		assert.Empty(t, response.Header.Get("Content-Encoding"), "Should not compress small responses")
+		assert.Equal(t, "Accept-Encoding", response.Header.Get("Vary"))
+		assert.Equal(t, "govalin", body)
+
+		response, body = get(t, govalinHTTP.Host+"/binary", "gzip")
+		assert.Empty(t, response.Header.Get("Content-Encoding"), "Should not compress disallowed content types")
+		assert.Empty(t, response.Header.Get("Vary"))
+		assert.Equal(t, largeText, body)
+
+		response, body = get(t, govalinHTTP.Host+"/stream", "gzip")
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"), "Should compress flushed responses")
+		assert.Equal(t, "\"first\"\n\"second\"\n", body)
+	})
+}
+
+func TestCompressionSSE(t *testing.T) {
+	received := make(chan bool)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(compression.New())
+		})
+		app.SSE("/events", func(client *govalin.SSEClient) {
+			_ = client.SendData("first")
+			<-received
+		})
+
+		return app
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, govalinHTTP.Host+"/events", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		response, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"))
+
+		// The event must arrive while the stream is still open
+		gzipReader, err := gzip.NewReader(response.Body)
+		assert.NoError(t, err)
+		line, _ := bufio.NewReader(gzipReader).ReadString('\n')
+		assert.Equal(t, "data: first\n", line)
+
+		received <- true
+	})
+}
+
+func TestCompressionStatic(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte(largeText), 0o600))
+	files := fstest.MapFS{"style.css": &fstest.MapFile{Data: []byte(largeText)}}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(compression.New())
+		}).
+			Static("/static", func(_ *govalin.Call, staticConfig *govalin.StaticConfig) {
+				staticConfig.WithStaticPath(dir)
+			}).
+			Static("/fs", func(_ *govalin.Call, staticConfig *govalin.StaticConfig) {
+				staticConfig.WithFS(files)
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, body := get(t, govalinHTTP.Host+"/static/app.js", "gzip")
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"))
+		assert.Equal(t, largeText, body)
+
+		response, body = get(t, govalinHTTP.Host+"/fs/style.css", "deflate")
+		assert.Equal(t, "deflate", response.Header.Get("Content-Encoding"))
+		assert.Equal(t, largeText, body)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, govalinHTTP.Host+"/static/app.js", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Range", "bytes=0-6")
+		response, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		body = decode(t, response.Header.Get("Content-Encoding"), response.Body)
+		response.Body.Close()
+		assert.Equal(t, 206, response.StatusCode)
+		assert.Empty(t, response.Header.Get("Content-Encoding"), "Should not compress partial content")
+		assert.Equal(t, "govalin", body)
+	})
+}
+
+// syncBuffer collects the access log written by the server.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (buffer *syncBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	return buffer.buffer.Write(data)
+}
+
+func (buffer *syncBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	return buffer.buffer.String()
+}
+
+func TestCompressionAccessLog(t *testing.T) {
+	logs := &syncBuffer{}
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableStartupLog(false)
+			config.AccessLog(func(accessLogConfig *govalin.AccessLogConfiguration) {
+				accessLogConfig.
+					Handler(slog.NewJSONHandler(logs, nil)).
+					Fields(govalin.AccessLogFieldResponseSize)
+			})
+			config.Plugin(compression.New())
+		}).Get("/large", func(call *govalin.Call) {
+			call.Text(largeText)
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, govalinHTTP.Host+"/large", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		response, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		compressed, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"))
+
+		assert.Eventually(t, func() bool { return logs.String() != "" }, time.Second, 5*time.Millisecond)
+		record := map[string]any{}
+		assert.NoError(t, json.Unmarshal([]byte(logs.String()), &record))
+		assert.Equal(t, float64(len(compressed)), record["response_size"],
+			"Should log the size of the compressed response")
+	})
+}
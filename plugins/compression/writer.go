@@ -0,0 +1,209 @@
+package compression
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/internal/http/writers"
+)
+
+// compressor is implemented by both gzip.Writer and flate.Writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+type writerState int
+
+const (
+	stateHeaders     writerState = iota // waiting for the status to be written
+	stateBuffering                      // buffering until the min size is reached
+	stateCompressing                    // compressing written data
+	statePassthrough                    // writing data as is
+)
+
+// compressWriter compresses the response when the content type and size of the
+// response allows it. Responses without a known length are buffered until the
+// min size is reached, the response is flushed or the call completes.
+type compressWriter struct {
+	writers.Wrapper
+	config     *Config
+	encoding   string
+	state      writerState
+	status     int
+	buffer     []byte
+	compressor compressor
+}
+
+func newCompressWriter(w http.ResponseWriter, config *Config, encoding string) *compressWriter {
+	return &compressWriter{
+		Wrapper:  writers.Wrapper{ResponseWriter: w},
+		config:   config,
+		encoding: encoding,
+		state:    stateHeaders,
+	}
+}
+
+func (writer *compressWriter) WriteHeader(status int) {
+	if writer.state != stateHeaders {
+		return
+	}
+
+	// Informational responses, such as switching protocols, are sent as is
+	if status < http.StatusOK {
+		writer.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	writer.status = status
+
+	responseHeaders := writer.Header()
+	if responseHeaders.Get(headers.ContentType) == "" {
+		// The content type is sniffed from the first write, like net/http does
+		writer.state = stateBuffering
+		return
+	}
+
+	writer.decide(responseHeaders.Get(headers.ContentLength))
+}
+
+func (writer *compressWriter) Write(data []byte) (int, error) {
+	if writer.state == stateHeaders {
+		writer.WriteHeader(http.StatusOK)
+	}
+
+	switch writer.state {
+	case stateBuffering:
+		if writer.Header().Get(headers.ContentType) == "" && len(data) > 0 {
+			writer.Header().Set(headers.ContentType, http.DetectContentType(data))
+		}
+
+		writer.buffer = append(writer.buffer, data...)
+		if len(writer.buffer) >= writer.config.minSize {
+			if startErr := writer.start(true); startErr != nil {
+				return 0, startErr
+			}
+		}
+
+		return len(data), nil
+	case stateCompressing:
+		return writer.compressor.Write(data)
+	default:
+		return writer.ResponseWriter.Write(data)
+	}
+}
+
+// Flush sends buffered data to the client, compressing it if the response is
+// compressible regardless of its size.
+func (writer *compressWriter) Flush() {
+	if writer.state == stateBuffering {
+		if startErr := writer.start(true); startErr != nil {
+			return
+		}
+	}
+
+	if writer.state == stateCompressing {
+		if flushErr := writer.compressor.Flush(); flushErr != nil {
+			return
+		}
+	}
+
+	writer.Wrapper.Flush()
+}
+
+// Close sends any buffered data and finishes the compressed stream.
+func (writer *compressWriter) Close() {
+	if writer.state == stateBuffering {
+		_ = writer.start(false)
+	}
+
+	if writer.state == stateCompressing {
+		// Errors are expected for bodies which aren't allowed, such as HEAD responses
+		if closeErr := writer.compressor.Close(); closeErr != nil {
+			slog.Debug("Failed to finish compressed response", "err", closeErr)
+		}
+		writer.config.putCompressor(writer.encoding, writer.compressor)
+		writer.compressor = nil
+		writer.state = statePassthrough
+	}
+}
+
+// decide chooses whether to compress, buffer or pass the response through once
+// the status and headers are known.
+func (writer *compressWriter) decide(contentLength string) {
+	if !writer.eligible() {
+		writer.state = statePassthrough
+		writer.ResponseWriter.WriteHeader(writer.status)
+		return
+	}
+
+	writer.state = stateBuffering
+	if length, parseErr := strconv.ParseInt(contentLength, 10, 64); parseErr == nil {
+		_ = writer.start(length >= int64(writer.config.minSize))
+	}
+}
+
+// eligible returns whether the content of the response can be compressed.
+func (writer *compressWriter) eligible() bool {
+	responseHeaders := writer.Header()
+
+	return writer.status != http.StatusNoContent && writer.status != http.StatusNotModified &&
+		writer.status != http.StatusPartialContent && responseHeaders.Get(headers.ContentRange) == "" &&
+		responseHeaders.Get(headers.ContentEncoding) == "" &&
+		writer.config.allowsContentType(responseHeaders.Get(headers.ContentType))
+}
+
+// start writes the status and buffered data, compressing the response if it's
+// eligible, the client accepts compression and given big enough.
+func (writer *compressWriter) start(bigEnough bool) error {
+	responseHeaders := writer.Header()
+
+	eligible := writer.eligible()
+	if eligible {
+		// Clients not accepting compression still need to know the response varies
+		addVary(responseHeaders)
+	}
+
+	if eligible && bigEnough && writer.encoding != "" {
+		responseHeaders.Del(headers.ContentLength)
+		responseHeaders.Set(headers.ContentEncoding, writer.encoding)
+
+		// The compressed response is no longer byte for byte identical
+		if etag := responseHeaders.Get(headers.ETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+			responseHeaders.Set(headers.ETag, "W/"+etag)
+		}
+
+		writer.ResponseWriter.WriteHeader(writer.status)
+		writer.compressor = writer.config.getCompressor(writer.encoding, writer.ResponseWriter)
+		writer.state = stateCompressing
+	} else {
+		writer.ResponseWriter.WriteHeader(writer.status)
+		writer.state = statePassthrough
+	}
+
+	buffer := writer.buffer
+	writer.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	_, writeErr := writer.Write(buffer)
+	return writeErr
+}
+
+func addVary(responseHeaders http.Header) {
+	for _, vary := range responseHeaders.Values(headers.Vary) {
+		for _, value := range strings.Split(vary, ",") {
+			if strings.EqualFold(strings.TrimSpace(value), headers.AcceptEncoding) {
+				return
+			}
+		}
+	}
+
+	responseHeaders.Add(headers.Vary, headers.AcceptEncoding)
+}
@@ -24,6 +24,22 @@ const (
 	nullOrigin = "null"
 )
 
+// DecisionAttribute is the call attribute key of the Decision made by the plugin.
+const DecisionAttribute = "govalin.cors.decision"
+
+// Decision describes how the CORS plugin handled a call. It's published as a
+// call attribute, see DecisionFrom.
+type Decision struct {
+	Origin    string // Origin is the Origin header of the request, empty for same origin requests
+	Allowed   bool   // Allowed is true if the origin was given CORS headers
+	Preflight bool   // Preflight is true for CORS preflight requests
+}
+
+// DecisionFrom returns the CORS decision made for the call.
+func DecisionFrom(call *govalin.Call) (Decision, bool) {
+	return govalin.Attr[Decision](call, DecisionAttribute)
+}
+
 func New() *Config {
 	return &Config{
 		allowedOrigins: []string{},
@@ -69,8 +85,14 @@ func (config *Config) checkConfiguration() {
 
 func (config *Config) handleCors(call *govalin.Call) bool {
 	origin := call.Header(headers.Origin)
+	decision := Decision{
+		Origin:    origin,
+		Allowed:   util.ContainsSome(config.allowedOrigins, wildcard, origin),
+		Preflight: call.Method() == http.MethodOptions && call.Header(headers.AccessControlRequestMethod) != "",
+	}
+	call.Attribute(DecisionAttribute, decision)
 
-	if !util.ContainsSome(config.allowedOrigins, wildcard, origin) {
+	if !decision.Allowed {
 		return true
 	}
 
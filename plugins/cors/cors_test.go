@@ -173,3 +173,33 @@ func TestShouldNotExitOnCorrectConfig(t *testing.T) {
 
 	assert.Equal(t, 0, exitCode)
 }
+
+func TestDecisionAttribute(t *testing.T) {
+	var decisions []cors.Decision
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.Plugin(cors.New().AllowOrigins("http://govalin.io"))
+		})
+		app.After("/*", func(call *govalin.Call) {
+			decision, ok := cors.DecisionFrom(call)
+			assert.True(t, ok)
+			decisions = append(decisions, decision)
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		_, _ = http.Raw().Begin().WithHeader(headers.Origin, "http://govalin.io").Get(http.Host + "/govalin")
+		_, _ = http.Raw().Begin().WithHeader(headers.Origin, "http://nogovalin.io").Get(http.Host + "/govalin")
+		_, _ = http.Raw().Begin().
+			WithHeader(headers.Origin, "http://govalin.io").
+			WithHeader(headers.AccessControlRequestMethod, "POST").
+			Options(http.Host + "/govalin")
+	})
+
+	assert.Equal(t, []cors.Decision{
+		{Origin: "http://govalin.io", Allowed: true},
+		{Origin: "http://nogovalin.io", Allowed: false},
+		{Origin: "http://govalin.io", Allowed: true, Preflight: true},
+	}, decisions)
+}
@@ -0,0 +1,266 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const (
+	defaultLivePath     = "/health/live"
+	defaultReadyPath    = "/health/ready"
+	defaultCheckTimeout = 5 * time.Second
+	sessionStoreCheck   = "session_store"
+	shutdownCheck       = "shutdown"
+)
+
+// Status is the status of a check or of the server.
+type Status string
+
+const (
+	// StatusUp means the check passed.
+	StatusUp Status = "up"
+	// StatusDown means the check failed.
+	StatusDown Status = "down"
+)
+
+var (
+	// ErrCheckTimeout is reported by checks which don't complete in time.
+	ErrCheckTimeout = errors.New("check timed out")
+	// ErrShuttingDown is reported by the readiness endpoint once the server has
+	// started shutting down.
+	ErrShuttingDown = errors.New("server is shutting down")
+)
+
+// CheckFunc checks a dependency of the server, such as pinging a database,
+// returning an error if it's unavailable. The context is cancelled when the
+// timeout of the check is exceeded.
+type CheckFunc func(ctx context.Context) error
+
+// Response is the aggregated status returned by the health endpoints.
+type Response struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult is the result of a single check.
+type CheckResult struct {
+	Status     Status  `json:"status"`
+	DurationMS float64 `json:"durationMs"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type check struct {
+	name    string
+	check   CheckFunc
+	timeout time.Duration
+}
+
+// Config contains the configuration of the health plugin
+//
+// The plugin adds a liveness endpoint, telling whether the server should be
+// restarted, and a readiness endpoint, telling whether the server should receive
+// traffic. Both run their checks concurrently and respond with the aggregated
+// status as JSON, with 200 OK when all checks pass and 503 Service Unavailable
+// otherwise. The readiness endpoint fails once App.Shutdown begins, letting load
+// balancers drain the server before it stops, see DrainDelay.
+type Config struct {
+	livePath          string
+	readyPath         string
+	timeout           time.Duration
+	drainDelay        time.Duration
+	liveChecks        []check
+	readyChecks       []check
+	checkSessionStore bool
+	shuttingDown      atomic.Bool
+}
+
+// New creates a health plugin.
+func New() *Config {
+	return &Config{
+		livePath:    defaultLivePath,
+		readyPath:   defaultReadyPath,
+		timeout:     defaultCheckTimeout,
+		liveChecks:  []check{},
+		readyChecks: []check{},
+	}
+}
+
+func (config *Config) Name() string {
+	return "Health plugin"
+}
+
+func (config *Config) OnInit(conf *govalin.Config) {
+	conf.Events(func(serverEvents *govalin.ServerEvents) {
+		serverEvents.AddOnServerShutdown(func() {
+			config.shuttingDown.Store(true)
+			// Shutdown events are run before the server stops accepting calls
+			time.Sleep(config.drainDelay)
+		})
+	})
+}
+
+func (config *Config) Apply(app *govalin.App) {
+	readyChecks := config.readyChecks
+	if config.checkSessionStore {
+		readyChecks = append(readyChecks, check{
+			name:  sessionStoreCheck,
+			check: app.PingSessionStore,
+		})
+	}
+
+	hidden := func(route *govalin.Route) {
+		route.Hidden()
+	}
+
+	app.Get(config.livePath, func(call *govalin.Call) {
+		config.respond(call, config.runChecks(call.Context(), config.liveChecks))
+	}, hidden)
+
+	app.Get(config.readyPath, func(call *govalin.Call) {
+		results := config.runChecks(call.Context(), readyChecks)
+		if config.shuttingDown.Load() {
+			results[shutdownCheck] = CheckResult{Status: StatusDown, Error: ErrShuttingDown.Error()}
+		}
+		config.respond(call, results)
+	}, hidden)
+}
+
+// LivePath sets the path of the liveness endpoint. Defaults to '/health/live'.
+func (config *Config) LivePath(path string) *Config {
+	config.livePath = path
+	return config
+}
+
+// ReadyPath sets the path of the readiness endpoint. Defaults to '/health/ready'.
+func (config *Config) ReadyPath(path string) *Config {
+	config.readyPath = path
+	return config
+}
+
+// Timeout sets the default timeout of checks. Defaults to 5 seconds.
+func (config *Config) Timeout(timeout time.Duration) *Config {
+	if timeout > 0 {
+		config.timeout = timeout
+	}
+	return config
+}
+
+// DrainDelay sets how long shutdown waits after the readiness endpoint starts
+// failing, giving load balancers time to stop sending calls to the server
+// before it stops accepting them. Defaults to no delay.
+func (config *Config) DrainDelay(drainDelay time.Duration) *Config {
+	config.drainDelay = drainDelay
+	return config
+}
+
+// Check adds a named readiness check, such as pinging a database the server
+// can't handle calls without. The timeout of the plugin is used unless a
+// timeout is given.
+func (config *Config) Check(name string, checkFunc CheckFunc, timeout ...time.Duration) *Config {
+	config.readyChecks = append(config.readyChecks, config.newCheck(name, checkFunc, timeout))
+	return config
+}
+
+// LiveCheck adds a named liveness check. Liveness checks should only fail when
+// the server can't recover without being restarted, such as when it's
+// deadlocked, as failing dependencies would otherwise restart every server.
+func (config *Config) LiveCheck(name string, checkFunc CheckFunc, timeout ...time.Duration) *Config {
+	config.liveChecks = append(config.liveChecks, config.newCheck(name, checkFunc, timeout))
+	return config
+}
+
+// SessionStore adds a readiness check named 'session_store' checking that the
+// session store of the app can be reached, see govalin.App.PingSessionStore.
+func (config *Config) SessionStore() *Config {
+	config.checkSessionStore = true
+	return config
+}
+
+func (config *Config) newCheck(name string, checkFunc CheckFunc, timeout []time.Duration) check {
+	created := check{name: name, check: checkFunc}
+	if len(timeout) > 0 && timeout[0] > 0 {
+		created.timeout = timeout[0]
+	}
+
+	return created
+}
+
+func (config *Config) respond(call *govalin.Call, results map[string]CheckResult) {
+	response := Response{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status == StatusDown {
+			response.Status = StatusDown
+		}
+	}
+
+	call.Header(headers.CacheControl, "no-store")
+	if response.Status == StatusDown {
+		call.Status(http.StatusServiceUnavailable)
+	}
+	call.JSON(response)
+}
+
+// runChecks runs the checks concurrently, waiting for all of them to complete
+// or time out.
+func (config *Config) runChecks(ctx context.Context, checks []check) map[string]CheckResult {
+	results := make(map[string]CheckResult, len(checks))
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, current := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			timeout := current.timeout
+			if timeout == 0 {
+				timeout = config.timeout
+			}
+			result := runCheck(ctx, current.check, timeout)
+
+			mutex.Lock()
+			results[current.name] = result
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func runCheck(ctx context.Context, checkFunc CheckFunc, timeout time.Duration) CheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- checkFunc(checkCtx)
+	}()
+
+	var checkErr error
+	select {
+	case checkErr = <-done:
+	case <-checkCtx.Done():
+		// Checks ignoring their context are left behind to complete by themselves
+		checkErr = ErrCheckTimeout
+	}
+
+	result := CheckResult{
+		Status:     StatusUp,
+		DurationMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if checkErr != nil {
+		result.Status = StatusDown
+		result.Error = checkErr.Error()
+	}
+
+	return result
+}
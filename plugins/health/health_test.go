@@ -0,0 +1,103 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/plugins/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func getHealth(t *testing.T, url string) (int, health.Response) {
+	response, getErr := http.Get(url)
+	assert.NoError(t, getErr)
+	defer response.Body.Close()
+
+	healthResponse := health.Response{}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&healthResponse))
+
+	return response.StatusCode, healthResponse
+}
+
+func TestHealthChecks(t *testing.T) {
+	cacheErr := errors.New("cache unavailable")
+	var failCache atomic.Bool
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.EnableSessions()
+			config.Plugin(health.New().
+				Timeout(time.Second).
+				SessionStore().
+				Check("cache", func(_ context.Context) error {
+					if failCache.Load() {
+						return cacheErr
+					}
+					return nil
+				}).
+				Check("database", func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}, 20*time.Millisecond).
+				LiveCheck("goroutines", func(_ context.Context) error {
+					return nil
+				}))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		status, live := getHealth(t, govalinHTTP.Host+"/health/live")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, health.StatusUp, live.Status)
+		assert.Equal(t, health.StatusUp, live.Checks["goroutines"].Status)
+		assert.NotContains(t, live.Checks, "cache", "Should only run liveness checks")
+
+		start := time.Now()
+		status, ready := getHealth(t, govalinHTTP.Host+"/health/ready")
+		assert.Less(t, time.Since(start), 500*time.Millisecond, "Should use the timeout of the check")
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, health.StatusDown, ready.Status)
+		assert.Equal(t, health.StatusUp, ready.Checks["cache"].Status)
+		assert.Equal(t, health.StatusUp, ready.Checks["session_store"].Status)
+		assert.Equal(t, health.StatusDown, ready.Checks["database"].Status)
+		assert.Equal(t, health.ErrCheckTimeout.Error(), ready.Checks["database"].Error)
+
+		failCache.Store(true)
+		_, ready = getHealth(t, govalinHTTP.Host+"/health/ready")
+		assert.Equal(t, "cache unavailable", ready.Checks["cache"].Error)
+	})
+}
+
+func TestHealthReadinessFailsOnShutdown(t *testing.T) {
+	var app *govalin.App
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app = govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(health.New().ReadyPath("/ready").DrainDelay(200 * time.Millisecond))
+		})
+		return app
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		status, ready := getHealth(t, govalinHTTP.Host+"/ready")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, health.StatusUp, ready.Status)
+
+		go func() {
+			_ = app.Shutdown()
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		status, ready = getHealth(t, govalinHTTP.Host+"/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, status, "Should fail readiness while draining")
+		assert.Equal(t, health.ErrShuttingDown.Error(), ready.Checks["shutdown"].Error)
+
+		status, _ = getHealth(t, govalinHTTP.Host+"/health/live")
+		assert.Equal(t, http.StatusOK, status, "Should stay live while draining")
+	})
+}
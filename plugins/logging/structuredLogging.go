@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"io"
 	"os"
 
 	"log/slog"
@@ -8,14 +9,37 @@ import (
 	"github.com/pkkummermo/govalin"
 )
 
+// Format is the format of log records.
+type Format int
+
+const (
+	// FormatJSON writes log records as JSON objects, one per line.
+	FormatJSON Format = iota
+	// FormatText writes log records as key=value pairs, one record per line.
+	FormatText
+)
+
+// ReplaceAttrFunc rewrites or removes attributes of log records before they're
+// written, see slog.HandlerOptions.ReplaceAttr.
+type ReplaceAttrFunc func(groups []string, attr slog.Attr) slog.Attr
+
 type StructuredLoggingConfig struct {
-	logLevel slog.Level
+	logLevel    slog.Level
+	format      Format
+	writer      io.Writer
+	addSource   bool
+	replaceAttr ReplaceAttrFunc
 }
 
 // NewStructuredLogging configures slog to use structured logging as default.
+//
+// Records are written as JSON to stdout by default. Loggers of calls, see
+// govalin.Call.Logger, log through the configured handler.
 func NewStructuredLogging() *StructuredLoggingConfig {
 	return &StructuredLoggingConfig{
 		logLevel: slog.LevelInfo,
+		format:   FormatJSON,
+		writer:   os.Stdout,
 	}
 }
 
@@ -24,19 +48,60 @@ func (config *StructuredLoggingConfig) Name() string {
 }
 
 func (config *StructuredLoggingConfig) OnInit(_ *govalin.Config) {
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		AddSource:   false,
-		Level:       config.logLevel,
-		ReplaceAttr: nil,
-	})
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(config.Handler()))
 }
 
 func (config *StructuredLoggingConfig) Apply(_ *govalin.App) {
 }
 
+// Handler creates the handler configured by the plugin, such as for access logs
+// written to a separate destination.
+func (config *StructuredLoggingConfig) Handler() slog.Handler {
+	options := &slog.HandlerOptions{
+		AddSource:   config.addSource,
+		Level:       config.logLevel,
+		ReplaceAttr: config.replaceAttr,
+	}
+
+	if config.format == FormatText {
+		return slog.NewTextHandler(config.writer, options)
+	}
+
+	return slog.NewJSONHandler(config.writer, options)
+}
+
 func (config *StructuredLoggingConfig) LogLevel(logLevel slog.Level) *StructuredLoggingConfig {
 	config.logLevel = logLevel
 
 	return config
 }
+
+// Format sets the format of log records. Defaults to FormatJSON.
+func (config *StructuredLoggingConfig) Format(format Format) *StructuredLoggingConfig {
+	config.format = format
+
+	return config
+}
+
+// Writer sets the writer log records are written to. Defaults to stdout.
+func (config *StructuredLoggingConfig) Writer(writer io.Writer) *StructuredLoggingConfig {
+	config.writer = writer
+
+	return config
+}
+
+// AddSource sets whether records include the source file and line of the log
+// call. Defaults to false.
+func (config *StructuredLoggingConfig) AddSource(addSource bool) *StructuredLoggingConfig {
+	config.addSource = addSource
+
+	return config
+}
+
+// ReplaceAttr sets a function rewriting attributes before records are written,
+// such as for redacting sensitive attributes like 'session_id'.
+func (config *StructuredLoggingConfig) ReplaceAttr(replaceAttr ReplaceAttrFunc) *StructuredLoggingConfig {
+	config.replaceAttr = replaceAttr
+
+	return config
+}
@@ -0,0 +1,39 @@
+package logging_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/plugins/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredLogging(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	var buffer bytes.Buffer
+	govalin.New(func(config *govalin.Config) {
+		config.Plugin(logging.NewStructuredLogging().
+			Format(logging.FormatText).
+			Writer(&buffer).
+			LogLevel(slog.LevelDebug).
+			AddSource(true).
+			ReplaceAttr(func(_ []string, attr slog.Attr) slog.Attr {
+				if attr.Key == "session_id" {
+					return slog.String(attr.Key, "REDACTED")
+				}
+				return attr
+			}))
+	})
+
+	slog.Debug("session started", "session_id", "secret")
+
+	assert.Contains(t, buffer.String(), "level=DEBUG")
+	assert.Contains(t, buffer.String(), `msg="session started"`)
+	assert.Contains(t, buffer.String(), "session_id=REDACTED")
+	assert.Contains(t, buffer.String(), "source=", "Should add the source of records")
+	assert.Contains(t, buffer.String(), "structuredLogging_test.go")
+}
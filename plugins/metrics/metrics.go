@@ -0,0 +1,338 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/internal/http/writers"
+)
+
+const (
+	defaultPath            = "/metrics"
+	defaultNamespace       = "govalin"
+	defaultShutdownTimeout = 5 * time.Second
+	unmatchedRoute         = "unmatched"
+	otherMethod            = "OTHER"
+)
+
+// standardMethods are the methods used as is in the method label. Other methods
+// are labeled as otherMethod, as clients choose the method freely and could
+// otherwise create an unbounded number of series.
+var standardMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// DefaultSizeBuckets are the default histogram buckets of request and response
+// body sizes in bytes.
+var DefaultSizeBuckets = []float64{100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000}
+
+// Config contains the configuration of the metrics plugin
+//
+// The plugin records the count, duration and body sizes of calls labeled by
+// method, route pattern and status, the number of calls in flight, the number
+// of open websocket connections and the count and duration of session store
+// operations. Calls not matching any route are labeled with the route
+// 'unmatched', and calls with non-standard methods with the method 'OTHER',
+// keeping the number of series bounded.
+//
+// Metrics are served in the Prometheus text exposition format on '/metrics' of
+// the app, or on a separate port, see Port. Custom metrics of the app can be
+// added to the registry of the plugin, see Registry.
+type Config struct {
+	path            string
+	port            uint16
+	namespace       string
+	registry        *Registry
+	durationBuckets []float64
+	sizeBuckets     []float64
+
+	requests          *Counter
+	requestDuration   *Histogram
+	requestsInFlight  *Gauge
+	requestSize       *Histogram
+	responseSize      *Histogram
+	websockets        *Gauge
+	sessionOperations *Counter
+	sessionDuration   *Histogram
+
+	serverMutex sync.Mutex
+	server      *http.Server
+}
+
+// New creates a metrics plugin.
+func New() *Config {
+	return &Config{
+		path:            defaultPath,
+		namespace:       defaultNamespace,
+		registry:        NewRegistry(),
+		durationBuckets: DefaultBuckets,
+		sizeBuckets:     DefaultSizeBuckets,
+	}
+}
+
+func (config *Config) Name() string {
+	return "Metrics plugin"
+}
+
+func (config *Config) OnInit(conf *govalin.Config) {
+	config.registerMetrics()
+
+	conf.Events(func(serverEvents *govalin.ServerEvents) {
+		serverEvents.AddCallObserver(config)
+		if config.port != 0 {
+			serverEvents.AddOnServerStartup(config.startServer)
+			serverEvents.AddOnServerStopped(config.stopServer)
+		}
+	})
+}
+
+func (config *Config) Apply(app *govalin.App) {
+	if config.port != 0 {
+		return
+	}
+
+	app.Get(config.path, func(call *govalin.Call) {
+		call.SetHeader(headers.ContentType, ContentType)
+		if _, writeErr := config.registry.WriteTo(call.Writer()); writeErr != nil {
+			slog.Debug("Failed to write metrics", "err", writeErr)
+		}
+	}, func(route *govalin.Route) {
+		route.Hidden()
+	})
+}
+
+// Path sets the path serving the metrics. Defaults to '/metrics'.
+func (config *Config) Path(path string) *Config {
+	config.path = path
+	return config
+}
+
+// Port serves the metrics on a separate port instead of on the app, keeping
+// them private when only the port of the app is exposed.
+func (config *Config) Port(port uint16) *Config {
+	config.port = port
+	return config
+}
+
+// Namespace sets the prefix of the names of the metrics of the plugin. Defaults
+// to 'govalin', such as 'govalin_http_requests_total'.
+func (config *Config) Namespace(namespace string) *Config {
+	config.namespace = namespace
+	return config
+}
+
+// Registry sets the registry of the metrics, letting the app add its own
+// metrics to the served metrics. Defaults to a new registry.
+func (config *Config) Registry(registry *Registry) *Config {
+	config.registry = registry
+	return config
+}
+
+// DurationBuckets sets the histogram buckets of durations in seconds. Defaults
+// to DefaultBuckets.
+func (config *Config) DurationBuckets(buckets ...float64) *Config {
+	if len(buckets) > 0 {
+		config.durationBuckets = buckets
+	}
+	return config
+}
+
+// SizeBuckets sets the histogram buckets of body sizes in bytes. Defaults to
+// DefaultSizeBuckets.
+func (config *Config) SizeBuckets(buckets ...float64) *Config {
+	if len(buckets) > 0 {
+		config.sizeBuckets = buckets
+	}
+	return config
+}
+
+func (config *Config) registerMetrics() {
+	name := func(name string) string {
+		if config.namespace == "" {
+			return name
+		}
+		return config.namespace + "_" + name
+	}
+	registry := config.registry
+
+	config.requests = registry.Counter(
+		name("http_requests_total"), "Total number of handled HTTP requests.",
+		"method", "route", "status",
+	)
+	config.requestDuration = registry.Histogram(
+		name("http_request_duration_seconds"), "Duration of handling HTTP requests in seconds.",
+		config.durationBuckets, "method", "route", "status",
+	)
+	config.requestsInFlight = registry.Gauge(
+		name("http_requests_in_flight"), "Number of HTTP requests currently being handled.",
+	)
+	config.requestSize = registry.Histogram(
+		name("http_request_size_bytes"), "Size of HTTP request bodies in bytes.",
+		config.sizeBuckets, "method", "route", "status",
+	)
+	config.responseSize = registry.Histogram(
+		name("http_response_size_bytes"), "Size of HTTP response bodies in bytes.",
+		config.sizeBuckets, "method", "route", "status",
+	)
+	config.websockets = registry.Gauge(
+		name("websocket_connections"), "Number of open websocket connections.",
+		"route",
+	)
+	config.sessionOperations = registry.Counter(
+		name("session_store_operations_total"), "Total number of session store operations.",
+		"operation", "result",
+	)
+	config.sessionDuration = registry.Histogram(
+		name("session_store_operation_duration_seconds"), "Duration of session store operations in seconds.",
+		config.durationBuckets, "operation",
+	)
+}
+
+// OnCallStart counts the call as in flight, and records the call when it
+// completes.
+func (config *Config) OnCallStart(call *govalin.Call) {
+	start := time.Now()
+	config.requestsInFlight.Inc()
+
+	request := &countingReader{ReadCloser: call.Raw.Req.Body}
+	if call.Raw.Req.Body != nil && call.Raw.Req.Body != http.NoBody {
+		call.Raw.Req.Body = request
+	}
+
+	var response *countingResponseWriter
+	call.WrapResponseWriter(func(w http.ResponseWriter) http.ResponseWriter {
+		response = &countingResponseWriter{Wrapper: writers.Wrapper{ResponseWriter: w}}
+		return response
+	})
+
+	call.OnComplete(func() {
+		config.requestsInFlight.Dec()
+
+		status := call.Status()
+		if call.Disconnected() {
+			status = govalin.StatusClientClosedRequest
+		}
+		route := call.RoutePattern()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := call.Method()
+		if _, isStandard := standardMethods[method]; !isStandard {
+			method = otherMethod
+		}
+		labels := []string{method, route, strconv.Itoa(status)}
+
+		config.requests.Inc(labels...)
+		config.requestDuration.Observe(time.Since(start).Seconds(), labels...)
+		config.requestSize.Observe(float64(max(request.read, call.Raw.Req.ContentLength)), labels...)
+		config.responseSize.Observe(float64(response.written), labels...)
+	})
+}
+
+// OnCallPhase records session store operations and open websocket connections.
+func (config *Config) OnCallPhase(call *govalin.Call, phase govalin.CallPhase, operation string) func(err error) {
+	switch phase {
+	case govalin.CallPhaseSessionStore:
+		start := time.Now()
+		return func(err error) {
+			result := "success"
+			if err != nil {
+				result = "error"
+			}
+			config.sessionOperations.Inc(operation, result)
+			config.sessionDuration.Observe(time.Since(start).Seconds(), operation)
+		}
+	case govalin.CallPhaseWebsocket:
+		route := call.RoutePattern()
+		config.websockets.Inc(route)
+		return func(_ error) {
+			config.websockets.Dec(route)
+		}
+	default:
+		return nil
+	}
+}
+
+func (config *Config) startServer() {
+	listener, listenErr := net.Listen("tcp", fmt.Sprintf(":%d", config.port))
+	if listenErr != nil {
+		slog.Error("Failed to start metrics server", "port", config.port, "err", listenErr)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(config.path, config.registry)
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: defaultShutdownTimeout,
+	}
+
+	config.serverMutex.Lock()
+	config.server = server
+	config.serverMutex.Unlock()
+
+	go func() {
+		if serveErr := server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			slog.Error("Metrics server failed", "err", serveErr)
+		}
+	}()
+}
+
+func (config *Config) stopServer() {
+	config.serverMutex.Lock()
+	server := config.server
+	config.server = nil
+	config.serverMutex.Unlock()
+
+	if server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+	if shutdownErr := server.Shutdown(ctx); shutdownErr != nil {
+		slog.Error("Failed to shut down metrics server", "err", shutdownErr)
+	}
+}
+
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	read int64
+}
+
+func (reader *countingReader) Read(data []byte) (int, error) {
+	read, readErr := reader.ReadCloser.Read(data)
+	reader.read += int64(read)
+	return read, readErr
+}
+
+// countingResponseWriter counts the bytes written to the response body.
+type countingResponseWriter struct {
+	writers.Wrapper
+	written int64
+}
+
+func (writer *countingResponseWriter) Write(data []byte) (int, error) {
+	written, writeErr := writer.ResponseWriter.Write(data)
+	writer.written += int64(written)
+	return written, writeErr
+}
@@ -0,0 +1,159 @@
+package metrics_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/plugins/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	signups := registry.Counter("signups_total", "Total number of signups.", "plan")
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.EnableSessions()
+			config.Plugin(metrics.New().Registry(registry))
+		}).
+			Get("/users/{id}", func(call *govalin.Call) {
+				call.Text("user " + call.PathParam("id"))
+			}).
+			Post("/signup", func(call *govalin.Call) {
+				body, _ := io.ReadAll(call.Raw.Req.Body)
+				signups.Inc(string(body))
+				call.Status(http.StatusCreated)
+				call.Text("welcome")
+			}).
+			Ws("/ws", func(_ *govalin.WsConfig) {})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, "user 1", govalinHTTP.Get("/users/1"))
+		assert.Equal(t, "user 2", govalinHTTP.Get("/users/2"))
+		govalinHTTP.Get("/missing")
+		request, _ := http.NewRequest("PURGE", govalinHTTP.Host+"/missing", nil)
+		response, purgeErr := http.DefaultClient.Do(request)
+		assert.NoError(t, purgeErr)
+		_ = response.Body.Close()
+
+		response, postErr := http.Post(govalinHTTP.Host+"/signup", "text/plain", strings.NewReader("premium"))
+		assert.NoError(t, postErr)
+		_ = response.Body.Close()
+
+		ws := govalinHTTP.Websocket("/ws")
+		time.Sleep(50 * time.Millisecond)
+
+		response, _ = http.Get(govalinHTTP.Host + "/metrics")
+		body, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		exposition := string(body)
+
+		assert.Equal(t, metrics.ContentType, response.Header.Get("Content-Type"))
+		assert.Contains(t, exposition, "# TYPE govalin_http_requests_total counter\n")
+		assert.Contains(t, exposition,
+			`govalin_http_requests_total{method="GET",route="/users/{id}",status="200"} 2`+"\n",
+			"Should label requests by route pattern",
+		)
+		assert.Contains(t, exposition, `govalin_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+		assert.Contains(t, exposition,
+			`govalin_http_requests_total{method="OTHER",route="unmatched",status="404"} 1`,
+			"Should not label requests by non-standard methods",
+		)
+		assert.NotContains(t, exposition, "PURGE")
+		assert.Contains(t, exposition,
+			`govalin_http_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`,
+		)
+		assert.Contains(t, exposition,
+			`govalin_http_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="200",le="+Inf"} 2`,
+		)
+		assert.Contains(t, exposition,
+			`govalin_http_request_size_bytes_sum{method="POST",route="/signup",status="201"} 7`,
+		)
+		assert.Contains(t, exposition,
+			`govalin_http_response_size_bytes_sum{method="POST",route="/signup",status="201"} 7`,
+		)
+		assert.Contains(t, exposition, "govalin_http_requests_in_flight 1\n", "Should count the metrics request")
+		assert.Contains(t, exposition, `govalin_websocket_connections{route="/ws"} 1`)
+		assert.Contains(t, exposition, `govalin_session_store_operations_total{operation="CreateSession",result="success"}`)
+		assert.Contains(t, exposition, `govalin_session_store_operation_duration_seconds_count{operation="GetSession"}`)
+		assert.Contains(t, exposition, `signups_total{plan="premium"} 1`, "Should serve custom metrics")
+		assert.NotContains(t, exposition, "/users/1", "Should not label requests by raw path")
+
+		_ = ws.Close()
+		time.Sleep(50 * time.Millisecond)
+
+		var buffer bytes.Buffer
+		_, _ = registry.WriteTo(&buffer)
+		assert.Contains(t, buffer.String(), `govalin_websocket_connections{route="/ws"} 0`)
+	})
+}
+
+func TestMetricsOnSeparatePort(t *testing.T) {
+	listener, _ := net.Listen("tcp", "localhost:0")
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	_ = listener.Close()
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(metrics.New().Port(port).Path("/prometheus").Namespace("shop"))
+		}).Get("/", func(call *govalin.Call) {
+			call.Text("home")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, "home", govalinHTTP.Get("/"))
+
+		response, _ := http.Get(govalinHTTP.Host + "/prometheus")
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusNotFound, response.StatusCode, "Should not serve metrics on the app")
+
+		response, getErr := http.Get(fmt.Sprintf("http://localhost:%d/prometheus", port))
+		assert.NoError(t, getErr)
+		body, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+
+		assert.Contains(t, string(body), `shop_http_requests_total{method="GET",route="/",status="200"} 1`)
+	})
+}
+
+func TestRegistryExposition(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	queue := registry.Gauge("queue_length", "Length of\nthe queue.", "queue")
+	queue.Set(3, `jobs "fast"`)
+	queue.Dec(`jobs "fast"`)
+
+	latency := registry.Histogram("latency_seconds", "Latency.", []float64{1, 0.1})
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(2)
+
+	assert.Equal(t, registry.Gauge("queue_length", "", "queue"), registry.Gauge("queue_length", "", "queue"))
+	registry.Counter("queue_length", "Conflicting metric.").Inc()
+	queue.Inc("too", "many labels")
+
+	var buffer bytes.Buffer
+	_, writeErr := registry.WriteTo(&buffer)
+	assert.NoError(t, writeErr)
+
+	assert.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 2.55
+latency_seconds_count 3
+# HELP queue_length Length of\nthe queue.
+# TYPE queue_length gauge
+queue_length{queue="jobs \"fast\""} 2
+`, buffer.String())
+}
@@ -0,0 +1,371 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type metricKind string
+
+const (
+	kindCounter   metricKind = "counter"
+	kindGauge     metricKind = "gauge"
+	kindHistogram metricKind = "histogram"
+)
+
+// Registry contains metrics and writes them in the Prometheus text exposition
+// format
+//
+// Metrics are created using Counter, Gauge and Histogram, and are identified by
+// their name. Creating a metric which already exists with the same kind and
+// labels returns the existing metric. The registry is an http.Handler serving
+// its metrics.
+type Registry struct {
+	mutex   sync.RWMutex
+	metrics map[string]*metric
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: map[string]*metric{},
+	}
+}
+
+// Counter creates a counter, a value which only increases, such as the number
+// of handled requests. Counter names should end with '_total'.
+func (registry *Registry) Counter(name string, help string, labelNames ...string) *Counter {
+	return &Counter{metric: registry.register(name, help, kindCounter, nil, labelNames)}
+}
+
+// Gauge creates a gauge, a value which can increase and decrease, such as the
+// number of open connections.
+func (registry *Registry) Gauge(name string, help string, labelNames ...string) *Gauge {
+	return &Gauge{metric: registry.register(name, help, kindGauge, nil, labelNames)}
+}
+
+// Histogram creates a histogram, counting observed values such as durations in
+// buckets given by their upper bounds. DefaultBuckets is used if no buckets
+// are given.
+func (registry *Registry) Histogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sortedBuckets := append([]float64(nil), buckets...)
+	sort.Float64s(sortedBuckets)
+
+	return &Histogram{metric: registry.register(name, help, kindHistogram, sortedBuckets, labelNames)}
+}
+
+func (registry *Registry) register(
+	name string,
+	help string,
+	kind metricKind,
+	buckets []float64,
+	labelNames []string,
+) *metric {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	created := &metric{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		buckets:    buckets,
+		labelNames: labelNames,
+		series:     map[string]*series{},
+	}
+
+	existing, found := registry.metrics[name]
+	if !found {
+		registry.metrics[name] = created
+		return created
+	}
+
+	if existing.kind != kind || strings.Join(existing.labelNames, ",") != strings.Join(labelNames, ",") {
+		// The metric is still usable by the caller, but isn't exposed
+		slog.Error("Metric is already registered with another kind or labels", "name", name)
+		return created
+	}
+
+	return existing
+}
+
+// WriteTo writes the metrics of the registry in the Prometheus text exposition
+// format, sorted by name.
+func (registry *Registry) WriteTo(w io.Writer) (int64, error) {
+	registry.mutex.RLock()
+	names := make([]string, 0, len(registry.metrics))
+	for name := range registry.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]*metric, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		metrics[i] = registry.metrics[name]
+	}
+	registry.mutex.RUnlock()
+
+	writer := &countingWriter{writer: bufio.NewWriter(w)}
+	for _, metric := range metrics {
+		metric.write(writer)
+	}
+
+	if flushErr := writer.writer.Flush(); flushErr != nil {
+		return writer.written, flushErr
+	}
+
+	return writer.written, writer.err
+}
+
+// ServeHTTP serves the metrics of the registry.
+func (registry *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set(headers.ContentType, ContentType)
+	if _, writeErr := registry.WriteTo(w); writeErr != nil {
+		slog.Debug("Failed to write metrics", "err", writeErr)
+	}
+}
+
+type metric struct {
+	mutex      sync.Mutex
+	name       string
+	help       string
+	kind       metricKind
+	buckets    []float64
+	labelNames []string
+	series     map[string]*series
+}
+
+// series is the values of a metric for a set of label values.
+type series struct {
+	labelValues []string
+	value       float64  // value of counters and gauges, or the sum of histograms
+	count       uint64   // count of histogram observations
+	counts      []uint64 // count of histogram observations per bucket
+}
+
+// update runs the update function with the series of the label values.
+func (metric *metric) update(labelValues []string, updateFunc func(series *series)) {
+	if len(labelValues) != len(metric.labelNames) {
+		slog.Error(
+			"Metric updated with wrong number of label values",
+			"name", metric.name,
+			"expected", len(metric.labelNames),
+			"got", len(labelValues),
+		)
+		return
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+
+	current, found := metric.series[key]
+	if !found {
+		current = &series{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(metric.buckets)),
+		}
+		metric.series[key] = current
+	}
+
+	updateFunc(current)
+}
+
+func (metric *metric) write(writer *countingWriter) {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+
+	writer.printf("# HELP %s %s\n", metric.name, escapeHelp(metric.help))
+	writer.printf("# TYPE %s %s\n", metric.name, metric.kind)
+
+	keys := make([]string, 0, len(metric.series))
+	for key := range metric.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		current := metric.series[key]
+		labels := formatLabels(metric.labelNames, current.labelValues)
+
+		if metric.kind != kindHistogram {
+			writer.printf("%s%s %s\n", metric.name, labels, formatValue(current.value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, bucket := range metric.buckets {
+			cumulative += current.counts[i]
+			writer.printf("%s_bucket%s %d\n", metric.name, withLabel(labels, "le", formatValue(bucket)), cumulative)
+		}
+		writer.printf("%s_bucket%s %d\n", metric.name, withLabel(labels, "le", "+Inf"), current.count)
+		writer.printf("%s_sum%s %s\n", metric.name, labels, formatValue(current.value))
+		writer.printf("%s_count%s %d\n", metric.name, labels, current.count)
+	}
+}
+
+// Counter is a value which only increases, such as the number of handled
+// requests.
+type Counter struct {
+	metric *metric
+}
+
+// Inc increases the counter of the label values by one.
+func (counter *Counter) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Add increases the counter of the label values by given value. Negative
+// values are ignored, as counters only increase.
+func (counter *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+
+	counter.metric.update(labelValues, func(series *series) {
+		series.value += value
+	})
+}
+
+// Gauge is a value which can increase and decrease, such as the number of open
+// connections.
+type Gauge struct {
+	metric *metric
+}
+
+// Set sets the gauge of the label values to given value.
+func (gauge *Gauge) Set(value float64, labelValues ...string) {
+	gauge.metric.update(labelValues, func(series *series) {
+		series.value = value
+	})
+}
+
+// Add adds given value, which may be negative, to the gauge of the label values.
+func (gauge *Gauge) Add(value float64, labelValues ...string) {
+	gauge.metric.update(labelValues, func(series *series) {
+		series.value += value
+	})
+}
+
+// Inc increases the gauge of the label values by one.
+func (gauge *Gauge) Inc(labelValues ...string) {
+	gauge.Add(1, labelValues...)
+}
+
+// Dec decreases the gauge of the label values by one.
+func (gauge *Gauge) Dec(labelValues ...string) {
+	gauge.Add(-1, labelValues...)
+}
+
+// DefaultBuckets are the default histogram buckets, suited for request
+// durations in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observed values, such as durations, in buckets.
+type Histogram struct {
+	metric *metric
+}
+
+// Observe adds an observed value to the histogram of the label values.
+func (histogram *Histogram) Observe(value float64, labelValues ...string) {
+	histogram.metric.update(labelValues, func(series *series) {
+		series.value += value
+		series.count++
+		for i, bucket := range histogram.metric.buckets {
+			if value <= bucket {
+				series.counts[i]++
+				break
+			}
+		}
+	})
+}
+
+// countingWriter writes formatted lines, keeping the first error and the number
+// of written bytes.
+type countingWriter struct {
+	writer  *bufio.Writer
+	written int64
+	err     error
+}
+
+func (writer *countingWriter) printf(format string, args ...any) {
+	if writer.err != nil {
+		return
+	}
+
+	written, writeErr := fmt.Fprintf(writer.writer, format, args...)
+	writer.written += int64(written)
+	writer.err = writeErr
+}
+
+func formatLabels(labelNames []string, labelValues []string) string {
+	if len(labelNames) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i, labelName := range labelNames {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(labelName)
+		builder.WriteString(`="`)
+		builder.WriteString(escapeLabelValue(labelValues[i]))
+		builder.WriteByte('"')
+	}
+	builder.WriteByte('}')
+
+	return builder.String()
+}
+
+// withLabel adds a label to formatted labels.
+func withLabel(labels string, name string, value string) string {
+	label := name + `="` + escapeLabelValue(value) + `"`
+	if labels == "" {
+		return "{" + label + "}"
+	}
+
+	return labels[:len(labels)-1] + "," + label + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
@@ -0,0 +1,154 @@
+package openapidocs
+
+import (
+	"embed"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/openapi"
+)
+
+//go:embed swaggerui
+var swaggerUIFiles embed.FS
+
+type Config struct {
+	specPath        string
+	uiPath          string
+	uiEnabled       bool
+	info            openapi.Info
+	servers         []openapi.Server
+	securitySchemes map[string]*openapi.SecurityScheme
+	security        []openapi.SecurityRequirement
+}
+
+// New configures the server to serve an OpenAPI document generated from the
+// registered routes, as well as a Swagger UI for browsing the document.
+func New() *Config {
+	return &Config{
+		specPath:  "/openapi.json",
+		uiPath:    "/swagger",
+		uiEnabled: true,
+		info: openapi.Info{
+			Title:   "govalin API",
+			Version: "1.0.0",
+		},
+		servers:         []openapi.Server{},
+		securitySchemes: map[string]*openapi.SecurityScheme{},
+		security:        []openapi.SecurityRequirement{},
+	}
+}
+
+func (config *Config) Name() string {
+	return "OpenAPI docs plugin"
+}
+
+func (config *Config) OnInit(_ *govalin.Config) {}
+
+func (config *Config) Apply(app *govalin.App) {
+	hidden := func(route *govalin.Route) { route.Hidden() }
+
+	app.Get(config.specPath, func(call *govalin.Call) {
+		call.JSON(config.Document(app))
+	}, hidden)
+
+	if !config.uiEnabled {
+		return
+	}
+
+	uiRoot, subErr := fs.Sub(swaggerUIFiles, "swaggerui")
+	if subErr != nil {
+		slog.Error("Failed to open the bundled Swagger UI files", "err", subErr)
+		os.Exit(1)
+	}
+
+	// Relative asset paths of the UI requires a trailing slash
+	app.Get(config.uiPath, func(call *govalin.Call) {
+		call.Redirect(config.uiPath+"/", false)
+	}, hidden)
+	app.Get(config.uiPath+"/swagger-config.json", func(call *govalin.Call) {
+		call.JSON(map[string]string{"url": config.specPath})
+	}, hidden)
+	app.Static(config.uiPath, func(_ *govalin.Call, staticConfig *govalin.StaticConfig) {
+		staticConfig.WithFS(uiRoot)
+	})
+}
+
+// Document generates the OpenAPI document for given app, decorated with the
+// configured info, servers and security schemes.
+func (config *Config) Document(app *govalin.App) *openapi.Document {
+	document := app.OpenAPI()
+
+	document.Info = config.info
+	document.Servers = config.servers
+	document.Security = config.security
+	for name, scheme := range config.securitySchemes {
+		document.Components.SecuritySchemes[name] = scheme
+	}
+
+	return document
+}
+
+// SpecPath sets the path serving the OpenAPI document. Default is "/openapi.json".
+func (config *Config) SpecPath(specPath string) *Config {
+	config.specPath = specPath
+	return config
+}
+
+// UIPath sets the path serving the Swagger UI. Default is "/swagger".
+func (config *Config) UIPath(uiPath string) *Config {
+	config.uiPath = strings.TrimRight(uiPath, "/")
+	return config
+}
+
+// EnableUI sets whether the Swagger UI should be served. Default is true.
+//
+// The Swagger UI assets are embedded in the plugin, so the UI works offline and
+// under a Content-Security-Policy only allowing scripts and styles of the app.
+func (config *Config) EnableUI(enabled bool) *Config {
+	config.uiEnabled = enabled
+	return config
+}
+
+// Title sets the title of the API. Default is "govalin API".
+func (config *Config) Title(title string) *Config {
+	config.info.Title = title
+	return config
+}
+
+// Version sets the version of the API. Default is "1.0.0".
+func (config *Config) Version(version string) *Config {
+	config.info.Version = version
+	return config
+}
+
+// Description sets the description of the API.
+func (config *Config) Description(description string) *Config {
+	config.info.Description = description
+	return config
+}
+
+// Server adds a server hosting the API.
+func (config *Config) Server(url string, description string) *Config {
+	config.servers = append(config.servers, openapi.Server{URL: url, Description: description})
+	return config
+}
+
+// SecurityScheme adds a named security scheme which can be referenced by routes
+// using Route.Security.
+func (config *Config) SecurityScheme(name string, scheme *openapi.SecurityScheme) *Config {
+	config.securitySchemes[name] = scheme
+	return config
+}
+
+// Security adds a security requirement applying to all routes of the API.
+func (config *Config) Security(scheme string, scopes ...string) *Config {
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	config.security = append(config.security, openapi.SecurityRequirement{scheme: scopes})
+	return config
+}
@@ -0,0 +1,54 @@
+package openapidocs_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/openapi"
+	"github.com/pkkummermo/govalin/plugins/openapi/openapidocs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServesOpenAPIDocument(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.Plugin(openapidocs.New().
+				Title("Govalin").
+				Version("2.0.0").
+				SecurityScheme("bearer", &openapi.SecurityScheme{Type: "http", Scheme: "bearer"}))
+		}).Get("/govalin", func(call *govalin.Call) {
+			call.Text("govalin")
+		}, func(route *govalin.Route) {
+			route.Summary("Govalin").Response(200, "")
+		})
+	}, func(http govalintesting.GovalinHTTP) {
+		var document openapi.Document
+		assert.NoError(t, json.Unmarshal([]byte(http.Get("/openapi.json")), &document))
+
+		assert.Equal(t, "Govalin", document.Info.Title)
+		assert.Equal(t, "2.0.0", document.Info.Version)
+		assert.Contains(t, document.Paths, "/govalin")
+		assert.Equal(t, "Govalin", document.Paths["/govalin"].Get.Summary)
+		assert.NotContains(t, document.Paths, "/openapi.json", "Should not document itself")
+		assert.Equal(t, "bearer", document.Components.SecuritySchemes["bearer"].Scheme)
+	})
+}
+
+func TestServesSwaggerUI(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.Plugin(openapidocs.New().UIPath("/docs").SpecPath("/spec.json"))
+		})
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Contains(t, http.Get("/docs/"), `<div id="swagger-ui">`)
+		assert.NotContains(t, http.Get("/docs/"), "https://", "Should not load assets from a CDN")
+		assert.Contains(t, http.Get("/docs/swagger-ui-bundle.js"), "SwaggerUIBundle")
+		assert.Contains(t, http.Get("/docs/swagger-ui.css"), ".swagger-ui")
+		assert.Contains(t, http.Get("/docs/swagger-initializer.js"), "SwaggerUIBundle")
+		assert.JSONEq(t, `{"url":"/spec.json"}`, http.Get("/docs/swagger-config.json"))
+		assert.Contains(t, http.Get("/docs"), `<div id="swagger-ui">`, "Should redirect to trailing slash")
+		assert.NotContains(t, http.Get("/spec.json"), "/docs", "Should not document the UI")
+	})
+}
@@ -0,0 +1,10 @@
+window.onload = function () {
+  // The configuration is served next to this page by the govalin OpenAPI docs plugin
+  var base = window.location.pathname.replace(/(index\.html)?$/, "").replace(/\/?$/, "/");
+
+  window.ui = SwaggerUIBundle({
+    configUrl: base + "swagger-config.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+  });
+};
@@ -0,0 +1,566 @@
+package openapivalidator
+
+import (
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/contenttypes"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/internal/http/writers"
+	"github.com/pkkummermo/govalin/internal/validation"
+	"github.com/pkkummermo/govalin/openapi"
+)
+
+const (
+	parameterRefPrefix   = "#/components/parameters/"
+	requestBodyRefPrefix = "#/components/requestBodies/"
+	responseRefPrefix    = "#/components/responses/"
+)
+
+var pathParamRegexp = regexp.MustCompile(`\{([^/{}]+)\}`)
+
+// ResponseMismatchFunc is called when a response doesn't match the OpenAPI document.
+type ResponseMismatchFunc func(call *govalin.Call, mismatches []openapi.SchemaError)
+
+type route struct {
+	path       string
+	pathItem   *openapi.PathItem
+	matcher    *regexp.Regexp
+	paramNames []string
+}
+
+type Config struct {
+	specPath           string
+	document           *openapi.Document
+	basePath           string
+	basePathSet        bool
+	ignoredPaths       []string
+	validateResponses  bool
+	onResponseMismatch ResponseMismatchFunc
+	routes             []route
+}
+
+// New configures the server to validate requests against the OpenAPI 3 document
+// found at given JSON or YAML file path. The document is loaded on init.
+func New(specPath string) *Config {
+	return &Config{
+		specPath:           specPath,
+		ignoredPaths:       []string{},
+		validateResponses:  false,
+		onResponseMismatch: logResponseMismatch,
+		routes:             []route{},
+	}
+}
+
+// NewFromDocument configures the server to validate requests against given
+// OpenAPI document.
+func NewFromDocument(document *openapi.Document) *Config {
+	config := New("")
+	config.document = document
+
+	return config
+}
+
+func (config *Config) Name() string {
+	return "OpenAPI validator plugin"
+}
+
+func (config *Config) OnInit(_ *govalin.Config) {
+	if config.document == nil {
+		document, loadErr := openapi.Load(config.specPath)
+		if loadErr != nil {
+			slog.Error("Failed to load the OpenAPI document for request validation", "err", loadErr)
+			os.Exit(1)
+		}
+		config.document = document
+	}
+
+	if !config.basePathSet {
+		config.basePath = basePathFromServers(config.document.Servers)
+	}
+
+	config.compileRoutes()
+}
+
+func (config *Config) Apply(app *govalin.App) {
+	app.Before("*", config.validateRequest)
+
+	if config.validateResponses {
+		app.After("*", config.validateResponse)
+	}
+}
+
+// BasePath sets the path prefix of all paths in the document. Defaults to the
+// path of the first server URL in the document.
+func (config *Config) BasePath(basePath string) *Config {
+	config.basePath = strings.TrimRight(basePath, "/")
+	config.basePathSet = true
+	return config
+}
+
+// IgnorePaths sets path prefixes which are not validated, such as health checks
+// or static files which are not part of the API.
+func (config *Config) IgnorePaths(pathPrefixes ...string) *Config {
+	config.ignoredPaths = append(config.ignoredPaths, pathPrefixes...)
+	return config
+}
+
+// ValidateResponses enables validation of responses against the document. This
+// buffers response bodies and is intended for tests. Mismatches are logged as
+// warnings unless another handler is given using OnResponseMismatch.
+func (config *Config) ValidateResponses(enabled bool) *Config {
+	config.validateResponses = enabled
+	return config
+}
+
+// OnResponseMismatch sets the function called when a response doesn't match
+// the document, for example to fail a test.
+func (config *Config) OnResponseMismatch(mismatchFunc ResponseMismatchFunc) *Config {
+	config.onResponseMismatch = mismatchFunc
+	return config
+}
+
+func (config *Config) compileRoutes() {
+	for path, pathItem := range config.document.Paths {
+		matcherParts := []string{}
+		paramNames := []string{}
+		lastIndex := 0
+
+		for _, match := range pathParamRegexp.FindAllStringSubmatchIndex(path, -1) {
+			matcherParts = append(matcherParts, regexp.QuoteMeta(path[lastIndex:match[0]]), "([^/]+)")
+			paramNames = append(paramNames, path[match[2]:match[3]])
+			lastIndex = match[1]
+		}
+		matcherParts = append(matcherParts, regexp.QuoteMeta(path[lastIndex:]))
+
+		config.routes = append(config.routes, route{
+			path:       path,
+			pathItem:   pathItem,
+			matcher:    regexp.MustCompile("^" + strings.Join(matcherParts, "") + "$"),
+			paramNames: paramNames,
+		})
+	}
+
+	// Prefer concrete paths over templated paths, ie. /users/me over /users/{id}
+	sort.SliceStable(config.routes, func(i, j int) bool {
+		if len(config.routes[i].paramNames) != len(config.routes[j].paramNames) {
+			return len(config.routes[i].paramNames) < len(config.routes[j].paramNames)
+		}
+		return config.routes[i].path < config.routes[j].path
+	})
+}
+
+// findRoute returns the route matching given request path and the path params.
+func (config *Config) findRoute(requestPath string) (*route, map[string]string) {
+	for i := range config.routes {
+		matches := config.routes[i].matcher.FindStringSubmatch(requestPath)
+		if matches == nil {
+			continue
+		}
+
+		pathParams := map[string]string{}
+		for j, name := range config.routes[i].paramNames {
+			value, unescapeErr := url.PathUnescape(matches[j+1])
+			if unescapeErr != nil {
+				value = matches[j+1]
+			}
+			pathParams[name] = value
+		}
+
+		return &config.routes[i], pathParams
+	}
+
+	return nil, nil
+}
+
+func (config *Config) isIgnored(requestPath string) bool {
+	for _, ignoredPath := range config.ignoredPaths {
+		if strings.HasPrefix(requestPath, ignoredPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findOperation finds the documented route and operation of the call. If none is
+// found, the corresponding error response is written and false is returned.
+func (config *Config) findOperation(call *govalin.Call) (*openapi.Operation, *route, map[string]string, bool) {
+	requestPath := call.URL().Path
+	if !strings.HasPrefix(requestPath, config.basePath) {
+		writeError(call, http.StatusNotFound, validation.NewParameterErrorDetail(
+			"path",
+			fmt.Sprintf("The path '%s' doesn't exist", requestPath),
+		))
+		return nil, nil, nil, false
+	}
+
+	matchedRoute, pathParams := config.findRoute(strings.TrimPrefix(requestPath, config.basePath))
+	if matchedRoute == nil {
+		writeError(call, http.StatusNotFound, validation.NewParameterErrorDetail(
+			"path",
+			fmt.Sprintf("The path '%s' doesn't exist", requestPath),
+		))
+		return nil, nil, nil, false
+	}
+
+	operation := matchedRoute.pathItem.Operation(call.Method())
+	if operation == nil && call.Method() == http.MethodHead {
+		operation = matchedRoute.pathItem.Get
+	}
+
+	if operation == nil {
+		allowedMethods := allowedMethods(matchedRoute.pathItem)
+		call.Header(headers.Allow, strings.Join(allowedMethods, ", "))
+		writeError(call, http.StatusMethodNotAllowed, validation.NewParameterErrorDetail(
+			"method",
+			fmt.Sprintf("The method '%s' is not allowed. Must be one of '%s'",
+				call.Method(),
+				strings.Join(allowedMethods, "', '"),
+			),
+		))
+		return nil, nil, nil, false
+	}
+
+	return operation, matchedRoute, pathParams, true
+}
+
+func (config *Config) validateRequest(call *govalin.Call) bool {
+	if config.isIgnored(call.URL().Path) {
+		return true
+	}
+
+	// CORS preflight requests are answered by the CORS plugin, not the API
+	if call.Method() == http.MethodOptions && call.Header(headers.AccessControlRequestMethod) != "" {
+		return true
+	}
+
+	operation, matchedRoute, pathParams, found := config.findOperation(call)
+	if !found {
+		return false
+	}
+
+	details := config.validateParams(call, matchedRoute.pathItem, operation, pathParams)
+
+	bodyDetails, bodyErr := config.validateBody(call, operation)
+	if bodyErr != nil {
+		call.Error(bodyErr)
+		return false
+	}
+	details = append(details, bodyDetails...)
+
+	if len(details) > 0 {
+		writeError(call, http.StatusBadRequest, details...)
+		return false
+	}
+
+	if config.validateResponses {
+		call.WrapResponseWriter(func(w http.ResponseWriter) http.ResponseWriter {
+			return newResponseRecorder(w)
+		})
+	}
+
+	return true
+}
+
+func (config *Config) validateParams(
+	call *govalin.Call,
+	pathItem *openapi.PathItem,
+	operation *openapi.Operation,
+	pathParams map[string]string,
+) []validation.ErrorDetail {
+	details := []validation.ErrorDetail{}
+
+	for _, param := range config.mergedParams(pathItem, operation) {
+		values := paramValues(call, param, pathParams)
+		if len(values) == 0 {
+			if param.Required {
+				details = append(details, validation.NewParameterErrorDetail(param.Name, "This parameter is required"))
+			}
+			continue
+		}
+
+		value := config.document.CoerceParam(param.Schema, values)
+		details = append(details, toErrorDetails(config.document.ValidateValue(param.Schema, value, param.Name), param.Name)...)
+	}
+
+	return details
+}
+
+// mergedParams returns the parameters of the path item overridden by the
+// parameters of the operation, with references resolved.
+func (config *Config) mergedParams(pathItem *openapi.PathItem, operation *openapi.Operation) []*openapi.Parameter {
+	params := []*openapi.Parameter{}
+	indexByKey := map[string]int{}
+
+	for _, param := range append(append([]*openapi.Parameter{}, pathItem.Parameters...), operation.Parameters...) {
+		if param.Ref != "" && config.document.Components != nil {
+			param = config.document.Components.Parameters[strings.TrimPrefix(param.Ref, parameterRefPrefix)]
+		}
+		if param == nil {
+			continue
+		}
+
+		key := param.In + ":" + param.Name
+		if index, exists := indexByKey[key]; exists {
+			params[index] = param
+			continue
+		}
+		indexByKey[key] = len(params)
+		params = append(params, param)
+	}
+
+	return params
+}
+
+func paramValues(call *govalin.Call, param *openapi.Parameter, pathParams map[string]string) []string {
+	switch param.In {
+	case openapi.InPath:
+		if value, exists := pathParams[param.Name]; exists {
+			return []string{value}
+		}
+	case openapi.InQuery:
+		return call.URL().Query()[param.Name]
+	case openapi.InHeader:
+		return call.Raw.Req.Header.Values(param.Name)
+	case openapi.InCookie:
+		if cookie, cookieErr := call.Cookie(param.Name); cookieErr == nil {
+			return []string{cookie.Value}
+		}
+	}
+
+	return nil
+}
+
+// validateBody validates the request body. Returns an error if the body can't be
+// validated at all, such as for unsupported content types or invalid JSON.
+func (config *Config) validateBody(call *govalin.Call, operation *openapi.Operation) ([]validation.ErrorDetail, error) {
+	requestBody := operation.RequestBody
+	if requestBody != nil && requestBody.Ref != "" && config.document.Components != nil {
+		requestBody = config.document.Components.RequestBodies[strings.TrimPrefix(requestBody.Ref, requestBodyRefPrefix)]
+	}
+	if requestBody == nil {
+		return nil, nil
+	}
+
+	hasBody := call.Raw.Req.ContentLength > 0 || len(call.Raw.Req.TransferEncoding) > 0
+	if !hasBody {
+		if requestBody.Required {
+			return []validation.ErrorDetail{validation.NewParameterErrorDetail("body", "Request body is required")}, nil
+		}
+		return nil, nil
+	}
+
+	contentType := call.Header(headers.ContentType)
+	mediaType, mediaTypeName := findMediaType(requestBody.Content, contentType)
+	if mediaType == nil {
+		supported := make([]string, 0, len(requestBody.Content))
+		for name := range requestBody.Content {
+			supported = append(supported, name)
+		}
+		sort.Strings(supported)
+
+		return nil, validation.NewError(validation.NewErrorResponse(
+			http.StatusUnsupportedMediaType,
+			validation.NewParameterErrorDetail(
+				headers.ContentType,
+				fmt.Sprintf("Unsupported content type '%s'. Must be one of '%s'",
+					contentType,
+					strings.Join(supported, "', '"),
+				),
+			),
+		))
+	}
+
+	if mediaType.Schema == nil || !isJSON(mediaTypeName, contentType) {
+		return nil, nil
+	}
+
+	var body any
+	if bodyErr := call.BodyAs(&body); bodyErr != nil {
+		return nil, bodyErr
+	}
+
+	return toErrorDetails(config.document.ValidateValue(mediaType.Schema, body, ""), "body"), nil
+}
+
+func (config *Config) validateResponse(call *govalin.Call) {
+	recorder, found := writers.Find[*responseRecorder](*call.Raw.W)
+	if !found || recorder.truncated {
+		return
+	}
+
+	operation, _, _, found := config.findOperationQuietly(call)
+	if !found {
+		return
+	}
+
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	response := findResponse(operation.Responses, status)
+	if response != nil && response.Ref != "" && config.document.Components != nil {
+		response = config.document.Components.Responses[strings.TrimPrefix(response.Ref, responseRefPrefix)]
+	}
+	if response == nil {
+		config.onResponseMismatch(call, []openapi.SchemaError{{
+			Field:  "status",
+			Reason: fmt.Sprintf("Undocumented response status %d", status),
+		}})
+		return
+	}
+
+	if len(response.Content) == 0 {
+		return
+	}
+
+	contentType := recorder.Header().Get(headers.ContentType)
+	mediaType, mediaTypeName := findMediaType(response.Content, contentType)
+	if mediaType == nil {
+		config.onResponseMismatch(call, []openapi.SchemaError{{
+			Field:  headers.ContentType,
+			Reason: fmt.Sprintf("Undocumented response content type '%s'", contentType),
+		}})
+		return
+	}
+
+	if mediaType.Schema == nil || !isJSON(mediaTypeName, contentType) {
+		return
+	}
+
+	body, decodeErr := recorder.decodeJSON()
+	if decodeErr != nil {
+		config.onResponseMismatch(call, []openapi.SchemaError{{Field: "body", Reason: "Response body is not valid JSON"}})
+		return
+	}
+
+	if mismatches := config.document.ValidateValue(mediaType.Schema, body, ""); len(mismatches) > 0 {
+		config.onResponseMismatch(call, mismatches)
+	}
+}
+
+// findOperationQuietly finds the operation of the call without writing errors.
+func (config *Config) findOperationQuietly(call *govalin.Call) (*openapi.Operation, *route, map[string]string, bool) {
+	matchedRoute, pathParams := config.findRoute(strings.TrimPrefix(call.URL().Path, config.basePath))
+	if matchedRoute == nil {
+		return nil, nil, nil, false
+	}
+
+	operation := matchedRoute.pathItem.Operation(call.Method())
+	if operation == nil && call.Method() == http.MethodHead {
+		operation = matchedRoute.pathItem.Get
+	}
+
+	return operation, matchedRoute, pathParams, operation != nil
+}
+
+func findResponse(responses map[string]*openapi.Response, status int) *openapi.Response {
+	if response, exists := responses[fmt.Sprint(status)]; exists {
+		return response
+	}
+	if response, exists := responses[fmt.Sprintf("%dXX", status/100)]; exists {
+		return response
+	}
+
+	return responses["default"]
+}
+
+// findMediaType finds the media type matching given content type, falling back
+// to wildcard media types such as "application/*" and "*/*".
+func findMediaType(content map[string]*openapi.MediaType, contentType string) (*openapi.MediaType, string) {
+	mediaTypeName, _, parseErr := mime.ParseMediaType(contentType)
+	if parseErr != nil {
+		mediaTypeName = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	}
+
+	mainType, _, _ := strings.Cut(mediaTypeName, "/")
+	for _, candidate := range []string{mediaTypeName, mainType + "/*", "*/*"} {
+		if mediaType, exists := content[candidate]; exists {
+			return mediaType, candidate
+		}
+	}
+
+	return nil, ""
+}
+
+func isJSON(mediaTypeName string, contentType string) bool {
+	for _, name := range []string{mediaTypeName, contentType} {
+		name, _, _ = strings.Cut(name, ";")
+		if name == contenttypes.ApplicationJSON || strings.HasSuffix(name, "+json") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allowedMethods(pathItem *openapi.PathItem) []string {
+	allowed := []string{}
+	for _, method := range []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodOptions,
+		http.MethodHead,
+	} {
+		if pathItem.Operation(method) != nil {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return allowed
+}
+
+func toErrorDetails(schemaErrors []openapi.SchemaError, rootField string) []validation.ErrorDetail {
+	details := make([]validation.ErrorDetail, 0, len(schemaErrors))
+	for _, schemaError := range schemaErrors {
+		field := schemaError.Field
+		if field == "" {
+			field = rootField
+		}
+		details = append(details, validation.NewParameterErrorDetail(field, schemaError.Reason))
+	}
+
+	return details
+}
+
+func writeError(call *govalin.Call, status int, details ...validation.ErrorDetail) {
+	call.Status(status)
+	call.JSON(validation.NewError(validation.NewErrorResponse(status, details...)).ErrorResponse)
+}
+
+func logResponseMismatch(call *govalin.Call, mismatches []openapi.SchemaError) {
+	slog.Warn(
+		"response doesn't match OpenAPI document",
+		slog.String("id", call.ID()),
+		slog.String("method", call.Method()),
+		slog.String("path", call.URL().Path),
+		slog.Int("status", call.Status()),
+		slog.Any("mismatches", mismatches),
+	)
+}
+
+func basePathFromServers(servers []openapi.Server) string {
+	if len(servers) == 0 {
+		return ""
+	}
+
+	serverURL, parseErr := url.Parse(servers[0].URL)
+	if parseErr != nil {
+		return ""
+	}
+
+	return strings.TrimRight(serverURL.Path, "/")
+}
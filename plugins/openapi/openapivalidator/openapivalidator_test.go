@@ -0,0 +1,160 @@
+package openapivalidator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+	"github.com/pkkummermo/govalin/openapi"
+	"github.com/pkkummermo/govalin/plugins/cors"
+	"github.com/pkkummermo/govalin/plugins/openapi/openapivalidator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPetstore(validator *openapivalidator.Config) *govalin.App {
+	return govalin.New(func(config *govalin.Config) {
+		config.Plugin(cors.New().AllowAllOrigins())
+		config.Plugin(validator)
+	}).
+		Get("/api/pets", func(call *govalin.Call) {
+			call.JSON([]map[string]string{{"name": "Fido", "tag": "dog"}})
+		}).
+		Post("/api/pets", func(call *govalin.Call) {
+			call.Status(201)
+			call.Text("created")
+		}).
+		Get("/api/pets/{id}", func(call *govalin.Call) {
+			call.JSON(map[string]string{"name": "F"})
+		}).
+		Get("/api/undocumented", func(call *govalin.Call) {
+			call.Text("undocumented")
+		}).
+		Get("/health", func(call *govalin.Call) {
+			call.Text("ok")
+		})
+}
+
+func TestValidRequests(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return newPetstore(openapivalidator.New("testdata/petstore.yaml").IgnorePaths("/health"))
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/api/pets?limit=10")
+		assert.Equal(t, 200, response.StatusCode)
+
+		response, _ = http.Raw().Begin().PostJson(http.Host+"/api/pets", map[string]string{"name": "Fido", "tag": "dog"})
+		assert.Equal(t, 201, response.StatusCode)
+
+		assert.Equal(t, "ok", http.Get("/health"))
+	})
+}
+
+func TestInvalidParams(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return newPetstore(openapivalidator.New("testdata/petstore.yaml"))
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/api/pets?limit=abc")
+		body, _ := response.ToString()
+		assert.Equal(t, 400, response.StatusCode)
+		assert.Contains(t, body, `"field":"limit"`)
+		assert.Contains(t, body, "Must be of type 'integer'")
+
+		response = http.GetResponse("/api/pets?limit=1000")
+		body, _ = response.ToString()
+		assert.Equal(t, 400, response.StatusCode)
+		assert.Contains(t, body, "Must be at most 100")
+
+		response = http.GetResponse("/api/pets/abc")
+		body, _ = response.ToString()
+		assert.Equal(t, 400, response.StatusCode)
+		assert.Contains(t, body, `"field":"id"`)
+	})
+}
+
+func TestInvalidBody(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return newPetstore(openapivalidator.New("testdata/petstore.yaml"))
+	}, func(http govalintesting.GovalinHTTP) {
+		response, _ := http.Raw().Begin().PostJson(http.Host+"/api/pets", map[string]string{"tag": "bird", "age": "1"})
+		body, _ := response.ToString()
+		assert.Equal(t, 400, response.StatusCode)
+		assert.Contains(t, body, `{"field":"name","reason":"This field is required"}`)
+		assert.Contains(t, body, `{"field":"age","reason":"Unknown field"}`)
+		assert.Contains(t, body, `"field":"tag"`)
+
+		response, _ = http.Raw().Do(
+			"POST",
+			http.Host+"/api/pets",
+			map[string]string{headers.ContentType: "application/json"},
+			strings.NewReader("{invalid"),
+		)
+		assert.Equal(t, 400, response.StatusCode)
+
+		response, _ = http.Raw().Do(
+			"POST",
+			http.Host+"/api/pets",
+			map[string]string{headers.ContentType: "text/plain"},
+			strings.NewReader("Fido"),
+		)
+		body, _ = response.ToString()
+		assert.Equal(t, 415, response.StatusCode)
+		assert.Contains(t, body, "Must be one of 'application/json'")
+	})
+}
+
+func TestUndocumentedRoutes(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return newPetstore(openapivalidator.New("testdata/petstore.yaml"))
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/api/undocumented")
+		assert.Equal(t, 404, response.StatusCode)
+
+		response = http.DeleteResponse("/api/pets")
+		assert.Equal(t, 405, response.StatusCode)
+		assert.Equal(t, "GET, POST", response.Header.Get(headers.Allow))
+
+		response, _ = http.Raw().Begin().
+			WithHeader(headers.Origin, "http://govalin.io").
+			WithHeader(headers.AccessControlRequestMethod, "POST").
+			Options(http.Host + "/api/pets")
+		assert.Equal(t, 200, response.StatusCode, "Should let CORS preflight requests through")
+	})
+}
+
+func TestResponseValidation(t *testing.T) {
+	var mismatches []openapi.SchemaError
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return newPetstore(openapivalidator.New("testdata/petstore.yaml").
+			ValidateResponses(true).
+			OnResponseMismatch(func(_ *govalin.Call, responseMismatches []openapi.SchemaError) {
+				mismatches = append(mismatches, responseMismatches...)
+			}))
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/api/pets")
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Empty(t, mismatches)
+
+		response = http.GetResponse("/api/pets/1")
+		assert.Equal(t, 200, response.StatusCode, "Should not change the response")
+		assert.Equal(t, []openapi.SchemaError{{Field: "name", Reason: "Must be at least 2 characters long"}}, mismatches)
+	})
+}
+
+func TestDocumentFromApp(t *testing.T) {
+	app := govalin.New().Get("/users/{id}", func(call *govalin.Call) {
+		call.Text("user")
+	})
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.Plugin(openapivalidator.NewFromDocument(app.OpenAPI()))
+		}).Get("/users/{id}", func(call *govalin.Call) {
+			call.Text("user")
+		})
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Equal(t, "user", http.Get("/users/1"))
+		assert.Equal(t, 404, http.GetResponse("/users").StatusCode)
+	})
+}
@@ -0,0 +1,55 @@
+package openapivalidator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkkummermo/govalin/internal/http/writers"
+)
+
+// maxRecordedBodySize is the max size of response bodies recorded for validation.
+const maxRecordedBodySize = 1 << 20
+
+// responseRecorder records the status and body of a response while writing it
+// to the wrapped response writer.
+type responseRecorder struct {
+	writers.Wrapper
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{
+		Wrapper: writers.Wrapper{ResponseWriter: w},
+	}
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+
+	if recorder.body.Len()+len(data) > maxRecordedBodySize {
+		recorder.truncated = true
+	} else {
+		recorder.body.Write(data)
+	}
+
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *responseRecorder) decodeJSON() (any, error) {
+	var body any
+	err := json.Unmarshal(recorder.body.Bytes(), &body)
+
+	return body, err
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Algorithm is the algorithm limiting calls.
+type Algorithm int
+
+const (
+	// TokenBucket lets clients burst up to the number of requests of the limit,
+	// refilling the bucket evenly over the window.
+	TokenBucket Algorithm = iota
+	// SlidingWindow allows the number of requests of the limit in any window,
+	// estimating the count of the sliding window from the counts of the current
+	// and previous fixed windows.
+	SlidingWindow
+)
+
+// Limit is the number of requests allowed per window, such as 100 requests
+// per minute.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// PerSecond allows the number of requests per second.
+func PerSecond(requests int) Limit {
+	return Limit{Requests: requests, Window: time.Second}
+}
+
+// PerMinute allows the number of requests per minute.
+func PerMinute(requests int) Limit {
+	return Limit{Requests: requests, Window: time.Minute}
+}
+
+// PerHour allows the number of requests per hour.
+func PerHour(requests int) Limit {
+	return Limit{Requests: requests, Window: time.Hour}
+}
+
+// result is the outcome of taking a request from a limit.
+type result struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration // reset is when the limit is fully available again
+	retryAfter time.Duration // retryAfter is when a denied request would be allowed
+}
+
+// ttl returns how long the state of a key is needed after its last update.
+func (algorithm Algorithm) ttl(limit Limit) time.Duration {
+	if algorithm == SlidingWindow {
+		return 2 * limit.Window
+	}
+
+	return limit.Window
+}
+
+// take returns the new state and the result of a request at now.
+func (algorithm Algorithm) take(limit Limit, state State, now time.Time) (State, result) {
+	if algorithm == SlidingWindow {
+		return takeSlidingWindow(limit, state, now)
+	}
+
+	return takeTokenBucket(limit, state, now)
+}
+
+func takeTokenBucket(limit Limit, state State, now time.Time) (State, result) {
+	capacity := float64(limit.Requests)
+	perNanosecond := capacity / float64(limit.Window)
+
+	tokens := capacity
+	if state.Timestamp != 0 {
+		elapsed := max(now.UnixNano()-state.Timestamp, 0)
+		tokens = min(capacity, state.Value+float64(elapsed)*perNanosecond)
+	}
+
+	taken := result{}
+	if tokens >= 1 {
+		tokens--
+		taken.allowed = true
+	} else {
+		taken.retryAfter = time.Duration(math.Ceil((1 - tokens) / perNanosecond))
+	}
+	taken.remaining = int(math.Floor(tokens))
+	taken.reset = time.Duration(math.Ceil((capacity - tokens) / perNanosecond))
+
+	return State{Value: tokens, Timestamp: now.UnixNano()}, taken
+}
+
+func takeSlidingWindow(limit Limit, state State, now time.Time) (State, result) {
+	window := int64(limit.Window)
+	windowStart := now.UnixNano() - now.UnixNano()%window
+
+	current, previous := state.Value, state.Previous
+	if state.Timestamp != windowStart {
+		previous = 0
+		if state.Timestamp == windowStart-window {
+			previous = current
+		}
+		current = 0
+	}
+
+	elapsed := float64(now.UnixNano()-windowStart) / float64(window)
+	requests := float64(limit.Requests)
+	estimated := previous*(1-elapsed) + current
+
+	taken := result{}
+	if estimated+1 <= requests {
+		current++
+		estimated++
+		taken.allowed = true
+	} else {
+		taken.retryAfter = slidingRetryAfter(limit, current, previous, elapsed)
+	}
+	taken.remaining = max(int(math.Floor(requests-estimated)), 0)
+	// The requests of the current window stop counting at the end of the next
+	taken.reset = time.Duration((2 - elapsed) * float64(window))
+	if current == 0 {
+		taken.reset = time.Duration((1 - elapsed) * float64(window))
+	}
+
+	return State{Value: current, Previous: previous, Timestamp: windowStart}, taken
+}
+
+// slidingRetryAfter returns when the estimated count of the sliding window has
+// decreased enough for another request, given the elapsed fraction of the
+// current window.
+func slidingRetryAfter(limit Limit, current float64, previous float64, elapsed float64) time.Duration {
+	window := float64(limit.Window)
+	allowed := float64(limit.Requests) - 1
+
+	if current <= allowed && previous > 0 {
+		// Wait for the previous window to decay within the current window
+		decayedAt := 1 - (allowed-current)/previous
+		return time.Duration(math.Ceil((decayedAt - elapsed) * window))
+	}
+
+	// Wait for the current window to decay within the next window
+	decayedAt := 1.0
+	if current > 0 {
+		decayedAt = max(1-allowed/current, 0)
+	}
+	return time.Duration(math.Ceil((1 - elapsed + decayedAt) * window))
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	limit := Limit{Requests: 2, Window: 10 * time.Second}
+	now := time.Unix(1000, 0)
+
+	state, taken := TokenBucket.take(limit, State{}, now)
+	assert.True(t, taken.allowed)
+	assert.Equal(t, 1, taken.remaining)
+	assert.Equal(t, 5*time.Second, taken.reset)
+
+	state, taken = TokenBucket.take(limit, state, now)
+	assert.True(t, taken.allowed, "Should allow bursts up to the limit")
+	assert.Equal(t, 0, taken.remaining)
+
+	state, taken = TokenBucket.take(limit, state, now.Add(time.Second))
+	assert.False(t, taken.allowed)
+	assert.Equal(t, 4*time.Second, taken.retryAfter, "Should retry once a token is refilled")
+
+	_, taken = TokenBucket.take(limit, state, now.Add(5*time.Second))
+	assert.True(t, taken.allowed)
+	assert.Equal(t, 0, taken.remaining)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	limit := Limit{Requests: 4, Window: 10 * time.Second}
+	windowStart := time.Unix(1000, 0)
+
+	state := State{}
+	var taken result
+	for range 4 {
+		state, taken = SlidingWindow.take(limit, state, windowStart.Add(time.Second))
+		assert.True(t, taken.allowed)
+	}
+	assert.Equal(t, 0, taken.remaining)
+
+	state, taken = SlidingWindow.take(limit, state, windowStart.Add(2*time.Second))
+	assert.False(t, taken.allowed)
+	assert.Equal(t, 10500*time.Millisecond, taken.retryAfter,
+		"Should retry once a quarter of the next window has passed",
+	)
+
+	// Half into the next window, half of the previous window still counts
+	state, taken = SlidingWindow.take(limit, state, windowStart.Add(15*time.Second))
+	assert.True(t, taken.allowed)
+	assert.Equal(t, 1, taken.remaining)
+	state, taken = SlidingWindow.take(limit, state, windowStart.Add(15*time.Second))
+	assert.True(t, taken.allowed)
+	_, taken = SlidingWindow.take(limit, state, windowStart.Add(15*time.Second))
+	assert.False(t, taken.allowed)
+	assert.Equal(t, 2500*time.Millisecond, taken.retryAfter)
+
+	_, taken = SlidingWindow.take(limit, state, windowStart.Add(time.Minute))
+	assert.True(t, taken.allowed, "Should forget windows older than the previous window")
+	assert.Equal(t, 3, taken.remaining)
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"database/sql"
+)
+
+const (
+	CreateGovalinRateLimitTable = `
+	CREATE TABLE IF NOT EXISTS govalin_rate_limit (
+		key            VARCHAR(512)  NOT NULL,
+		value          REAL          NOT NULL,
+		previous       REAL          NOT NULL,
+		timestamp      BIGINT        NOT NULL,
+		expires        BIGINT        NOT NULL,
+		CONSTRAINT govalin_rate_limit_pk PRIMARY KEY (key)
+	)`
+	CreateGovalinRateLimitTableIndex = `CREATE INDEX IF NOT EXISTS rate_limit_expires ON govalin_rate_limit(expires)`
+)
+
+type Statements struct {
+	RetrieveState *sql.Stmt
+	SetState      *sql.Stmt
+	PruneStates   *sql.Stmt
+}
+
+func InitStatements(db *sql.DB) (Statements, error) {
+	var err error
+	var sqlStatements Statements
+
+	if sqlStatements.RetrieveState, err = db.Prepare(`
+		SELECT value, previous, timestamp
+			FROM govalin_rate_limit
+			WHERE key = $1 AND expires > $2`); err != nil {
+		return sqlStatements, err
+	}
+	if sqlStatements.SetState, err = db.Prepare(`
+		INSERT INTO govalin_rate_limit (key, value, previous, timestamp, expires)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (key) DO UPDATE
+			SET value = excluded.value,
+				previous = excluded.previous,
+				timestamp = excluded.timestamp,
+				expires = excluded.expires`); err != nil {
+		return sqlStatements, err
+	}
+	if sqlStatements.PruneStates, err = db.Prepare(`
+		DELETE FROM govalin_rate_limit
+			WHERE expires <= $1`); err != nil {
+		return sqlStatements, err
+	}
+
+	return sqlStatements, nil
+}
@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const defaultKeyPrefix = "govalin:ratelimit:"
+
+// KeyFunc returns the key calls are limited by, such as the IP of the client.
+// Calls are limited by the IP of the client if the key is empty.
+type KeyFunc func(call *govalin.Call) string
+
+// KeyByClientIP limits calls by the IP of the client, see govalin.Call.ClientIP.
+func KeyByClientIP(call *govalin.Call) string {
+	return "ip:" + call.ClientIP()
+}
+
+// KeyBySession limits calls by the session of the client, which requires
+// sessions to be enabled. A new session is created for every call without a
+// valid session cookie, so such calls are limited by KeyByClientIP instead,
+// stopping clients from evading the limits by dropping their cookies.
+func KeyBySession(call *govalin.Call) string {
+	sessionID := govalin.SessionIDFromContext(call.Context())
+	if sessionID == "" || !call.SessionResumed() {
+		return KeyByClientIP(call)
+	}
+
+	return "session:" + sessionID
+}
+
+// KeyByHeader limits calls by the value of the header, such as an API key.
+func KeyByHeader(header string) KeyFunc {
+	return func(call *govalin.Call) string {
+		if value := call.Header(header); value != "" {
+			return "header:" + value
+		}
+
+		return ""
+	}
+}
+
+type pathLimit struct {
+	path  string
+	limit Limit
+}
+
+// Config contains the configuration of the rate limiting plugin
+//
+// The plugin limits the calls of each client to the paths of the configured
+// limits, responding with 429 Too Many Requests once a limit is exceeded. Calls
+// matching several limits count against each of them. Responses include the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers of the most restrictive limit, and denied calls include Retry-After.
+//
+// Calls are allowed if the store fails, so an unavailable store doesn't take
+// the app down with it.
+type Config struct {
+	algorithm Algorithm
+	keyFunc   KeyFunc
+	store     Store
+	keyPrefix string
+	limits    []pathLimit
+}
+
+// New creates a rate limiting plugin limiting calls by the IP of the client
+// using token buckets kept in memory.
+func New() *Config {
+	return &Config{
+		algorithm: TokenBucket,
+		keyFunc:   KeyByClientIP,
+		store:     NewInMemoryStore(),
+		keyPrefix: defaultKeyPrefix,
+		limits:    []pathLimit{},
+	}
+}
+
+func (config *Config) Name() string {
+	return "Rate limiting plugin"
+}
+
+func (config *Config) OnInit(_ *govalin.Config) {}
+
+func (config *Config) Apply(app *govalin.App) {
+	for _, current := range config.limits {
+		app.Before(current.path, func(call *govalin.Call) bool {
+			return config.take(call, current)
+		})
+	}
+}
+
+// Limit limits calls to paths matching the path, such as '*' for all paths or
+// '/api/*' for a route group.
+func (config *Config) Limit(path string, limit Limit) *Config {
+	if limit.Requests <= 0 || limit.Window <= 0 {
+		slog.Error("Ignoring rate limit without requests or window", "path", path)
+		return config
+	}
+
+	config.limits = append(config.limits, pathLimit{path: path, limit: limit})
+	return config
+}
+
+// Algorithm sets the algorithm limiting calls. Defaults to TokenBucket.
+func (config *Config) Algorithm(algorithm Algorithm) *Config {
+	config.algorithm = algorithm
+	return config
+}
+
+// Key sets the function returning the key calls are limited by, such as
+// KeyByClientIP, KeyBySession or KeyByHeader. Defaults to KeyByClientIP.
+func (config *Config) Key(keyFunc KeyFunc) *Config {
+	config.keyFunc = keyFunc
+	return config
+}
+
+// Store sets the store of the state of limits, such as a SQLite store shared by
+// multiple processes. Defaults to an in-memory store.
+func (config *Config) Store(store Store) *Config {
+	config.store = store
+	return config
+}
+
+// KeyPrefix sets the prefix of stored keys, letting apps share a store without
+// sharing limits. Defaults to 'govalin:ratelimit:'.
+func (config *Config) KeyPrefix(keyPrefix string) *Config {
+	config.keyPrefix = keyPrefix
+	return config
+}
+
+// take takes a request from the limit for the client of the call, returning
+// whether the call may continue.
+func (config *Config) take(call *govalin.Call, current pathLimit) bool {
+	key := config.keyFunc(call)
+	if key == "" {
+		key = KeyByClientIP(call)
+	}
+	key = config.keyPrefix + current.path + ":" + key
+
+	var taken result
+	now := time.Now()
+	updateErr := config.store.Update(
+		call.Context(),
+		key,
+		config.algorithm.ttl(current.limit),
+		func(state State) State {
+			newState, newResult := config.algorithm.take(current.limit, state, now)
+			taken = newResult
+			return newState
+		},
+	)
+	if updateErr != nil {
+		slog.Error("Failed to update rate limit, allowing call", "err", updateErr)
+		return true
+	}
+
+	setHeaders(call, current.limit, taken)
+	if taken.allowed {
+		return true
+	}
+
+	call.Header(headers.RetryAfter, strconv.Itoa(seconds(taken.retryAfter)))
+	call.Error(govalin.NewHTTPError(
+		http.StatusTooManyRequests,
+		"Rate limit of "+strconv.Itoa(current.limit.Requests)+" requests per "+current.limit.Window.String()+" exceeded",
+	))
+
+	return false
+}
+
+// setHeaders sets the rate limit headers of the response, unless a more
+// restrictive limit has set them already.
+func setHeaders(call *govalin.Call, limit Limit, taken result) {
+	responseHeaders := (*call.Raw.W).Header()
+	remaining, parseErr := strconv.Atoi(responseHeaders.Get(headers.RateLimitRemaining))
+	if parseErr == nil && remaining < taken.remaining {
+		return
+	}
+
+	call.SetHeader(headers.RateLimitLimit, strconv.Itoa(limit.Requests))
+	call.SetHeader(headers.RateLimitRemaining, strconv.Itoa(taken.remaining))
+	call.SetHeader(headers.RateLimitReset, strconv.Itoa(seconds(taken.reset)))
+	call.SetHeader(headers.RateLimitPolicy, strconv.Itoa(limit.Requests)+";w="+strconv.Itoa(seconds(limit.Window)))
+}
+
+// seconds rounds the duration up to whole seconds, as used by headers.
+func seconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,164 @@
+package ratelimit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ddliu/go-httpclient"
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/plugins/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	config := ratelimit.New().
+		Limit("*", ratelimit.PerMinute(5)).
+		Limit("/api/*", ratelimit.PerMinute(2)).
+		Key(ratelimit.KeyByHeader("X-Api-Key"))
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(govalinConfig *govalin.Config) {
+			govalinConfig.EnableAccessLog(false)
+			govalinConfig.Plugin(config)
+		})
+		app.Route("/api", func() {
+			app.Get("/users", func(call *govalin.Call) {
+				call.Text("users")
+			})
+		})
+
+		return app.Get("/", func(call *govalin.Call) {
+			call.Text("home")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		alice := map[string]string{"X-Api-Key": "alice"}
+
+		response, _ := govalinHTTP.Raw().Do(http.MethodGet, govalinHTTP.Host+"/", alice, nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "5", response.Header.Get("RateLimit-Limit"))
+		assert.Equal(t, "4", response.Header.Get("RateLimit-Remaining"))
+		assert.Equal(t, "12", response.Header.Get("RateLimit-Reset"))
+		assert.Equal(t, "5;w=60", response.Header.Get("RateLimit-Policy"))
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, govalinHTTP.Host+"/api/users", alice, nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "2", response.Header.Get("RateLimit-Limit"), "Should send the most restrictive limit")
+		assert.Equal(t, "1", response.Header.Get("RateLimit-Remaining"))
+
+		_, _ = govalinHTTP.Raw().Do(http.MethodGet, govalinHTTP.Host+"/api/users", alice, nil)
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, govalinHTTP.Host+"/api/users", alice, nil)
+		data, _ := response.ReadAll()
+		body := map[string]any{}
+		assert.NoError(t, json.Unmarshal(data, &body))
+
+		assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+		assert.Equal(t, "30", response.Header.Get("Retry-After"))
+		assert.Equal(t, "0", response.Header.Get("RateLimit-Remaining"))
+		assert.Equal(t, "Too many requests", body["title"])
+		assert.Equal(t, "Rate limit of 2 requests per 1m0s exceeded", body["detail"])
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodGet, govalinHTTP.Host+"/", alice, nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode, "Should only limit the route group")
+		assert.Equal(t, "0", response.Header.Get("RateLimit-Remaining"),
+			"Should count calls to the group against the limit of all paths",
+		)
+
+		response, _ = govalinHTTP.Raw().Do(
+			http.MethodGet,
+			govalinHTTP.Host+"/api/users",
+			map[string]string{"X-Api-Key": "bob"},
+			nil,
+		)
+		assert.Equal(t, http.StatusOK, response.StatusCode, "Should limit each key separately")
+	})
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	config := ratelimit.New().
+		Algorithm(ratelimit.SlidingWindow).
+		Limit("*", ratelimit.Limit{Requests: 1, Window: time.Hour})
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(govalinConfig *govalin.Config) {
+			govalinConfig.EnableAccessLog(false)
+			govalinConfig.Plugin(config)
+		}).Get("/", func(call *govalin.Call) {
+			call.Text("home")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, http.StatusOK, govalinHTTP.GetResponse("/").StatusCode)
+		assert.Equal(t, http.StatusTooManyRequests, govalinHTTP.GetResponse("/").StatusCode,
+			"Should limit by client IP without a key",
+		)
+	})
+}
+
+func TestRateLimitBySession(t *testing.T) {
+	config := ratelimit.New().
+		Limit("*", ratelimit.PerMinute(2)).
+		Key(ratelimit.KeyBySession)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(govalinConfig *govalin.Config) {
+			govalinConfig.EnableAccessLog(false)
+			govalinConfig.EnableSessions()
+			govalinConfig.Plugin(config)
+		}).Get("/", func(call *govalin.Call) {
+			call.Text("home")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, http.StatusOK, govalinHTTP.GetResponse("/").StatusCode)
+
+		withoutCookies := func(client *http.Client, _ *http.Request) {
+			client.Jar = nil
+		}
+		response, _ := govalinHTTP.Raw().Begin().
+			WithOption(httpclient.OPT_BEFORE_REQUEST_FUNC, withoutCookies).
+			Get(govalinHTTP.Host + "/")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		response, _ = govalinHTTP.Raw().Begin().
+			WithOption(httpclient.OPT_BEFORE_REQUEST_FUNC, withoutCookies).
+			Get(govalinHTTP.Host + "/")
+		assert.Equal(t, http.StatusTooManyRequests, response.StatusCode,
+			"Should limit clients not sending the session cookie by client IP",
+		)
+
+		assert.Equal(t, http.StatusOK, govalinHTTP.GetResponse("/").StatusCode,
+			"Should limit clients sending the session cookie by session",
+		)
+		assert.Equal(t, http.StatusOK, govalinHTTP.GetResponse("/").StatusCode)
+		assert.Equal(t, http.StatusTooManyRequests, govalinHTTP.GetResponse("/").StatusCode)
+	})
+}
+
+type failingStore struct{}
+
+func (failingStore) Update(_ context.Context, _ string, _ time.Duration, _ ratelimit.UpdateFunc) error {
+	return errors.New("store unavailable")
+}
+
+func TestRateLimitFailingStore(t *testing.T) {
+	config := ratelimit.New().
+		Store(failingStore{}).
+		Limit("*", ratelimit.PerHour(1))
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(govalinConfig *govalin.Config) {
+			govalinConfig.EnableAccessLog(false)
+			govalinConfig.Plugin(config)
+		}).Get("/", func(call *govalin.Call) {
+			call.Text("home")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, http.StatusOK, govalinHTTP.GetResponse("/").StatusCode)
+		assert.Equal(t, http.StatusOK, govalinHTTP.GetResponse("/").StatusCode,
+			"Should allow calls when the store fails",
+		)
+	})
+}
@@ -0,0 +1,150 @@
+package sqliteratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	// SQLite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkkummermo/govalin/plugins/ratelimit"
+	"github.com/pkkummermo/govalin/plugins/ratelimit/internal"
+)
+
+const (
+	sqliteDriver  = "sqlite3"
+	pruneInterval = time.Minute
+	// Updates lock the database when they begin, so concurrent updates of other
+	// processes wait for each other instead of failing when upgrading their lock
+	immediateTxLock = "_txlock=immediate"
+	busyTimeout     = "_busy_timeout=5000"
+)
+
+type sqliteRateLimitStore struct {
+	mutex      *sync.Mutex
+	db         *sql.DB
+	s          internal.Statements
+	lastPruned time.Time
+}
+
+// NewSqliteStore creates a rate limit store in the SQLite database of the
+// connection string, such as 'file:ratelimit.db', letting processes using the
+// same database file share limits. Updates use immediate transactions and wait
+// up to 5 seconds for other processes, unless the connection string sets the
+// _txlock or _busy_timeout parameters.
+func NewSqliteStore(connectionString string, useWAL bool) (ratelimit.Store, error) {
+	var err error
+
+	ret := sqliteRateLimitStore{
+		mutex:      &sync.Mutex{},
+		lastPruned: time.Now(),
+	}
+
+	if ret.db, err = sql.Open(sqliteDriver, withDefaultParams(connectionString)); err != nil {
+		return &ret, err
+	}
+	// In-memory databases only exist within their connection
+	ret.db.SetMaxOpenConns(1)
+
+	if pingErr := ret.db.Ping(); pingErr != nil {
+		return &ret, pingErr
+	}
+
+	if useWAL {
+		if _, pragmaErr := ret.db.Exec("PRAGMA journal_mode=WAL;"); pragmaErr != nil {
+			return &ret, pragmaErr
+		}
+	}
+
+	if createSchemaErr := ret.createSchema(); createSchemaErr != nil {
+		return &ret, createSchemaErr
+	}
+
+	if ret.s, err = internal.InitStatements(ret.db); err != nil {
+		return &ret, err
+	}
+
+	return &ret, nil
+}
+
+func withDefaultParams(connectionString string) string {
+	params := []string{}
+	if !strings.Contains(connectionString, "_txlock=") {
+		params = append(params, immediateTxLock)
+	}
+	if !strings.Contains(connectionString, "_busy_timeout=") {
+		params = append(params, busyTimeout)
+	}
+	if len(params) == 0 {
+		return connectionString
+	}
+
+	separator := "?"
+	if strings.Contains(connectionString, "?") {
+		separator = "&"
+	}
+
+	return connectionString + separator + strings.Join(params, "&")
+}
+
+func (s *sqliteRateLimitStore) createSchema() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(internal.CreateGovalinRateLimitTable)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(internal.CreateGovalinRateLimitTableIndex)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqliteRateLimitStore) Update(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	updateFunc ratelimit.UpdateFunc,
+) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPruned) > pruneInterval {
+		if _, pruneErr := s.s.PruneStates.ExecContext(ctx, now.UnixNano()); pruneErr != nil {
+			return pruneErr
+		}
+		s.lastPruned = now
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	state := ratelimit.State{}
+	scanErr := tx.StmtContext(ctx, s.s.RetrieveState).
+		QueryRowContext(ctx, key, now.UnixNano()).
+		Scan(&state.Value, &state.Previous, &state.Timestamp)
+	if scanErr != nil && !errors.Is(scanErr, sql.ErrNoRows) {
+		return scanErr
+	}
+
+	state = updateFunc(state)
+	if _, err = tx.StmtContext(ctx, s.s.SetState).ExecContext(
+		ctx, key, state.Value, state.Previous, state.Timestamp, now.Add(ttl).UnixNano(),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
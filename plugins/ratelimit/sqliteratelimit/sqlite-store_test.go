@@ -0,0 +1,72 @@
+package sqliteratelimit_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin/plugins/ratelimit"
+	"github.com/pkkummermo/govalin/plugins/ratelimit/sqliteratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.db")
+
+	// Two stores using the same file act like two processes sharing limits
+	first, firstErr := sqliteratelimit.NewSqliteStore("file:"+path, true)
+	assert.NoError(t, firstErr)
+	second, secondErr := sqliteratelimit.NewSqliteStore("file:"+path, true)
+	assert.NoError(t, secondErr)
+
+	increment := func(state ratelimit.State) ratelimit.State {
+		state.Value++
+		state.Timestamp = time.Now().UnixNano()
+		return state
+	}
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			store := first
+			if i%2 == 0 {
+				store = second
+			}
+			assert.NoError(t, store.Update(context.Background(), "key", time.Minute, increment))
+		}()
+	}
+	wg.Wait()
+
+	var count float64
+	assert.NoError(t, first.Update(context.Background(), "key", time.Minute, func(state ratelimit.State) ratelimit.State {
+		count = state.Value
+		return state
+	}))
+	assert.Equal(t, float64(50), count, "Should not lose concurrent updates")
+
+	assert.NoError(t, first.Update(context.Background(), "expiring", time.Nanosecond, increment))
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, second.Update(context.Background(), "expiring", time.Minute, func(state ratelimit.State) ratelimit.State {
+		assert.Zero(t, state.Value, "Should not return expired states")
+		return state
+	}))
+}
+
+func TestSqliteStoreInMemory(t *testing.T) {
+	store, storeErr := sqliteratelimit.NewSqliteStore(":memory:", false)
+	assert.NoError(t, storeErr)
+
+	assert.NoError(t, store.Update(context.Background(), "key", time.Minute, func(state ratelimit.State) ratelimit.State {
+		state.Value = 3
+		return state
+	}))
+	assert.NoError(t, store.Update(context.Background(), "key", time.Minute, func(state ratelimit.State) ratelimit.State {
+		assert.Equal(t, float64(3), state.Value)
+		return state
+	}))
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultPruneInterval = time.Minute
+
+// State is the stored state of the limit of a key. The meaning of the values
+// depends on the algorithm of the limit.
+type State struct {
+	Value     float64 // Value is the tokens left, or the count of the current window
+	Previous  float64 // Previous is the count of the previous window
+	Timestamp int64   // Timestamp is the time of the state in Unix nanoseconds
+}
+
+// UpdateFunc returns the new state of a key given its current state, which is
+// the zero State for keys without a stored state.
+type UpdateFunc func(state State) State
+
+// Store stores the state of the limits of keys
+//
+// Update must replace the state of the key atomically, so concurrent calls,
+// possibly from multiple processes sharing the store, don't lose updates. The
+// stored state may be removed once the ttl has passed without updates.
+type Store interface {
+	Update(ctx context.Context, key string, ttl time.Duration, updateFunc UpdateFunc) error
+}
+
+type inMemoryEntry struct {
+	state   State
+	expires time.Time
+}
+
+// InMemoryStore stores the state of limits in memory, limiting calls per
+// process.
+type InMemoryStore struct {
+	mutex      sync.Mutex
+	entries    map[string]inMemoryEntry
+	lastPruned time.Time
+}
+
+// NewInMemoryStore creates an empty in-memory store.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		entries:    map[string]inMemoryEntry{},
+		lastPruned: time.Now(),
+	}
+}
+
+func (store *InMemoryStore) Update(_ context.Context, key string, ttl time.Duration, updateFunc UpdateFunc) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(store.lastPruned) > defaultPruneInterval {
+		store.prune(now)
+	}
+
+	entry, found := store.entries[key]
+	if !found || now.After(entry.expires) {
+		entry = inMemoryEntry{}
+	}
+
+	store.entries[key] = inMemoryEntry{
+		state:   updateFunc(entry.state),
+		expires: now.Add(ttl),
+	}
+
+	return nil
+}
+
+// prune removes expired entries, so keys of clients which stopped calling
+// don't use memory forever.
+func (store *InMemoryStore) prune(now time.Time) {
+	for key, entry := range store.entries {
+		if now.After(entry.expires) {
+			delete(store.entries, key)
+		}
+	}
+	store.lastPruned = now
+}
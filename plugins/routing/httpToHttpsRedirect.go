@@ -1,10 +1,10 @@
 package routing
 
 import (
+	"log/slog"
 	"strings"
 
 	"github.com/pkkummermo/govalin"
-	"github.com/pkkummermo/govalin/internal/http/headers"
 )
 
 type HTTPToHTTPSConfig struct {
@@ -12,6 +12,11 @@ type HTTPToHTTPSConfig struct {
 }
 
 // NewHTTPtoHTTPS configures the server to redirect HTTP calls to HTTPS.
+//
+// The scheme forwarded by proxies is only used for calls from proxies trusted
+// using govalin.Config.TrustedProxies. When running behind a load balancer or
+// proxy terminating TLS, it must be trusted, or every call will be seen as HTTP
+// and redirected endlessly. A warning is logged when no proxies are trusted.
 func NewHTTPtoHTTPS() *HTTPToHTTPSConfig {
 	return &HTTPToHTTPSConfig{
 		redirectLocalhost: false,
@@ -22,7 +27,13 @@ func (config *HTTPToHTTPSConfig) Name() string {
 	return "HTTP to HTTPS plugin"
 }
 
-func (config *HTTPToHTTPSConfig) OnInit(_ *govalin.Config) {
+func (config *HTTPToHTTPSConfig) OnInit(govalinConfig *govalin.Config) {
+	if !govalinConfig.HasTrustedProxies() {
+		slog.Warn(
+			"HTTP to HTTPS redirect is enabled without trusted proxies. Calls forwarded by a proxy " +
+				"terminating TLS will be redirected endlessly unless the proxy is trusted using TrustedProxies",
+		)
+	}
 }
 
 func (config *HTTPToHTTPSConfig) Apply(app *govalin.App) {
@@ -35,9 +46,8 @@ func (config *HTTPToHTTPSConfig) Apply(app *govalin.App) {
 			return true
 		}
 
-		// Redirect if scheme is http or forwarded scheme is http
-		xForwardedProto := call.Header(headers.XForwardedProto)
-		if xForwardedProto == "http" || (xForwardedProto == "" && call.Raw.Req.TLS == nil) {
+		// Redirect if the scheme used by the client, possibly forwarded by a trusted proxy, is http
+		if call.Scheme() == "http" {
 			// More often than not we do not want to redirect to HTTPS on localhost, at least not permanently
 			if isLocalhost {
 				call.Redirect("https://"+callHost+call.URL().Path, false)
@@ -1,7 +1,9 @@
 package routing_test
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 	"testing"
@@ -77,3 +79,55 @@ func TestRedirectOnExternalHost(t *testing.T) {
 		assert.Equal(t, "https://govalin.io/govalin", response.Header.Get(headers.Location))
 	})
 }
+
+func TestForwardedSchemeFromTrustedProxies(t *testing.T) {
+	for _, trusted := range []bool{true, false} {
+		govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+			return govalin.New(func(config *govalin.Config) {
+				config.Plugin(routing.NewHTTPtoHTTPS().RedirectLocalHost(true))
+				if trusted {
+					config.TrustedProxies("127.0.0.1", "::1")
+				}
+			}).Get("/govalin", func(call *govalin.Call) {
+				call.Text("govalin")
+			})
+		}, func(govalinHttp govalintesting.GovalinHTTP) {
+			response, _ := govalinHttp.
+				Raw().
+				Begin().
+				WithOption(httpclient.OPT_FOLLOWLOCATION, false).
+				WithHeader(headers.XForwardedProto, "https").
+				Get(govalinHttp.Host + "/govalin")
+
+			if trusted {
+				assert.Equal(t, 200, response.StatusCode, "Should trust the scheme forwarded by trusted proxies")
+			} else {
+				assert.Equal(t, 302, response.StatusCode, "Should ignore the scheme forwarded by other peers")
+			}
+		})
+	}
+}
+
+func TestWarnWithoutTrustedProxies(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	for _, trusted := range []bool{true, false} {
+		var buffer bytes.Buffer
+		slog.SetDefault(slog.New(slog.NewTextHandler(&buffer, nil)))
+
+		govalin.New(func(config *govalin.Config) {
+			config.Plugin(routing.NewHTTPtoHTTPS())
+			if trusted {
+				config.TrustedProxies("10.0.0.0/8")
+			}
+		})
+
+		if trusted {
+			assert.NotContains(t, buffer.String(), "without trusted proxies")
+		} else {
+			assert.Contains(t, buffer.String(), "level=WARN", "Should warn about endless redirects")
+			assert.Contains(t, buffer.String(), "without trusted proxies")
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 type Config struct {
 	connectionString string
 	useWAL           bool
+	keyring          *govalin.Keyring
 }
 
 func New() *Config {
@@ -32,7 +33,7 @@ func (config *Config) OnInit(conf *govalin.Config) {
 	}
 
 	conf.EnableSessions(func(sessionConfig *govalin.SessionConfiguration) {
-		sessionConfig.SessionStore(initiatedStore)
+		sessionConfig.SessionStore(initiatedStore).Keyring(config.keyring)
 	})
 }
 
@@ -49,3 +50,10 @@ func (config *Config) UseWAL(useWAL bool) *Config {
 	config.useWAL = useWAL
 	return config
 }
+
+// Keyring sets the keyring used to sign the session cookie. Session cookies
+// aren't signed by default.
+func (config *Config) Keyring(keyring *govalin.Keyring) *Config {
+	config.keyring = keyring
+	return config
+}
@@ -1,6 +1,7 @@
 package sqlitesession
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"sync"
@@ -51,6 +52,10 @@ func NewSqliteSessionStore(connectionString string, useWAL bool) (session.Store,
 	return &ret, nil
 }
 
+func (s *sqliteSessionStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
 func (s *sqliteSessionStore) createSchema() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
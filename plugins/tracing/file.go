@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileExporter exports spans to a file as JSON lines, where each line is an
+// OTLP/JSON document like those posted by OTLPExporter. The format is the one
+// written and read by the file exporter and receiver of the OpenTelemetry
+// collector, so spans can be imported later.
+type FileExporter struct {
+	mutex       sync.Mutex
+	file        *os.File
+	serviceName string
+}
+
+// NewFileExporter creates an exporter appending spans to the file at the path,
+// creating the file if it doesn't exist.
+func NewFileExporter(path string) (*FileExporter, error) {
+	file, openErr := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if openErr != nil {
+		return nil, fmt.Errorf("failed to open span file. %w", openErr)
+	}
+
+	return &FileExporter{file: file, serviceName: defaultServiceName}, nil
+}
+
+// ServiceName sets the service.name resource attribute of exported spans.
+// Defaults to "govalin".
+func (exporter *FileExporter) ServiceName(serviceName string) *FileExporter {
+	exporter.serviceName = serviceName
+	return exporter
+}
+
+func (exporter *FileExporter) Export(_ context.Context, spans []SpanData) error {
+	line, marshalErr := json.Marshal(newOTLPTraces(exporter.serviceName, spans))
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal spans. %w", marshalErr)
+	}
+
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	if _, writeErr := exporter.file.Write(append(line, '\n')); writeErr != nil {
+		return fmt.Errorf("failed to write spans. %w", writeErr)
+	}
+
+	return nil
+}
+
+func (exporter *FileExporter) Shutdown(_ context.Context) error {
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	return exporter.file.Close()
+}
@@ -0,0 +1,191 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	scopeName             = "github.com/pkkummermo/govalin/plugins/tracing"
+	defaultOTLPHTTPTimout = 10 * time.Second
+)
+
+// OTLPExporter exports spans to an OpenTelemetry collector using OTLP/JSON over
+// HTTP, such as to 'http://localhost:4318/v1/traces'.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	headers     map[string]string
+	client      *http.Client
+}
+
+// NewOTLPExporter creates an exporter posting spans to the traces endpoint of
+// an OpenTelemetry collector.
+func NewOTLPExporter(endpoint string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: defaultServiceName,
+		headers:     map[string]string{},
+		client:      &http.Client{Timeout: defaultOTLPHTTPTimout},
+	}
+}
+
+// ServiceName sets the service.name resource attribute of exported spans.
+// Defaults to "govalin".
+func (exporter *OTLPExporter) ServiceName(serviceName string) *OTLPExporter {
+	exporter.serviceName = serviceName
+	return exporter
+}
+
+// Header sets a header sent with every export, such as an API key.
+func (exporter *OTLPExporter) Header(key string, value string) *OTLPExporter {
+	exporter.headers[key] = value
+	return exporter
+}
+
+// HTTPClient sets the HTTP client used to post spans.
+func (exporter *OTLPExporter) HTTPClient(client *http.Client) *OTLPExporter {
+	exporter.client = client
+	return exporter
+}
+
+func (exporter *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, marshalErr := json.Marshal(newOTLPTraces(exporter.serviceName, spans))
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal spans. %w", marshalErr)
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, exporter.endpoint, bytes.NewReader(body))
+	if reqErr != nil {
+		return fmt.Errorf("failed to create export request. %w", reqErr)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range exporter.headers {
+		req.Header.Set(key, value)
+	}
+
+	response, postErr := exporter.client.Do(req)
+	if postErr != nil {
+		return fmt.Errorf("failed to post spans. %w", postErr)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("collector responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+func (exporter *OTLPExporter) Shutdown(_ context.Context) error {
+	exporter.client.CloseIdleConnections()
+	return nil
+}
+
+// The OTLP/JSON encoding of spans. IDs are hex encoded, enums are numbers and
+// 64 bit integers are strings, as given by the OTLP specification.
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              SpanKind       `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    SpanStatus `json:"code,omitempty"`
+		Message string     `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func newOTLPTraces(serviceName string, spans []SpanData) otlpTraces {
+	otlpSpans := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		otlpSpans[i] = otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        newOTLPAttributes(span.Attributes),
+			Status:            otlpStatus{Code: span.Status, Message: span.StatusMessage},
+		}
+	}
+
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: newOTLPAttributes(map[string]any{"service.name": serviceName})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: scopeName},
+			Spans: otlpSpans,
+		}},
+	}}}
+}
+
+func newOTLPAttributes(attributes map[string]any) []otlpKeyValue {
+	keyValues := make([]otlpKeyValue, 0, len(attributes))
+	for key, value := range attributes {
+		keyValues = append(keyValues, otlpKeyValue{Key: key, Value: newOTLPValue(value)})
+	}
+
+	return keyValues
+}
+
+func newOTLPValue(value any) otlpValue {
+	switch typed := value.(type) {
+	case string:
+		return otlpValue{StringValue: &typed}
+	case bool:
+		return otlpValue{BoolValue: &typed}
+	case int:
+		intValue := strconv.Itoa(typed)
+		return otlpValue{IntValue: &intValue}
+	case int64:
+		intValue := strconv.FormatInt(typed, 10)
+		return otlpValue{IntValue: &intValue}
+	case float64:
+		return otlpValue{DoubleValue: &typed}
+	default:
+		stringValue := fmt.Sprint(typed)
+		return otlpValue{StringValue: &stringValue}
+	}
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// SpanKind is the kind of a span, following OpenTelemetry.
+type SpanKind int
+
+const (
+	// SpanKindInternal is an operation within the server, such as a handler.
+	SpanKindInternal SpanKind = 1
+	// SpanKindServer is the handling of a request by the server.
+	SpanKindServer SpanKind = 2
+	// SpanKindClient is a request made by the server to another service.
+	SpanKindClient SpanKind = 3
+)
+
+// SpanStatus is the status of a span, following OpenTelemetry.
+type SpanStatus int
+
+const (
+	// SpanStatusUnset is the status of spans which haven't failed.
+	SpanStatusUnset SpanStatus = 0
+	// SpanStatusOK is the status of spans explicitly marked as successful.
+	SpanStatusOK SpanStatus = 1
+	// SpanStatusError is the status of failed spans.
+	SpanStatusError SpanStatus = 2
+)
+
+// SpanData is a finished span, as given to exporters.
+type SpanData struct {
+	TraceID       string         // TraceID is the 32 hex character ID of the trace
+	SpanID        string         // SpanID is the 16 hex character ID of the span
+	ParentSpanID  string         // ParentSpanID is the ID of the parent span, or empty for root spans
+	Name          string         // Name describes the operation of the span
+	Kind          SpanKind       // Kind is the kind of the span
+	Start         time.Time      // Start is when the span started
+	End           time.Time      // End is when the span ended
+	Attributes    map[string]any // Attributes describe the operation of the span
+	Status        SpanStatus     // Status is the status of the span
+	StatusMessage string         // StatusMessage describes the error of failed spans
+}
+
+// Span is an operation within a trace
+//
+// Spans are started using StartSpan, and must be ended using End for them to
+// be exported. Methods of spans which aren't recorded, such as spans started
+// without a traced call in the context, do nothing.
+type Span struct {
+	mutex  sync.Mutex
+	data   SpanData
+	tracer *tracer
+	ended  bool
+}
+
+type spanContextKey struct{}
+
+// StartSpan starts a span as a child of the span in the context, returning a
+// context containing the new span. The span of the call is available in the
+// context of the call, see govalin.Call.Context. The returned span isn't
+// recorded if the context doesn't contain a span.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent.tracer == nil {
+		return ctx, parent
+	}
+
+	span := parent.tracer.startSpan(parent.data.TraceID, parent.data.SpanID, name, SpanKindInternal)
+	return ContextWithSpan(ctx, span), span
+}
+
+// SpanFromContext returns the span of the context, or a span which isn't
+// recorded if the context doesn't contain a span.
+func SpanFromContext(ctx context.Context) *Span {
+	if span, ok := ctx.Value(spanContextKey{}).(*Span); ok {
+		return span
+	}
+
+	return &Span{}
+}
+
+// ContextWithSpan returns a context containing the span, making the span the
+// parent of spans started using the context.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// TraceID returns the ID of the trace of the span.
+func (span *Span) TraceID() string {
+	return span.data.TraceID
+}
+
+// SpanID returns the ID of the span.
+func (span *Span) SpanID() string {
+	return span.data.SpanID
+}
+
+// SetName changes the name of the span.
+func (span *Span) SetName(name string) {
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	span.data.Name = name
+}
+
+// SetKind changes the kind of the span, such as SpanKindClient for requests
+// to other services.
+func (span *Span) SetKind(kind SpanKind) {
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	span.data.Kind = kind
+}
+
+// SetAttribute sets an attribute describing the operation of the span. Values
+// should be strings, booleans, integers or floats.
+func (span *Span) SetAttribute(key string, value any) {
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	if span.tracer == nil || span.ended {
+		return
+	}
+	span.data.Attributes[key] = value
+}
+
+// SetStatus sets the status of the span, with a message describing errors.
+func (span *Span) SetStatus(status SpanStatus, message string) {
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	span.data.Status = status
+	span.data.StatusMessage = message
+}
+
+// RecordError marks the span as failed with the error, if the error isn't nil.
+func (span *Span) RecordError(err error) {
+	if err != nil {
+		span.SetStatus(SpanStatusError, err.Error())
+	}
+}
+
+// End ends the span, queueing it for export. Calls after the first are ignored.
+func (span *Span) End() {
+	span.mutex.Lock()
+	if span.tracer == nil || span.ended {
+		span.mutex.Unlock()
+		return
+	}
+	span.ended = true
+	span.data.End = time.Now()
+	data := span.data
+	span.mutex.Unlock()
+
+	span.tracer.enqueue(data)
+}
+
+func randomID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
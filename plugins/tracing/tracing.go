@@ -0,0 +1,266 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+)
+
+const (
+	defaultServiceName   = "govalin"
+	defaultBatchTimeout  = 5 * time.Second
+	defaultMaxBatchSize  = 512
+	defaultMaxQueueSize  = 2048
+	defaultExportTimeout = 10 * time.Second
+)
+
+// Exporter exports finished spans, such as to an OpenTelemetry collector
+//
+// Export is called from a single goroutine with batches of spans. Shutdown is
+// called once when the server has stopped, after the remaining spans have been
+// exported.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// Config contains the configuration of the tracing plugin
+//
+// The plugin records a server span for each call, continuing the trace of the
+// traceparent header of the request, with child spans for the before handlers,
+// the endpoint handler, the after handlers and each call to the session store.
+// Spans are exported in batches in the background. The span of the call is
+// available in the context of the call for starting user spans, see StartSpan.
+type Config struct {
+	exporter     Exporter
+	batchTimeout time.Duration
+	maxBatchSize int
+	tracer       *tracer
+}
+
+// New creates a tracing plugin exporting spans using the exporter.
+func New(exporter Exporter) *Config {
+	return &Config{
+		exporter:     exporter,
+		batchTimeout: defaultBatchTimeout,
+		maxBatchSize: defaultMaxBatchSize,
+	}
+}
+
+func (config *Config) Name() string {
+	return "Tracing plugin"
+}
+
+func (config *Config) OnInit(conf *govalin.Config) {
+	config.tracer = newTracer(config)
+
+	conf.Events(func(serverEvents *govalin.ServerEvents) {
+		serverEvents.AddCallObserver(config)
+		serverEvents.AddOnServerStartup(config.tracer.start)
+		serverEvents.AddOnServerStopped(config.tracer.stop)
+	})
+}
+
+func (config *Config) Apply(_ *govalin.App) {}
+
+// BatchTimeout sets the max time spans are buffered before being exported.
+// Defaults to 5 seconds.
+func (config *Config) BatchTimeout(batchTimeout time.Duration) *Config {
+	if batchTimeout > 0 {
+		config.batchTimeout = batchTimeout
+	}
+	return config
+}
+
+// MaxBatchSize sets the max number of spans exported at once. Defaults to 512.
+func (config *Config) MaxBatchSize(maxBatchSize int) *Config {
+	if maxBatchSize > 0 {
+		config.maxBatchSize = maxBatchSize
+	}
+	return config
+}
+
+// OnCallStart starts the server span of the call.
+func (config *Config) OnCallStart(call *govalin.Call) {
+	traceContext := call.TraceContext()
+	span := config.tracer.startSpan(traceContext.TraceID, traceContext.ParentID, call.Method(), SpanKindServer)
+	span.data.SpanID = traceContext.SpanID
+
+	span.data.Attributes["http.request.method"] = call.Method()
+	span.data.Attributes["url.path"] = call.URL().Path
+	span.data.Attributes["url.scheme"] = call.Scheme()
+	span.data.Attributes["client.address"] = call.ClientIP()
+	span.data.Attributes["govalin.call_id"] = call.ID()
+	if userAgent := call.UserAgent(); userAgent != "" {
+		span.data.Attributes["user_agent.original"] = userAgent
+	}
+
+	call.WithContext(ContextWithSpan(call.Context(), span))
+	call.OnComplete(func() {
+		status := call.Status()
+		if call.Disconnected() {
+			status = govalin.StatusClientClosedRequest
+		}
+
+		if route := call.RoutePattern(); route != "" {
+			span.SetName(call.Method() + " " + route)
+			span.SetAttribute("http.route", route)
+		}
+		span.SetAttribute("http.response.status_code", status)
+		if status >= 500 {
+			span.SetStatus(SpanStatusError, strconv.Itoa(status))
+		}
+
+		span.End()
+	})
+}
+
+// OnCallPhase starts a child span of the server span for the phase.
+func (config *Config) OnCallPhase(call *govalin.Call, phase govalin.CallPhase, operation string) func(err error) {
+	parent := SpanFromContext(call.Context())
+	if parent.tracer == nil {
+		return nil
+	}
+
+	name := string(phase)
+	if operation != "" {
+		name += " " + operation
+	}
+
+	span := config.tracer.startSpan(parent.data.TraceID, parent.data.SpanID, name, SpanKindInternal)
+	span.data.Attributes["govalin.phase"] = string(phase)
+
+	return func(err error) {
+		span.RecordError(err)
+		span.End()
+	}
+}
+
+// tracer creates spans and exports them in batches in the background.
+type tracer struct {
+	config  *Config
+	mutex   sync.Mutex
+	queue   chan SpanData
+	started bool
+	stopped bool
+	done    chan struct{}
+}
+
+func newTracer(config *Config) *tracer {
+	return &tracer{
+		config: config,
+		queue:  make(chan SpanData, defaultMaxQueueSize),
+		done:   make(chan struct{}),
+	}
+}
+
+func (tracer *tracer) startSpan(traceID string, parentID string, name string, kind SpanKind) *Span {
+	return &Span{
+		tracer: tracer,
+		data: SpanData{
+			TraceID:      traceID,
+			SpanID:       randomID(8),
+			ParentSpanID: parentID,
+			Name:         name,
+			Kind:         kind,
+			Start:        time.Now(),
+			Attributes:   map[string]any{},
+		},
+	}
+}
+
+// enqueue queues the span for export, dropping it if the queue is full or the
+// tracer has stopped.
+func (tracer *tracer) enqueue(span SpanData) {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	if tracer.stopped {
+		return
+	}
+
+	select {
+	case tracer.queue <- span:
+	default:
+		slog.Debug("Dropped span as the export queue is full", "span", span.Name)
+	}
+}
+
+func (tracer *tracer) start() {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	if !tracer.started {
+		tracer.started = true
+		go tracer.run()
+	}
+}
+
+// stop exports the remaining spans and shuts the exporter down.
+func (tracer *tracer) stop() {
+	tracer.mutex.Lock()
+	if tracer.stopped {
+		tracer.mutex.Unlock()
+		return
+	}
+	tracer.stopped = true
+	close(tracer.queue)
+	started := tracer.started
+	tracer.mutex.Unlock()
+
+	if started {
+		<-tracer.done
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultExportTimeout)
+	defer cancel()
+	if shutdownErr := tracer.config.exporter.Shutdown(ctx); shutdownErr != nil {
+		slog.Error("Failed to shut down span exporter", "err", shutdownErr)
+	}
+}
+
+func (tracer *tracer) run() {
+	defer close(tracer.done)
+
+	ticker := time.NewTicker(tracer.config.batchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, tracer.config.maxBatchSize)
+	for {
+		select {
+		case span, open := <-tracer.queue:
+			if !open {
+				tracer.export(batch)
+				return
+			}
+
+			batch = append(batch, span)
+			if len(batch) >= tracer.config.maxBatchSize {
+				tracer.export(batch)
+				batch = make([]SpanData, 0, tracer.config.maxBatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				tracer.export(batch)
+				batch = make([]SpanData, 0, tracer.config.maxBatchSize)
+			}
+		}
+	}
+}
+
+func (tracer *tracer) export(batch []SpanData) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultExportTimeout)
+	defer cancel()
+
+	if exportErr := tracer.config.exporter.Export(ctx, batch); exportErr != nil {
+		slog.Error("Failed to export spans", "spans", len(batch), "err", exportErr)
+	}
+}
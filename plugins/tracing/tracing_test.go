@@ -0,0 +1,222 @@
+package tracing_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/plugins/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Attributes   []struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	} `json:"attributes"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+func (span exportedSpan) attribute(key string) any {
+	for _, attribute := range span.Attributes {
+		if attribute.Key == key {
+			for _, value := range attribute.Value {
+				return value
+			}
+		}
+	}
+
+	return nil
+}
+
+type exportedTraces struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []struct {
+				Key   string         `json:"key"`
+				Value map[string]any `json:"value"`
+			} `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []exportedSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+func (traces exportedTraces) spans() []exportedSpan {
+	spans := []exportedSpan{}
+	for _, resourceSpans := range traces.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			spans = append(spans, scopeSpans.Spans...)
+		}
+	}
+
+	return spans
+}
+
+func spansByName(spans []exportedSpan) map[string]exportedSpan {
+	byName := map[string]exportedSpan{}
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+
+	return byName
+}
+
+func TestTracingOTLPExporter(t *testing.T) {
+	var mutex sync.Mutex
+	var traces []exportedTraces
+	var contentType string
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		exported := exportedTraces{}
+		assert.NoError(t, json.Unmarshal(body, &exported))
+
+		mutex.Lock()
+		traces = append(traces, exported)
+		contentType = r.Header.Get("Content-Type")
+		mutex.Unlock()
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOTLPExporter(collector.URL + "/v1/traces").ServiceName("users")
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		app := govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.EnableSessions()
+			config.Plugin(tracing.New(exporter))
+		})
+		app.Before("/users/*", func(_ *govalin.Call) bool {
+			return true
+		})
+
+		return app.
+			Get("/users/{id}", func(call *govalin.Call) {
+				_, span := tracing.StartSpan(call.Context(), "load user")
+				span.SetAttribute("user.id", call.PathParam("id"))
+				span.RecordError(errors.New("not cached"))
+				span.End()
+
+				call.Text("user")
+			}).
+			Get("/fail", func(call *govalin.Call) {
+				call.Status(http.StatusInternalServerError)
+				call.Text("fail")
+			})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, _ := govalinHTTP.Raw().Begin().
+			WithHeader("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01").
+			Get(govalinHTTP.Host + "/users/42")
+		_ = response.Body.Close()
+
+		assert.Equal(t, "fail", govalinHTTP.Get("/fail"))
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	assert.Equal(t, "application/json", contentType)
+	assert.NotEmpty(t, traces, "Should export the spans when the server stops")
+	assert.Equal(t, "users", traces[0].ResourceSpans[0].Resource.Attributes[0].Value["stringValue"])
+
+	spans := []exportedSpan{}
+	for _, exported := range traces {
+		spans = append(spans, exported.spans()...)
+	}
+	byName := spansByName(spans)
+
+	server := byName["GET /users/{id}"]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.TraceID, "Should continue the incoming trace")
+	assert.Equal(t, "00f067aa0ba902b7", server.ParentSpanID)
+	assert.Equal(t, 2, server.Kind)
+	assert.Equal(t, "/users/{id}", server.attribute("http.route"))
+	assert.Equal(t, "200", server.attribute("http.response.status_code"))
+	assert.Equal(t, "127.0.0.1", server.attribute("client.address"))
+
+	traceSpans := []exportedSpan{}
+	for _, span := range spans {
+		if span.TraceID == server.TraceID {
+			traceSpans = append(traceSpans, span)
+		}
+	}
+	traceByName := spansByName(traceSpans)
+	for _, name := range []string{"before", "handler", "after", "session_store CreateSession", "load user"} {
+		child, found := traceByName[name]
+		assert.True(t, found, "Should export the span "+name)
+		assert.Equal(t, server.TraceID, child.TraceID)
+		assert.Equal(t, server.SpanID, child.ParentSpanID, "Should be a child of the server span")
+	}
+	assert.Equal(t, "42", byName["load user"].attribute("user.id"))
+	assert.Equal(t, 2, byName["load user"].Status.Code)
+	assert.Equal(t, "not cached", byName["load user"].Status.Message)
+
+	failed := byName["GET /fail"]
+	assert.Equal(t, 2, failed.Status.Code, "Should fail spans of server errors")
+	assert.Len(t, failed.TraceID, 32)
+	assert.NotEqual(t, server.TraceID, failed.TraceID, "Should start new traces")
+	assert.Empty(t, failed.ParentSpanID)
+}
+
+func TestTracingFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	exporter, exporterErr := tracing.NewFileExporter(path)
+	assert.NoError(t, exporterErr)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(tracing.New(exporter))
+		}).Get("/users/{id}", func(call *govalin.Call) {
+			_, span := tracing.StartSpan(call.Context(), "load user")
+			span.End()
+
+			call.Text("user")
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		assert.Equal(t, "user", govalinHTTP.Get("/users/1"))
+	})
+
+	file, openErr := os.Open(path)
+	assert.NoError(t, openErr)
+	defer file.Close()
+
+	spans := []exportedSpan{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		exported := exportedTraces{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &exported))
+		spans = append(spans, exported.spans()...)
+	}
+
+	byName := spansByName(spans)
+	assert.Contains(t, byName, "GET /users/{id}")
+	assert.Contains(t, byName, "load user")
+}
+
+func TestStartSpanWithoutTrace(t *testing.T) {
+	ctx, span := tracing.StartSpan(context.Background(), "untraced")
+	span.SetAttribute("key", "value")
+	span.End()
+
+	assert.Empty(t, span.TraceID(), "Should not record spans outside of traced calls")
+	assert.Equal(t, span, tracing.SpanFromContext(ctx))
+}
@@ -0,0 +1,220 @@
+package tus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	uploadDataExtension = ".bin"
+	uploadInfoExtension = ".json"
+)
+
+// FileStorage stores uploads in a directory on the local filesystem
+//
+// The data of each upload is stored in a '<id>.bin' file next to a '<id>.json'
+// file containing the state of the upload.
+type FileStorage struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewFileStorage returns a storage keeping uploads in given directory, creating
+// it if it doesn't exist.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if mkdirErr := os.MkdirAll(dir, 0o750); mkdirErr != nil {
+		return nil, fmt.Errorf("failed to create upload directory '%s'. %w", dir, mkdirErr)
+	}
+
+	return &FileStorage{dir: dir}, nil
+}
+
+// Path returns the path of the file containing the data of the upload.
+func (storage *FileStorage) Path(id string) string {
+	return filepath.Join(storage.dir, id+uploadDataExtension)
+}
+
+func (storage *FileStorage) Create(_ context.Context, upload Upload) error {
+	if !isValidID(upload.ID) {
+		return fmt.Errorf("invalid upload ID '%s'", upload.ID)
+	}
+
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	file, createErr := os.OpenFile(storage.Path(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if createErr != nil {
+		return fmt.Errorf("failed to create upload '%s'. %w", upload.ID, createErr)
+	}
+	if closeErr := file.Close(); closeErr != nil {
+		return fmt.Errorf("failed to create upload '%s'. %w", upload.ID, closeErr)
+	}
+
+	return storage.writeInfo(upload)
+}
+
+func (storage *FileStorage) Get(_ context.Context, id string) (Upload, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	return storage.readInfo(id)
+}
+
+func (storage *FileStorage) List(_ context.Context) ([]Upload, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	entries, readErr := os.ReadDir(storage.dir)
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to list uploads. %w", readErr)
+	}
+
+	uploads := []Upload{}
+	for _, entry := range entries {
+		id, isInfo := strings.CutSuffix(entry.Name(), uploadInfoExtension)
+		if !isInfo || entry.IsDir() {
+			continue
+		}
+
+		upload, infoErr := storage.readInfo(id)
+		if infoErr != nil {
+			return nil, infoErr
+		}
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, nil
+}
+
+func (storage *FileStorage) WriteChunk(
+	_ context.Context,
+	id string,
+	offset int64,
+	expiresAt time.Time,
+	reader io.Reader,
+) (Upload, error) {
+	storage.mutex.Lock()
+	upload, infoErr := storage.readInfo(id)
+	storage.mutex.Unlock()
+	if infoErr != nil {
+		return Upload{}, infoErr
+	}
+
+	file, openErr := os.OpenFile(storage.Path(id), os.O_WRONLY, 0o600)
+	if openErr != nil {
+		return Upload{}, fmt.Errorf("failed to open upload '%s'. %w", id, openErr)
+	}
+
+	// Writing happens without holding the lock, as chunks can take long to
+	// receive and the plugin only writes a single chunk per upload at a time
+	written, copyErr := io.Copy(io.NewOffsetWriter(file, offset), reader)
+	closeErr := file.Close()
+
+	upload.Offset = offset + written
+	upload.ExpiresAt = expiresAt
+
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if writeErr := storage.writeInfo(upload); writeErr != nil {
+		return upload, writeErr
+	}
+	if copyErr != nil {
+		return upload, fmt.Errorf("failed to write chunk of upload '%s'. %w", id, copyErr)
+	}
+	if closeErr != nil {
+		return upload, fmt.Errorf("failed to write chunk of upload '%s'. %w", id, closeErr)
+	}
+
+	return upload, nil
+}
+
+func (storage *FileStorage) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	if !isValidID(id) {
+		return nil, ErrUploadNotFound
+	}
+
+	file, openErr := os.Open(storage.Path(id))
+	if errors.Is(openErr, fs.ErrNotExist) {
+		return nil, ErrUploadNotFound
+	}
+	if openErr != nil {
+		return nil, fmt.Errorf("failed to open upload '%s'. %w", id, openErr)
+	}
+
+	return file, nil
+}
+
+func (storage *FileStorage) Remove(_ context.Context, id string) error {
+	if !isValidID(id) {
+		return ErrUploadNotFound
+	}
+
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	for _, path := range []string{storage.infoPath(id), storage.Path(id)} {
+		if removeErr := os.Remove(path); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove upload '%s'. %w", id, removeErr)
+		}
+	}
+
+	return nil
+}
+
+func (storage *FileStorage) infoPath(id string) string {
+	return filepath.Join(storage.dir, id+uploadInfoExtension)
+}
+
+func (storage *FileStorage) readInfo(id string) (Upload, error) {
+	if !isValidID(id) {
+		return Upload{}, ErrUploadNotFound
+	}
+
+	infoBytes, readErr := os.ReadFile(storage.infoPath(id))
+	if errors.Is(readErr, fs.ErrNotExist) {
+		return Upload{}, ErrUploadNotFound
+	}
+	if readErr != nil {
+		return Upload{}, fmt.Errorf("failed to read upload '%s'. %w", id, readErr)
+	}
+
+	upload := Upload{}
+	if unmarshalErr := json.Unmarshal(infoBytes, &upload); unmarshalErr != nil {
+		return Upload{}, fmt.Errorf("failed to read upload '%s'. %w", id, unmarshalErr)
+	}
+
+	return upload, nil
+}
+
+// writeInfo atomically replaces the info file of the upload.
+func (storage *FileStorage) writeInfo(upload Upload) error {
+	infoBytes, marshalErr := json.Marshal(upload)
+	if marshalErr != nil {
+		return fmt.Errorf("failed to write upload '%s'. %w", upload.ID, marshalErr)
+	}
+
+	tempPath := storage.infoPath(upload.ID) + ".tmp"
+	if writeErr := os.WriteFile(tempPath, infoBytes, 0o600); writeErr != nil {
+		return fmt.Errorf("failed to write upload '%s'. %w", upload.ID, writeErr)
+	}
+	if renameErr := os.Rename(tempPath, storage.infoPath(upload.ID)); renameErr != nil {
+		return fmt.Errorf("failed to write upload '%s'. %w", upload.ID, renameErr)
+	}
+
+	return nil
+}
+
+// isValidID makes sure IDs can't be used to access files outside the directory.
+func isValidID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\.`)
+}
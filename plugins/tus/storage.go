@@ -0,0 +1,59 @@
+package tus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrUploadNotFound is returned by storages for uploads which don't exist.
+var ErrUploadNotFound = errors.New("upload not found")
+
+// Upload contains the state of an upload.
+type Upload struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata"`
+	SessionID string            `json:"sessionId,omitempty"` // SessionID is the govalin session which created the upload
+	CreatedAt time.Time         `json:"createdAt"`
+	ExpiresAt time.Time         `json:"expiresAt"` // ExpiresAt is zero for uploads which don't expire
+}
+
+// Complete returns whether all the data of the upload has been received.
+func (upload Upload) Complete() bool {
+	return upload.Offset == upload.Size
+}
+
+// Expired returns whether the upload is incomplete and has expired at given time.
+func (upload Upload) Expired(now time.Time) bool {
+	return !upload.Complete() && !upload.ExpiresAt.IsZero() && now.After(upload.ExpiresAt)
+}
+
+// Storage stores uploads and their data
+//
+// The plugin makes sure chunks of an upload are written one at a time, but
+// different uploads are written concurrently.
+type Storage interface {
+	// Create stores a new upload without any data.
+	Create(ctx context.Context, upload Upload) error
+
+	// Get returns the upload with given ID, or ErrUploadNotFound.
+	Get(ctx context.Context, id string) (Upload, error)
+
+	// List returns all stored uploads.
+	List(ctx context.Context) ([]Upload, error)
+
+	// WriteChunk appends data from the reader to the upload at given offset and
+	// stores the new offset and expiry time of the upload. Data which was written
+	// before the reader failed must be kept, letting clients resume from there.
+	// Returns the updated upload.
+	WriteChunk(ctx context.Context, id string, offset int64, expiresAt time.Time, reader io.Reader) (Upload, error)
+
+	// Open returns a reader for the data of the upload.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+
+	// Remove removes the upload and its data.
+	Remove(ctx context.Context, id string) error
+}
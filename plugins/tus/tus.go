@@ -0,0 +1,542 @@
+package tus
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+// Version is the version of the tus protocol implemented by the plugin.
+const Version = "1.0.0"
+
+const (
+	headerTusResumable   = "Tus-Resumable"
+	headerTusVersion     = "Tus-Version"
+	headerTusExtension   = "Tus-Extension"
+	headerTusMaxSize     = "Tus-Max-Size"
+	headerUploadLength   = "Upload-Length"
+	headerUploadOffset   = "Upload-Offset"
+	headerUploadMetadata = "Upload-Metadata"
+	headerUploadExpires  = "Upload-Expires"
+
+	contentTypeOffsetOctetStream = "application/offset+octet-stream"
+	extensions                   = "creation,termination,expiration"
+
+	defaultExpiration      = 24 * time.Hour
+	defaultCleanupInterval = 10 * time.Minute
+)
+
+// CompleteFunc is called when all the data of an upload has been received.
+type CompleteFunc func(call *govalin.Call, upload Upload)
+
+// Config contains the configuration of the tus plugin
+//
+// The plugin mounts a tus 1.0 endpoint for resumable uploads, supporting the
+// creation, termination and expiration extensions. Uploads are created by
+// POSTing to the path, and are available at '<path>/<id>'. See https://tus.io
+// for the protocol.
+//
+// When sessions are enabled, uploads are tied to the session which created
+// them, and can only be resumed, inspected and terminated within it.
+type Config struct {
+	path            string
+	storage         Storage
+	maxSize         int64
+	expiration      time.Duration
+	cleanupInterval time.Duration
+	onComplete      []CompleteFunc
+	locksMutex      sync.Mutex
+	locks           map[string]struct{}
+	stopCleanup     context.CancelFunc
+}
+
+// New creates a tus plugin mounting the endpoint on given path, storing uploads
+// in given storage.
+func New(path string, storage Storage) *Config {
+	return &Config{
+		path:            strings.TrimSuffix(path, "/"),
+		storage:         storage,
+		maxSize:         0,
+		expiration:      defaultExpiration,
+		cleanupInterval: defaultCleanupInterval,
+		onComplete:      []CompleteFunc{},
+		locks:           map[string]struct{}{},
+	}
+}
+
+// MaxSize sets the max size of uploads in bytes. Defaults to no limit.
+func (config *Config) MaxSize(maxSize int64) *Config {
+	config.maxSize = maxSize
+	return config
+}
+
+// Expiration sets how long incomplete uploads are kept since they were last
+// written to. Expired uploads are removed from the storage. Defaults to 24
+// hours, 0 disables expiration.
+func (config *Config) Expiration(expiration time.Duration) *Config {
+	config.expiration = expiration
+	return config
+}
+
+// CleanupInterval sets how often expired uploads are removed. Defaults to 10
+// minutes.
+func (config *Config) CleanupInterval(interval time.Duration) *Config {
+	config.cleanupInterval = interval
+	return config
+}
+
+// OnComplete adds a function which is called with the call receiving the last
+// chunk of an upload. The data of the upload can be read using Storage.Open.
+func (config *Config) OnComplete(completeFunc CompleteFunc) *Config {
+	config.onComplete = append(config.onComplete, completeFunc)
+	return config
+}
+
+func (config *Config) Name() string {
+	return "tus plugin"
+}
+
+func (config *Config) OnInit(govalinConfig *govalin.Config) {
+	if config.expiration <= 0 || config.cleanupInterval <= 0 {
+		return
+	}
+
+	govalinConfig.Events(func(serverEvents *govalin.ServerEvents) {
+		serverEvents.AddOnServerStartup(func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			config.stopCleanup = cancel
+			go config.cleanupExpired(ctx)
+		})
+		serverEvents.AddOnServerShutdown(func() {
+			if config.stopCleanup != nil {
+				config.stopCleanup()
+			}
+		})
+	})
+}
+
+func (config *Config) Apply(app *govalin.App) {
+	uploadPath := config.path + "/{id}"
+
+	app.Options(config.path, config.handleOptions, func(route *govalin.Route) {
+		route.Summary("Get the capabilities of the tus server")
+	})
+	app.Post(config.path, config.handleCreate, func(route *govalin.Route) {
+		route.Summary("Create a resumable upload")
+	})
+	app.Head(uploadPath, config.handleHead, func(route *govalin.Route) {
+		route.Summary("Get the offset of a resumable upload")
+	})
+	app.Patch(uploadPath, config.handlePatch, func(route *govalin.Route) {
+		route.Summary("Upload a chunk of a resumable upload")
+	})
+	app.Delete(uploadPath, config.handleDelete, func(route *govalin.Route) {
+		route.Summary("Terminate a resumable upload")
+	})
+}
+
+func (config *Config) handleOptions(call *govalin.Call) {
+	call.Header(headerTusResumable, Version)
+	call.Header(headerTusVersion, Version)
+	call.Header(headerTusExtension, extensions)
+	if config.maxSize > 0 {
+		call.Header(headerTusMaxSize, strconv.FormatInt(config.maxSize, 10))
+	}
+
+	respond(call, http.StatusNoContent)
+}
+
+func (config *Config) handleCreate(call *govalin.Call) {
+	if !checkVersion(call) {
+		return
+	}
+
+	size, sizeErr := strconv.ParseInt(call.Header(headerUploadLength), 10, 64)
+	if sizeErr != nil || size < 0 {
+		call.Error(govalin.NewHTTPError(
+			http.StatusBadRequest,
+			fmt.Sprintf("Missing or invalid '%s' header", headerUploadLength),
+		))
+		return
+	}
+	if config.maxSize > 0 && size > config.maxSize {
+		call.Error(govalin.NewHTTPError(
+			http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The upload exceeds the max size of %d bytes", config.maxSize),
+		))
+		return
+	}
+
+	metadata, metadataErr := parseMetadata(call.Header(headerUploadMetadata))
+	if metadataErr != nil {
+		call.Error(govalin.NewHTTPError(http.StatusBadRequest, metadataErr.Error()))
+		return
+	}
+
+	now := time.Now()
+	upload := Upload{
+		ID:        uuid.NewString(),
+		Size:      size,
+		Offset:    0,
+		Metadata:  metadata,
+		SessionID: govalin.SessionIDFromContext(call.Context()),
+		CreatedAt: now,
+		ExpiresAt: config.expiresAt(now),
+	}
+
+	if createErr := config.storage.Create(call.Context(), upload); createErr != nil {
+		call.Error(createErr)
+		return
+	}
+
+	call.Header(headers.Location, config.path+"/"+upload.ID)
+	setExpires(call, upload)
+
+	if upload.Complete() {
+		config.complete(call, upload)
+	}
+
+	respond(call, http.StatusCreated)
+}
+
+func (config *Config) handleHead(call *govalin.Call) {
+	if !checkVersion(call) {
+		return
+	}
+
+	upload, found := config.findUpload(call)
+	if !found {
+		return
+	}
+
+	call.Header(headers.CacheControl, "no-store")
+	call.Header(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	call.Header(headerUploadLength, strconv.FormatInt(upload.Size, 10))
+	if len(upload.Metadata) > 0 {
+		call.Header(headerUploadMetadata, formatMetadata(upload.Metadata))
+	}
+	setExpires(call, upload)
+
+	respond(call, http.StatusOK)
+}
+
+func (config *Config) handlePatch(call *govalin.Call) {
+	if !checkVersion(call) {
+		return
+	}
+
+	if call.Header(headers.ContentType) != contentTypeOffsetOctetStream {
+		call.Error(govalin.NewHTTPError(
+			http.StatusUnsupportedMediaType,
+			fmt.Sprintf("The '%s' header must be '%s'", headers.ContentType, contentTypeOffsetOctetStream),
+		))
+		return
+	}
+
+	offset, offsetErr := strconv.ParseInt(call.Header(headerUploadOffset), 10, 64)
+	if offsetErr != nil || offset < 0 {
+		call.Error(govalin.NewHTTPError(
+			http.StatusBadRequest,
+			fmt.Sprintf("Missing or invalid '%s' header", headerUploadOffset),
+		))
+		return
+	}
+
+	id := call.PathParam("id")
+	if !config.lock(id) {
+		call.Error(govalin.NewHTTPError(http.StatusLocked, "The upload is already being written to"))
+		return
+	}
+	defer config.unlock(id)
+
+	upload, found := config.findUpload(call)
+	if !found {
+		return
+	}
+
+	if offset != upload.Offset {
+		call.Error(govalin.NewHTTPError(
+			http.StatusConflict,
+			fmt.Sprintf("The offset %d doesn't match the offset of the upload", offset),
+		))
+		return
+	}
+
+	remaining := upload.Size - upload.Offset
+	chunkTooLargeErr := govalin.NewHTTPError(
+		http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("The chunk exceeds the remaining %d bytes of the upload", remaining),
+	)
+	if call.Raw.Req.ContentLength > remaining {
+		call.Error(chunkTooLargeErr)
+		return
+	}
+
+	upload, writeErr := config.storage.WriteChunk(
+		call.Context(),
+		id,
+		offset,
+		config.expiresAt(time.Now()),
+		&chunkReader{reader: call.Raw.Req.Body, remaining: remaining},
+	)
+	if errors.Is(writeErr, errChunkTooLarge) {
+		call.Error(chunkTooLargeErr)
+		return
+	}
+	if writeErr != nil {
+		// The written data is kept, so clients can resume from the new offset
+		slog.Warn("Failed to write chunk of upload", "id", id, "offset", offset, "err", writeErr)
+		call.Error(writeErr)
+		return
+	}
+
+	call.Header(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	setExpires(call, upload)
+
+	if upload.Complete() {
+		config.complete(call, upload)
+	}
+
+	respond(call, http.StatusNoContent)
+}
+
+func (config *Config) handleDelete(call *govalin.Call) {
+	if !checkVersion(call) {
+		return
+	}
+
+	id := call.PathParam("id")
+	if !config.lock(id) {
+		call.Error(govalin.NewHTTPError(http.StatusLocked, "The upload is being written to"))
+		return
+	}
+	defer config.unlock(id)
+
+	upload, found := config.findUpload(call)
+	if !found {
+		return
+	}
+
+	if removeErr := config.storage.Remove(call.Context(), upload.ID); removeErr != nil {
+		call.Error(removeErr)
+		return
+	}
+
+	respond(call, http.StatusNoContent)
+}
+
+// findUpload returns the upload of the call, responding with an error if it
+// doesn't exist, has expired or belongs to another session.
+func (config *Config) findUpload(call *govalin.Call) (Upload, bool) {
+	upload, getErr := config.storage.Get(call.Context(), call.PathParam("id"))
+	if errors.Is(getErr, ErrUploadNotFound) ||
+		(getErr == nil && upload.SessionID != "" && upload.SessionID != govalin.SessionIDFromContext(call.Context())) {
+		call.Error(govalin.NewHTTPError(http.StatusNotFound, "The upload doesn't exist"))
+		return Upload{}, false
+	}
+	if getErr != nil {
+		call.Error(getErr)
+		return Upload{}, false
+	}
+
+	if upload.Expired(time.Now()) {
+		call.Error(govalin.NewHTTPError(http.StatusGone, "The upload has expired"))
+		return Upload{}, false
+	}
+
+	return upload, true
+}
+
+func (config *Config) complete(call *govalin.Call, upload Upload) {
+	for _, completeFunc := range config.onComplete {
+		completeFunc(call, upload)
+	}
+}
+
+func (config *Config) expiresAt(now time.Time) time.Time {
+	if config.expiration <= 0 {
+		return time.Time{}
+	}
+
+	return now.Add(config.expiration)
+}
+
+// lock makes sure only a single call writes to an upload at a time.
+func (config *Config) lock(id string) bool {
+	config.locksMutex.Lock()
+	defer config.locksMutex.Unlock()
+
+	if _, locked := config.locks[id]; locked {
+		return false
+	}
+	config.locks[id] = struct{}{}
+
+	return true
+}
+
+func (config *Config) unlock(id string) {
+	config.locksMutex.Lock()
+	defer config.locksMutex.Unlock()
+
+	delete(config.locks, id)
+}
+
+func (config *Config) cleanupExpired(ctx context.Context) {
+	ticker := time.NewTicker(config.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			config.removeExpired(ctx)
+		}
+	}
+}
+
+func (config *Config) removeExpired(ctx context.Context) {
+	uploads, listErr := config.storage.List(ctx)
+	if listErr != nil {
+		slog.Error("Failed to list uploads for expiration", "err", listErr)
+		return
+	}
+
+	now := time.Now()
+	for _, upload := range uploads {
+		if !upload.Expired(now) || !config.lock(upload.ID) {
+			continue
+		}
+
+		if removeErr := config.storage.Remove(ctx, upload.ID); removeErr != nil {
+			slog.Error("Failed to remove expired upload", "id", upload.ID, "err", removeErr)
+		}
+		config.unlock(upload.ID)
+	}
+}
+
+// checkVersion responds with 412 Precondition Failed if the client doesn't use
+// a supported version of the protocol.
+func checkVersion(call *govalin.Call) bool {
+	call.Header(headerTusResumable, Version)
+
+	if call.Header(headerTusResumable) != Version {
+		call.Header(headerTusVersion, Version)
+		call.Error(govalin.NewHTTPError(
+			http.StatusPreconditionFailed,
+			fmt.Sprintf("Unsupported tus version. Supported versions are '%s'", Version),
+		))
+		return false
+	}
+
+	return true
+}
+
+// respond sends the status without a body.
+func respond(call *govalin.Call, status int) {
+	call.Status(status)
+	_ = call.Writer().Flush()
+}
+
+func setExpires(call *govalin.Call, upload Upload) {
+	if !upload.ExpiresAt.IsZero() && !upload.Complete() {
+		call.Header(headerUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// errChunkTooLarge is returned by chunkReader for bodies exceeding the remaining
+// length of the upload.
+var errChunkTooLarge = errors.New("chunk exceeds the remaining length of the upload")
+
+// chunkReader reads the body of a chunk, failing with errChunkTooLarge once it
+// exceeds the remaining length of the upload. Bodies without a Content-Length
+// can't be checked up front, so the last byte of the upload is held back until
+// the end of the body is reached. That way a chunk which is too large never
+// completes the upload, and the client can resume from the stored offset.
+type chunkReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (reader *chunkReader) Read(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	if reader.remaining <= 1 {
+		buffer := make([]byte, reader.remaining+1)
+		read, readErr := io.ReadFull(reader.reader, buffer)
+		if int64(read) > reader.remaining {
+			return 0, errChunkTooLarge
+		}
+		if read == 0 {
+			return 0, readErr
+		}
+
+		reader.remaining -= int64(read)
+		return copy(data, buffer[:read]), nil
+	}
+
+	if int64(len(data)) > reader.remaining-1 {
+		data = data[:reader.remaining-1]
+	}
+
+	read, readErr := reader.reader.Read(data)
+	reader.remaining -= int64(read)
+
+	return read, readErr
+}
+
+// parseMetadata parses the Upload-Metadata header, consisting of comma separated
+// pairs of keys and base64 encoded values.
+func parseMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encodedValue, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, fmt.Errorf("invalid '%s' header. Keys must not be empty", headerUploadMetadata)
+		}
+		if _, exists := metadata[key]; exists {
+			return nil, fmt.Errorf("invalid '%s' header. The key '%s' is given more than once", headerUploadMetadata, key)
+		}
+
+		value, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedValue))
+		if decodeErr != nil {
+			return nil, fmt.Errorf("invalid '%s' header. The value of '%s' is not base64 encoded", headerUploadMetadata, key)
+		}
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
+
+func formatMetadata(metadata map[string]string) string {
+	pairs := []string{}
+	for key, value := range metadata {
+		if value == "" {
+			pairs = append(pairs, key)
+		} else {
+			pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+		}
+	}
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, ",")
+}
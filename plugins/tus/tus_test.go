@@ -0,0 +1,255 @@
+package tus_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ddliu/go-httpclient"
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/pkkummermo/govalin/plugins/tus"
+	"github.com/stretchr/testify/assert"
+)
+
+func tusRequest(
+	t *testing.T,
+	govalinHTTP *govalintesting.GovalinHTTP,
+	method string,
+	path string,
+	requestHeaders map[string]string,
+	body string,
+) *httpclient.Response {
+	allHeaders := map[string]string{"Tus-Resumable": tus.Version}
+	for key, value := range requestHeaders {
+		allHeaders[key] = value
+	}
+
+	response, err := govalinHTTP.Raw().Do(method, govalinHTTP.Host+path, allHeaders, strings.NewReader(body))
+	assert.NoError(t, err)
+
+	return response
+}
+
+func patchHeaders(offset string) map[string]string {
+	return map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": offset,
+	}
+}
+
+func TestTus(t *testing.T) {
+	storage, _ := tus.NewFileStorage(t.TempDir())
+	completed := make(chan string, 1)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(tus.New("/files", storage).
+				MaxSize(100).
+				OnComplete(func(call *govalin.Call, upload tus.Upload) {
+					reader, _ := storage.Open(call.Context(), upload.ID)
+					defer reader.Close()
+					content, _ := io.ReadAll(reader)
+					completed <- upload.Metadata["filename"] + ":" + string(content)
+				}))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := tusRequest(t, &govalinHTTP, http.MethodOptions, "/files", nil, "")
+		assert.Equal(t, 204, response.StatusCode)
+		assert.Equal(t, "1.0.0", response.Header.Get("Tus-Version"))
+		assert.Equal(t, "creation,termination,expiration", response.Header.Get("Tus-Extension"))
+		assert.Equal(t, "100", response.Header.Get("Tus-Max-Size"))
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPost, "/files", map[string]string{
+			"Upload-Length":   "11",
+			"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte("video.mp4")) + ",private",
+		}, "")
+		assert.Equal(t, 201, response.StatusCode)
+		assert.Equal(t, "1.0.0", response.Header.Get("Tus-Resumable"))
+		assert.NotEmpty(t, response.Header.Get("Upload-Expires"))
+		location := response.Header.Get("Location")
+		assert.True(t, strings.HasPrefix(location, "/files/"))
+
+		response = tusRequest(t, &govalinHTTP, http.MethodHead, location, nil, "")
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "0", response.Header.Get("Upload-Offset"))
+		assert.Equal(t, "11", response.Header.Get("Upload-Length"))
+		assert.Equal(t, "no-store", response.Header.Get("Cache-Control"))
+		assert.Equal(
+			t,
+			"filename "+base64.StdEncoding.EncodeToString([]byte("video.mp4"))+",private",
+			response.Header.Get("Upload-Metadata"),
+		)
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPatch, location, patchHeaders("0"), "hello ")
+		assert.Equal(t, 204, response.StatusCode)
+		assert.Equal(t, "6", response.Header.Get("Upload-Offset"))
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPatch, location, patchHeaders("0"), "hello ")
+		assert.Equal(t, 409, response.StatusCode, "Should refuse chunks not matching the offset")
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPatch, location, patchHeaders("6"), "world and more")
+		assert.Equal(t, 413, response.StatusCode, "Should refuse chunks exceeding the upload size")
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPatch, location, map[string]string{
+			"Upload-Offset": "6",
+		}, "world")
+		assert.Equal(t, 415, response.StatusCode)
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPatch, location, patchHeaders("6"), "world")
+		assert.Equal(t, 204, response.StatusCode)
+		assert.Equal(t, "11", response.Header.Get("Upload-Offset"))
+		assert.Empty(t, response.Header.Get("Upload-Expires"), "Should not expire completed uploads")
+
+		select {
+		case result := <-completed:
+			assert.Equal(t, "video.mp4:hello world", result)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Upload was not completed")
+		}
+
+		response = tusRequest(t, &govalinHTTP, http.MethodDelete, location, nil, "")
+		assert.Equal(t, 204, response.StatusCode)
+
+		response = tusRequest(t, &govalinHTTP, http.MethodHead, location, nil, "")
+		assert.Equal(t, 404, response.StatusCode)
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPost, "/files", map[string]string{
+			"Upload-Length": "101",
+		}, "")
+		assert.Equal(t, 413, response.StatusCode)
+
+		response = tusRequest(t, &govalinHTTP, http.MethodPost, "/files", map[string]string{
+			"Upload-Length":   "1",
+			"Upload-Metadata": "filename not-base64!",
+		}, "")
+		assert.Equal(t, 400, response.StatusCode)
+
+		response, _ = govalinHTTP.Raw().Do(http.MethodPost, govalinHTTP.Host+"/files", map[string]string{
+			"Upload-Length": "1",
+		}, nil)
+		assert.Equal(t, 412, response.StatusCode, "Should require the Tus-Resumable header")
+		assert.Equal(t, "1.0.0", response.Header.Get("Tus-Version"))
+	})
+}
+
+func TestTusExpiration(t *testing.T) {
+	storage, _ := tus.NewFileStorage(t.TempDir())
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(tus.New("/files", storage).
+				Expiration(50 * time.Millisecond).
+				CleanupInterval(20 * time.Millisecond))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := tusRequest(t, &govalinHTTP, http.MethodPost, "/files", map[string]string{
+			"Upload-Length": "10",
+		}, "")
+		location := response.Header.Get("Location")
+
+		assert.Eventually(t, func() bool {
+			uploads, _ := storage.List(context.Background())
+			return len(uploads) == 0
+		}, time.Second, 10*time.Millisecond, "Should remove expired uploads")
+
+		response = tusRequest(t, &govalinHTTP, http.MethodHead, location, nil, "")
+		assert.Equal(t, 404, response.StatusCode)
+	})
+}
+
+func TestTusSession(t *testing.T) {
+	storage, _ := tus.NewFileStorage(t.TempDir())
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.EnableSessions()
+			config.Plugin(tus.New("/files", storage))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := tusRequest(t, &govalinHTTP, http.MethodPost, "/files", map[string]string{
+			"Upload-Length": "10",
+		}, "")
+		location := response.Header.Get("Location")
+
+		uploads, _ := storage.List(context.Background())
+		assert.Len(t, uploads, 1)
+		assert.NotEmpty(t, uploads[0].SessionID, "Should tie the upload to the session")
+
+		response = tusRequest(t, &govalinHTTP, http.MethodHead, location, nil, "")
+		assert.Equal(t, 200, response.StatusCode, "Should be available within the session")
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodHead, govalinHTTP.Host+location, nil)
+		req.Header.Set("Tus-Resumable", tus.Version)
+		otherSession, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		otherSession.Body.Close()
+		assert.Equal(t, 404, otherSession.StatusCode, "Should not be available to other sessions")
+	})
+}
+
+func TestTusChunkedBody(t *testing.T) {
+	storage, _ := tus.NewFileStorage(t.TempDir())
+	completed := make(chan string, 2)
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.Plugin(tus.New("/files", storage).
+				OnComplete(func(call *govalin.Call, upload tus.Upload) {
+					reader, _ := storage.Open(call.Context(), upload.ID)
+					defer reader.Close()
+					content, _ := io.ReadAll(reader)
+					completed <- string(content)
+				}))
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		// Hide the length of the body, so it's sent using chunked encoding
+		patchChunked := func(location string, offset string, body string) *http.Response {
+			req, _ := http.NewRequest(
+				http.MethodPatch,
+				govalinHTTP.Host+location,
+				io.MultiReader(strings.NewReader(body)),
+			)
+			req.Header.Set("Tus-Resumable", tus.Version)
+			for key, value := range patchHeaders(offset) {
+				req.Header.Set(key, value)
+			}
+			response, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			_ = response.Body.Close()
+
+			return response
+		}
+
+		response := tusRequest(t, &govalinHTTP, http.MethodPost, "/files", map[string]string{
+			"Upload-Length": "5",
+		}, "")
+		location := response.Header.Get("Location")
+
+		chunkedResponse := patchChunked(location, "0", "hello world")
+		assert.Equal(t, 413, chunkedResponse.StatusCode, "Should refuse chunked bodies exceeding the upload size")
+
+		response = tusRequest(t, &govalinHTTP, http.MethodHead, location, nil, "")
+		assert.Equal(t, "4", response.Header.Get("Upload-Offset"), "Should not complete the upload")
+		assert.Empty(t, completed)
+
+		chunkedResponse = patchChunked(location, "4", "o")
+		assert.Equal(t, 204, chunkedResponse.StatusCode)
+		assert.Equal(t, "5", chunkedResponse.Header.Get("Upload-Offset"))
+
+		select {
+		case result := <-completed:
+			assert.Equal(t, "hello", result)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Upload was not completed")
+		}
+	})
+}
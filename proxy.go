@@ -0,0 +1,212 @@
+package govalin
+
+import (
+	"log/slog"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const (
+	schemeHTTP  = "http"
+	schemeHTTPS = "https"
+)
+
+// ClientIPAttribute is the call attribute key of the IP address of the client,
+// as resolved by Call.ClientIP. It's published for every call, so it can be
+// read using Attr like the values published by plugins.
+const ClientIPAttribute = "govalin.client.ip"
+
+// clientInfo is the client address, scheme and host of a call, resolved from
+// the connection and the forwarding headers of trusted proxies.
+type clientInfo struct {
+	ip     string
+	scheme string
+	host   string
+}
+
+// TrustedProxies sets the proxies trusted to forward requests, as IP addresses
+// or CIDR ranges such as '10.0.0.0/8'
+//
+// Call.ClientIP, Call.Scheme and Call.Host use the Forwarded header, or the
+// X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host headers, of requests
+// from trusted proxies. The headers of other peers are ignored, as anyone can
+// set them. No proxies are trusted by default. Invalid values are logged and
+// ignored.
+func (config *Config) TrustedProxies(cidrs ...string) *Config {
+	config.server.trustedProxies = []netip.Prefix{}
+
+	for _, cidr := range cidrs {
+		prefix, parseErr := netip.ParsePrefix(cidr)
+		if parseErr != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				slog.Error("Ignoring invalid trusted proxy", "proxy", cidr, "err", parseErr)
+				continue
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		config.server.trustedProxies = append(config.server.trustedProxies, prefix.Masked())
+	}
+
+	return config
+}
+
+// HasTrustedProxies returns whether any proxies are trusted to forward
+// requests. See Config.TrustedProxies.
+func (config *Config) HasTrustedProxies() bool {
+	return len(config.server.trustedProxies) > 0
+}
+
+// ClientIP returns the IP address of the client
+//
+// Returns the address of the peer, unless the peer is a trusted proxy, in
+// which case the forwarding headers are followed until the first address which
+// isn't a trusted proxy. See Config.TrustedProxies.
+func (call *Call) ClientIP() string {
+	return call.client().ip
+}
+
+// Scheme returns the scheme used by the client, either 'http' or 'https'
+//
+// Returns the scheme of the connection, unless the peer is a trusted proxy, in
+// which case the scheme forwarded by the proxy is used. See
+// Config.TrustedProxies.
+func (call *Call) Scheme() string {
+	return call.client().scheme
+}
+
+// Host returns the host requested by the client
+//
+// Returns the Host header of the request, unless the peer is a trusted proxy,
+// in which case the host forwarded by the proxy is used. See
+// Config.TrustedProxies.
+func (call *Call) Host() string {
+	return call.client().host
+}
+
+// client resolves and caches the client info of the call.
+func (call *Call) client() clientInfo {
+	if call.clientInfo != nil {
+		return *call.clientInfo
+	}
+
+	info := clientInfo{ip: remoteIP(call.req.RemoteAddr), scheme: schemeHTTP, host: call.req.Host}
+	if call.req.TLS != nil {
+		info.scheme = schemeHTTPS
+	}
+
+	if call.isTrustedProxy(info.ip) {
+		if forwarded := call.Forwarded(); len(forwarded) > 0 {
+			call.resolveForwarded(&info, forwarded)
+		} else {
+			call.resolveXForwarded(&info)
+		}
+	}
+
+	call.clientInfo = &info
+	return info
+}
+
+// resolveForwarded resolves the client info from the elements of the Forwarded
+// header, using the host and scheme forwarded by the proxy the client connected
+// to.
+func (call *Call) resolveForwarded(info *clientInfo, forwarded []ForwardedElement) {
+	addresses := make([]string, len(forwarded))
+	for i, element := range forwarded {
+		addresses[i] = element.For
+	}
+
+	index := call.clientIndex(addresses)
+	if index < 0 {
+		return
+	}
+	info.ip = remoteIP(addresses[index])
+
+	element := forwarded[index]
+	if element.Proto == schemeHTTP || element.Proto == schemeHTTPS {
+		info.scheme = element.Proto
+	}
+	if element.Host != "" {
+		info.host = element.Host
+	}
+}
+
+// resolveXForwarded resolves the client info from the X-Forwarded-* headers.
+// The scheme and host are taken from the last value, set by the closest proxy.
+func (call *Call) resolveXForwarded(info *clientInfo) {
+	addresses := splitHeaderElements(call.HeaderValues(headers.XForwardedFor), ',')
+	if index := call.clientIndex(addresses); index >= 0 {
+		info.ip = remoteIP(addresses[index])
+	}
+
+	if protos := splitHeaderElements(call.HeaderValues(headers.XForwardedProto), ','); len(protos) > 0 {
+		proto := strings.ToLower(protos[len(protos)-1])
+		if proto == schemeHTTP || proto == schemeHTTPS {
+			info.scheme = proto
+		}
+	}
+
+	if hosts := splitHeaderElements(call.HeaderValues(headers.XForwardedHost), ','); len(hosts) > 0 {
+		info.host = hosts[len(hosts)-1]
+	}
+}
+
+// clientIndex returns the index of the client in the list of forwarded
+// addresses, walking from the closest proxy until an address which isn't a
+// trusted proxy is found. Addresses which aren't IP addresses, such as
+// obfuscated identifiers, can't be trusted, so the address forwarded before it
+// is used. Returns -1 if no address can be used.
+func (call *Call) clientIndex(addresses []string) int {
+	for i := len(addresses) - 1; i >= 0; i-- {
+		ip := remoteIP(addresses[i])
+		if _, parseErr := netip.ParseAddr(ip); parseErr != nil {
+			if i == len(addresses)-1 {
+				return -1
+			}
+			return i + 1
+		}
+
+		if i == 0 || !call.isTrustedProxy(ip) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (call *Call) isTrustedProxy(ip string) bool {
+	addr, parseErr := netip.ParseAddr(ip)
+	if parseErr != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range call.config.server.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteIP returns the IP address of an address with an optional port, such as
+// '192.0.2.1:8080' or '[2001:db8::1]:8080'.
+func remoteIP(address string) string {
+	address = strings.TrimSpace(address)
+
+	if host, _, splitErr := net.SplitHostPort(address); splitErr == nil {
+		address = host
+	}
+	address = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+
+	if addr, parseErr := netip.ParseAddr(address); parseErr == nil {
+		return addr.Unmap().String()
+	}
+
+	return address
+}
@@ -0,0 +1,82 @@
+package govalin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientWithoutTrustedProxies(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+		}).Get("/client", func(call *govalin.Call) {
+			call.Text(call.ClientIP() + " " + call.Scheme() + " " + call.Host())
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		_, body := getWithHeaders(t, govalinHTTP.Host+"/client", http.Header{
+			"X-Forwarded-For":   {"203.0.113.7"},
+			"X-Forwarded-Proto": {"https"},
+			"X-Forwarded-Host":  {"govalin.io"},
+			"Forwarded":         {"for=203.0.113.8;proto=https;host=govalin.io"},
+		})
+		assert.Regexp(t, `^127\.0\.0\.1 http localhost:\d+$`, body, "Should ignore forwarding headers of untrusted peers")
+	})
+}
+
+func TestClientWithTrustedProxies(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.TrustedProxies("127.0.0.0/8", "10.0.0.1", "invalid")
+		}).Get("/client", func(call *govalin.Call) {
+			call.Text(call.ClientIP() + " " + call.Scheme() + " " + call.Host())
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		_, body := getWithHeaders(t, govalinHTTP.Host+"/client", http.Header{
+			"X-Forwarded-For":   {"198.51.100.1, 203.0.113.7", "10.0.0.1"},
+			"X-Forwarded-Proto": {"https"},
+			"X-Forwarded-Host":  {"govalin.io"},
+		})
+		assert.Equal(t, "203.0.113.7 https govalin.io", body, "Should skip trusted proxies")
+
+		_, body = getWithHeaders(t, govalinHTTP.Host+"/client", http.Header{
+			"X-Forwarded-For": {"10.0.0.1"},
+		})
+		assert.Contains(t, body, "10.0.0.1 http ", "Should use the last address when all are trusted")
+
+		_, body = getWithHeaders(t, govalinHTTP.Host+"/client", http.Header{
+			"Forwarded": {
+				`for=198.51.100.1;proto=http, for="[2001:db8::7]:4711";proto=https;host=govalin.io`,
+				"for=10.0.0.1;proto=http;host=internal",
+			},
+			"X-Forwarded-For": {"192.0.2.1"},
+		})
+		assert.Equal(t, "2001:db8::7 https govalin.io", body, "Should prefer the Forwarded header")
+
+		_, body = getWithHeaders(t, govalinHTTP.Host+"/client", http.Header{
+			"Forwarded": {"for=_hidden, for=10.0.0.1"},
+		})
+		assert.Contains(t, body, "10.0.0.1 http ", "Should not trust obfuscated addresses")
+	})
+}
+
+func TestClientIPAttribute(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.TrustedProxies("127.0.0.0/8")
+		}).Get("/client", func(call *govalin.Call) {
+			clientIP, _ := govalin.Attr[string](call, govalin.ClientIPAttribute)
+			call.Text(clientIP)
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		_, body := getWithHeaders(t, govalinHTTP.Host+"/client", http.Header{
+			"X-Forwarded-For": {"203.0.113.7"},
+		})
+		assert.Equal(t, "203.0.113.7", body, "Should publish the resolved client IP")
+	})
+}
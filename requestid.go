@@ -0,0 +1,171 @@
+package govalin
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRequestIDHeader    = "X-Govalin-Id"
+	defaultMaxRequestIDLength = 128
+	crockfordBase32           = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// RequestIDGenerator generates the ID of calls without a valid incoming ID.
+type RequestIDGenerator func() string
+
+// RequestIDValidator returns whether an incoming request ID can be used as the
+// ID of the call.
+type RequestIDValidator func(id string) bool
+
+// RequestIDConfiguration configures how the ID of calls is chosen
+//
+// The ID of the call is taken from the request ID header of the request when
+// it's valid, and generated otherwise. The ID is echoed in the request ID
+// header of the response, so clients can correlate responses with logs.
+type RequestIDConfiguration struct {
+	header        string
+	generator     RequestIDGenerator
+	validator     RequestIDValidator
+	trustIncoming bool
+	echo          bool
+	useTraceID    bool
+}
+
+type RequestIDConfigFunc func(requestIDConfig *RequestIDConfiguration)
+
+// RequestID configures how the ID of calls is chosen. By default, valid IDs of
+// the X-Govalin-Id header are reused, and UUIDv4s are generated otherwise.
+func (config *Config) RequestID(confFunc RequestIDConfigFunc) *Config {
+	confFunc(&config.server.requestID)
+	return config
+}
+
+func newRequestIDConfiguration() RequestIDConfiguration {
+	return RequestIDConfiguration{
+		header:        defaultRequestIDHeader,
+		generator:     UUIDv4,
+		validator:     isValidRequestID,
+		trustIncoming: true,
+		echo:          true,
+	}
+}
+
+// Header sets the name of the header the request ID is read from and echoed
+// in, such as X-Request-Id. Defaults to X-Govalin-Id.
+func (config *RequestIDConfiguration) Header(header string) *RequestIDConfiguration {
+	config.header = http.CanonicalHeaderKey(header)
+	return config
+}
+
+// Generator sets the generator of IDs for calls without a valid incoming ID,
+// such as UUIDv4, UUIDv7 or ULID. Defaults to UUIDv4.
+func (config *RequestIDConfiguration) Generator(generator RequestIDGenerator) *RequestIDConfiguration {
+	config.generator = generator
+	return config
+}
+
+// Validator sets the validator of incoming IDs. IDs which aren't valid are
+// replaced by generated IDs. By default, IDs of up to 128 letters, digits and
+// the characters '-', '_', '.' and ':' are valid, so IDs can't be used to
+// inject content in logs.
+func (config *RequestIDConfiguration) Validator(validator RequestIDValidator) *RequestIDConfiguration {
+	config.validator = validator
+	return config
+}
+
+// TrustIncoming sets whether incoming IDs are used. Disable it for servers
+// exposed directly to clients, so the IDs of calls are always generated.
+// Defaults to true.
+func (config *RequestIDConfiguration) TrustIncoming(trustIncoming bool) *RequestIDConfiguration {
+	config.trustIncoming = trustIncoming
+	return config
+}
+
+// Echo sets whether the ID is echoed in the request ID header of the response.
+// Defaults to true.
+func (config *RequestIDConfiguration) Echo(echo bool) *RequestIDConfiguration {
+	config.echo = echo
+	return config
+}
+
+// UseTraceID sets whether the trace ID of the traceparent header is used as the
+// ID of calls without an incoming ID, so the call correlates with distributed
+// traces. Defaults to false.
+func (config *RequestIDConfiguration) UseTraceID(useTraceID bool) *RequestIDConfiguration {
+	config.useTraceID = useTraceID
+	return config
+}
+
+// callID chooses the ID of the call from the request and its trace context.
+func (config *RequestIDConfiguration) callID(req *http.Request, traceContext TraceContext) string {
+	if config.trustIncoming {
+		if values := req.Header[config.header]; len(values) > 0 && config.validator(values[0]) {
+			return values[0]
+		}
+	}
+
+	if config.useTraceID && traceContext.ParentID != "" {
+		return traceContext.TraceID
+	}
+
+	return config.generator()
+}
+
+// UUIDv4 generates random UUIDs, such as 'f47ac10b-58cc-4372-a567-0e02b2c3d479'.
+func UUIDv4() string {
+	return uuid.New().String()
+}
+
+// UUIDv7 generates time ordered UUIDs, such as
+// '01912d68-783e-7a03-8df6-3a5c1e2b7f4d', which sort by creation time.
+func UUIDv7() string {
+	id, idErr := uuid.NewV7()
+	if idErr != nil {
+		return UUIDv4()
+	}
+
+	return id.String()
+}
+
+// ULID generates time ordered ULIDs, such as '01J4PZ7R8Y3K6V2H9XQ5TBN1CW', which
+// are shorter than UUIDs and sort by creation time.
+func ULID() string {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixMilli())<<16)
+	_, _ = rand.Read(id[6:])
+
+	// The 128 bits are encoded 5 bits at a time, where the first character only
+	// encodes the 3 most significant bits
+	encoded := make([]byte, 26)
+	high := binary.BigEndian.Uint64(id[:8])
+	low := binary.BigEndian.Uint64(id[8:])
+	for i := 25; i >= 0; i-- {
+		encoded[i] = crockfordBase32[low&0x1f]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+
+	return string(encoded)
+}
+
+// isValidRequestID is the default validator of incoming request IDs.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > defaultMaxRequestIDLength {
+		return false
+	}
+
+	for _, char := range id {
+		isValid := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') ||
+			char == '-' || char == '_' || char == '.' || char == ':'
+		if !isValid {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,67 @@
+package govalin_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDConfiguration(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.RequestID(func(requestIDConfig *govalin.RequestIDConfiguration) {
+				requestIDConfig.Header("x-request-id").Generator(govalin.ULID)
+			})
+		}).Get("/id", func(call *govalin.Call) {
+			call.Text(call.ID())
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response := govalinHTTP.GetResponse("/id")
+		id, _ := response.ToString()
+		assert.Regexp(t, `^[0-9A-HJKMNP-TV-Z]{26}$`, id, "Should generate ULIDs")
+		assert.Equal(t, id, response.Header.Get("X-Request-Id"), "Should echo the ID")
+		assert.Empty(t, response.Header.Get("X-Govalin-Id"))
+
+		response, _ = govalinHTTP.Raw().Begin().WithHeader("X-Request-Id", "upstream-id.1").Get(govalinHTTP.Host + "/id")
+		id, _ = response.ToString()
+		assert.Equal(t, "upstream-id.1", id, "Should reuse valid IDs")
+		assert.Equal(t, "upstream-id.1", response.Header.Get("X-Request-Id"))
+
+		for _, invalid := range []string{"injected\" level=ERROR", strings.Repeat("a", 129)} {
+			response, _ = govalinHTTP.Raw().Begin().WithHeader("X-Request-Id", invalid).Get(govalinHTTP.Host + "/id")
+			id, _ = response.ToString()
+			assert.Len(t, id, 26, "Should replace invalid IDs")
+		}
+	})
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.RequestID(func(requestIDConfig *govalin.RequestIDConfiguration) {
+				requestIDConfig.Generator(govalin.UUIDv7).TrustIncoming(false).Echo(false)
+			})
+		}).Get("/id", func(call *govalin.Call) {
+			call.Text(call.ID())
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, _ := govalinHTTP.Raw().Begin().WithHeader("X-Govalin-Id", "govalin").Get(govalinHTTP.Host + "/id")
+		id, _ := response.ToString()
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-`), id, "Should ignore incoming IDs")
+		assert.Empty(t, response.Header.Get("X-Govalin-Id"))
+	})
+}
+
+func TestULIDsAreOrdered(t *testing.T) {
+	previous := govalin.ULID()
+	for range 100 {
+		next := govalin.ULID()
+		assert.Len(t, next, 26)
+		assert.LessOrEqual(t, previous[:10], next[:10], "Should sort by time")
+		previous = next
+	}
+}
@@ -0,0 +1,88 @@
+package govalin
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/pkkummermo/govalin/openapi"
+)
+
+// RouteFunc configures a route when it's added to the App.
+type RouteFunc func(route *Route)
+
+// Route contains optional configuration for a route, such as its documentation
+// for generated OpenAPI documents and how requests to it are handled.
+type Route struct {
+	summary       string
+	description   string
+	operationID   string
+	tags          []string
+	deprecated    bool
+	hidden        bool
+	body          any
+	hasBody       bool
+	responses     []routeResponse
+	params        []*openapi.Parameter
+	security      []openapi.SecurityRequirement
+	roles         []string
+	timeout       time.Duration
+	successStatus int
+	maxFiles      int
+	maxFileSize   int64
+	etagMode      ETagMode
+	requestType   reflect.Type
+	responseType  reflect.Type
+}
+
+func newRoute(routeFuncs ...RouteFunc) *Route {
+	route := &Route{
+		tags:      []string{},
+		responses: []routeResponse{},
+		params:    []*openapi.Parameter{},
+		security:  []openapi.SecurityRequirement{},
+		roles:     []string{},
+	}
+
+	for _, routeFunc := range routeFuncs {
+		routeFunc(route)
+	}
+
+	return route
+}
+
+// Timeout sets the deadline of the context of calls to the route, counting from
+// when the handler is invoked. Handlers should pass Call.Context() to blocking
+// operations so they are cancelled when the deadline is exceeded.
+func (route *Route) Timeout(timeout time.Duration) *Route {
+	route.timeout = timeout
+	return route
+}
+
+// SuccessStatus sets the status of successful responses from typed handlers,
+// see Handle. It's also used as the documented status of the response type
+// given by Describe.
+func (route *Route) SuccessStatus(status int) *Route {
+	route.successStatus = status
+	return route
+}
+
+// MaxFiles sets the max number of files accepted in multipart bodies sent to
+// the route, overriding Config.ServerMaxFiles.
+func (route *Route) MaxFiles(maxFiles int) *Route {
+	route.maxFiles = maxFiles
+	return route
+}
+
+// MaxFileSize sets the max size in bytes of each file in multipart bodies sent
+// to the route, overriding Config.ServerMaxFileSize.
+func (route *Route) MaxFileSize(maxFileSize int64) *Route {
+	route.maxFileSize = maxFileSize
+	return route
+}
+
+// ETag sets whether ETags are generated for responses to GET and HEAD requests
+// to the route, overriding Config.ETags. See Config.ETags.
+func (route *Route) ETag(mode ETagMode) *Route {
+	route.etagMode = mode
+	return route
+}
@@ -7,6 +7,10 @@ import (
 type ServeHTTPFunc func(w http.ResponseWriter, r *http.Request)
 
 // HttpServe registers a ServeHttpFunc to a path which adheres to the http.Handler interface.
+//
+// The RouteFuncs of the enclosing route groups apply to the path, so calls are
+// passed through the access manager with the roles of the groups, see
+// App.Route and Route.Roles. The path isn't included in OpenAPI documents.
 func (server *App) HTTPServe(path string, httpServeFunc ServeHTTPFunc) {
 	fullPath := server.currentFragment + path
 	handler := server.getOrCreatePathHandlerByPath(fullPath)
@@ -24,6 +28,20 @@ func (server *App) HTTPServe(path string, httpServeFunc ServeHTTPFunc) {
 	handler.Options = handlerFunc
 	handler.Head = handlerFunc
 
+	for _, method := range []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodOptions,
+		http.MethodHead,
+	} {
+		route := newRoute(server.scopeRouteFuncs...)
+		route.hidden = true
+		handler.Routes[method] = route
+	}
+
 	for _, onRouteAdded := range server.config.server.events.onRouteAdded {
 		onRouteAdded("ServeHTTP", fullPath, handlerFunc)
 	}
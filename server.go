@@ -8,8 +8,12 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/pkkummermo/govalin/internal/http/writers"
+	"github.com/pkkummermo/govalin/internal/session"
 	"github.com/pkkummermo/govalin/internal/validation"
 )
 
@@ -28,7 +32,13 @@ type App struct {
 	mux             *http.ServeMux
 	server          http.Server
 	currentFragment string
+	scopeRouteFuncs []RouteFunc
+	accessManager   AccessManagerFunc
 	pathHandlers    []pathHandler
+	baseContext     context.Context
+	cancelBase      context.CancelCauseFunc
+	sseMutex        sync.Mutex
+	sseClients      map[*SSEClient]struct{}
 }
 
 // New creates a new Govalin App instance.
@@ -49,6 +59,7 @@ func New(config ...ConfigFunc) *App {
 		createdTime:     time.Now(),
 		currentFragment: "",
 		mux:             http.NewServeMux(),
+		sseClients:      map[*SSEClient]struct{}{},
 	}
 }
 
@@ -56,18 +67,23 @@ func New(config ...ConfigFunc) *App {
 //
 // Add a route which provides a scoped route function for which you can add
 // methods or even more routes into. This allows for hierarchical building
-// of routes and methods.
-func (server *App) Route(path string, scopeFunc func()) *App {
+// of routes and methods. Optional RouteFuncs configure every route added in the
+// scope before the RouteFuncs of the route itself, such as the roles of a group
+// of routes.
+func (server *App) Route(path string, scopeFunc func(), routeFuncs ...RouteFunc) *App {
 	server.currentFragment += path
+	scopeRouteFuncs := server.scopeRouteFuncs
+	server.scopeRouteFuncs = append(slices.Clip(scopeRouteFuncs), routeFuncs...)
 
 	scopeFunc()
 
 	server.currentFragment = server.currentFragment[:len(server.currentFragment)-len(path)]
+	server.scopeRouteFuncs = scopeRouteFuncs
 
 	return server
 }
 
-func (server *App) addMethod(method string, fullPath string, methodHandler HandlerFunc) {
+func (server *App) addMethod(method string, fullPath string, methodHandler HandlerFunc, routeFuncs ...RouteFunc) {
 	handler := server.getOrCreatePathHandlerByPath(fullPath)
 
 	switch method {
@@ -118,6 +134,8 @@ func (server *App) addMethod(method string, fullPath string, methodHandler Handl
 		return
 	}
 
+	handler.Routes[method] = newRoute(append(slices.Clip(server.scopeRouteFuncs), routeFuncs...)...)
+
 	for _, onRouteAdded := range server.config.server.events.onRouteAdded {
 		onRouteAdded(method, fullPath, methodHandler)
 	}
@@ -127,41 +145,34 @@ func (server *App) addMethod(method string, fullPath string, methodHandler Handl
 //
 // Add a before handler that will run before any endpoint handler which matches
 // the same request. If the before handler returns false, the request will be
-// short circuited.
+// short circuited. Multiple before handlers can be added to the same path, and
+// will run in the order they were added.
 func (server *App) Before(path string, beforeFunc BeforeFunc) {
 	fullPath := server.currentFragment + path
 	handler := server.getOrCreatePathHandlerByPath(fullPath)
 
-	if handler.Before != nil {
-		slog.Error(fmt.Sprintf("Before already exists on path %s.", fullPath))
-		os.Exit(1)
-	}
-
-	handler.Before = beforeFunc
+	handler.Before = append(handler.Before, beforeFunc)
 }
 
 // Add an after handler to given path
 //
 // Add an after handler that will run after any endpoint handler which matches
-// the same request.
+// the same request. Multiple after handlers can be added to the same path, and
+// will run in the order they were added.
 func (server *App) After(path string, afterFunc AfterFunc) {
 	fullPath := server.currentFragment + path
 	handler := server.getOrCreatePathHandlerByPath(fullPath)
 
-	if handler.After != nil {
-		slog.Error(fmt.Sprintf("Before already exists on path %s.", fullPath))
-		os.Exit(1)
-	}
-
-	handler.After = afterFunc
+	handler.After = append(handler.After, afterFunc)
 }
 
 // Add a GET handler
 //
 // Add a GET handler based on where you are in a hierarchy composed from
-// other method handlers or route handlers.
-func (server *App) Get(path string, handler HandlerFunc) *App {
-	server.addMethod(http.MethodGet, server.currentFragment+path, handler)
+// other method handlers or route handlers. Optional RouteFuncs configure the
+// route, such as its documentation for generated OpenAPI documents.
+func (server *App) Get(path string, handler HandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodGet, server.currentFragment+path, handler, routeFuncs...)
 	return server
 }
 
@@ -169,8 +180,8 @@ func (server *App) Get(path string, handler HandlerFunc) *App {
 //
 // Add a POST handler based on where you are in a hierarchy composed from
 // other method handlers or route handlers.
-func (server *App) Post(path string, handler HandlerFunc) *App {
-	server.addMethod(http.MethodPost, server.currentFragment+path, handler)
+func (server *App) Post(path string, handler HandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodPost, server.currentFragment+path, handler, routeFuncs...)
 	return server
 }
 
@@ -178,8 +189,8 @@ func (server *App) Post(path string, handler HandlerFunc) *App {
 //
 // Add a PUT handler based on where you are in a hierarchy composed from
 // other method handlers or route handlers.
-func (server *App) Put(path string, handler HandlerFunc) *App {
-	server.addMethod(http.MethodPut, server.currentFragment+path, handler)
+func (server *App) Put(path string, handler HandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodPut, server.currentFragment+path, handler, routeFuncs...)
 	return server
 }
 
@@ -187,8 +198,8 @@ func (server *App) Put(path string, handler HandlerFunc) *App {
 //
 // Add a PATCH handler based on where you are in a hierarchy composed from
 // other method handlers or route handlers.
-func (server *App) Patch(path string, handler HandlerFunc) *App {
-	server.addMethod(http.MethodPatch, server.currentFragment+path, handler)
+func (server *App) Patch(path string, handler HandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodPatch, server.currentFragment+path, handler, routeFuncs...)
 	return server
 }
 
@@ -196,8 +207,8 @@ func (server *App) Patch(path string, handler HandlerFunc) *App {
 //
 // Add a DELETE handler based on where you are in a hierarchy composed from
 // other method handlers or route handlers.
-func (server *App) Delete(path string, handler HandlerFunc) *App {
-	server.addMethod(http.MethodDelete, server.currentFragment+path, handler)
+func (server *App) Delete(path string, handler HandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodDelete, server.currentFragment+path, handler, routeFuncs...)
 	return server
 }
 
@@ -205,8 +216,8 @@ func (server *App) Delete(path string, handler HandlerFunc) *App {
 //
 // Add a OPTIONS handler based on where you are in a hierarchy composed from
 // other method handlers or route handlers.
-func (server *App) Options(path string, handler HandlerFunc) *App {
-	server.addMethod(http.MethodOptions, server.currentFragment+path, handler)
+func (server *App) Options(path string, handler HandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodOptions, server.currentFragment+path, handler, routeFuncs...)
 	return server
 }
 
@@ -214,8 +225,8 @@ func (server *App) Options(path string, handler HandlerFunc) *App {
 //
 // Add a HEAD handler based on where you are in a hierarchy composed from
 // other method handlers or route handlers.
-func (server *App) Head(path string, handler HandlerFunc) *App {
-	server.addMethod(http.MethodHead, server.currentFragment+path, handler)
+func (server *App) Head(path string, handler HandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodHead, server.currentFragment+path, handler, routeFuncs...)
 	return server
 }
 
@@ -249,9 +260,16 @@ func (server *App) Start(port ...uint16) error {
 
 	server.mux.HandleFunc("/", server.rootHandlerFunc)
 
+	// Call contexts derive from the base context, letting Shutdown cancel calls
+	// which don't complete within the shutdown timeout
+	server.baseContext, server.cancelBase = context.WithCancelCause(context.Background())
+
 	server.server = http.Server{
 		ReadHeaderTimeout: time.Second * time.Duration(server.config.server.maxReadTimeout),
 		Handler:           server.mux,
+		BaseContext: func(_ net.Listener) context.Context {
+			return server.baseContext
+		},
 	}
 
 	if server.config.server.startupLogEnabled {
@@ -275,7 +293,8 @@ func (server *App) Start(port ...uint16) error {
 
 // Shutdown the govalin server
 //
-// Start a graceful shutdown of the govalin instance.
+// Start a graceful shutdown of the govalin instance. Calls which are still
+// running when the shutdown timeout is exceeded have their contexts cancelled.
 func (server *App) Shutdown() error {
 	if !server.started {
 		slog.Warn("Server was not started")
@@ -290,13 +309,39 @@ func (server *App) Shutdown() error {
 		slog.Info(fmt.Sprintf("Shutting down govalin. Server ran for %v 👋", time.Since(server.createdTime)))
 	}
 
+	// Server-sent events streams never complete by themselves
+	server.closeSSEClients()
+
 	ctx, closeFunc := context.WithTimeout(
 		context.Background(),
 		time.Duration(server.config.server.shutdownTimeoutInMS)*time.Millisecond,
 	)
 	defer closeFunc()
 
-	return server.server.Shutdown(ctx)
+	shutdownErr := server.server.Shutdown(ctx)
+	server.cancelBase(ErrServerShutdown)
+
+	for _, onServerStopped := range server.config.server.events.onServerStopped {
+		onServerStopped()
+	}
+
+	return shutdownErr
+}
+
+// PingSessionStore checks whether the session store can be reached, such as for
+// health checks. Stores implementing Ping(ctx context.Context) error, such as
+// the SQLite store, are pinged, while other stores are considered reachable.
+// Returns an error if sessions aren't enabled.
+func (server *App) PingSessionStore(ctx context.Context) error {
+	if !server.config.server.sessionsEnabled {
+		return errors.New("session handling is not enabled")
+	}
+
+	if pinger, ok := server.config.server.sessionStore.(session.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
 }
 
 func (server *App) getOrCreatePathHandlerByPath(path string) *pathHandler {
@@ -335,12 +380,14 @@ func (server *App) matchBeforeHandlers(call *Call) bool {
 		if call.bypassLifecycle {
 			return false
 		}
-		if pathHandler.Before != nil && pathHandler.PathMatcher.MatchesURL(call.URL().Path) {
+		if len(pathHandler.Before) > 0 && pathHandler.PathMatcher.MatchesURL(call.URL().Path) {
 			call.pathParams = pathHandler.PathMatcher.PathParams(call.URL().Path)
 
-			// Return false means short circuit, return false
-			if !pathHandler.Before(call) {
-				return false
+			for _, before := range pathHandler.Before {
+				// Return false means short circuit, return false
+				if !before(call) || call.bypassLifecycle {
+					return false
+				}
 			}
 		}
 	}
@@ -357,7 +404,22 @@ func (server *App) matchHandlers(call *Call) {
 		if pathHandler.GetHandlerByMethod(call.Method()) != nil && pathHandler.PathMatcher.MatchesURL(call.URL().Path) {
 			handler := pathHandler.GetHandlerByMethod(call.Method())
 			call.pathParams = pathHandler.PathMatcher.PathParams(call.URL().Path)
-			handler(call)
+
+			call.route = pathHandler.Routes[call.Method()]
+			call.routePattern = pathHandler.PathFragment
+			if route := call.route; route != nil && route.timeout > 0 {
+				// The deadline only applies to the handler, so after handlers and
+				// OnComplete funcs get the context of the call without it
+				parent := call.Context()
+				ctx, cancel := context.WithTimeout(parent, route.timeout)
+				call.WithContext(ctx)
+				defer func() {
+					cancel()
+					call.WithContext(parent)
+				}()
+			}
+
+			server.invokeHandler(call, handler)
 			break
 		}
 	}
@@ -368,9 +430,15 @@ func (server *App) matchAfterHandlers(call *Call) {
 		if call.bypassLifecycle {
 			return
 		}
-		if pathHandler.After != nil && pathHandler.PathMatcher.MatchesURL(call.URL().Path) {
+		if len(pathHandler.After) > 0 && pathHandler.PathMatcher.MatchesURL(call.URL().Path) {
 			call.pathParams = pathHandler.PathMatcher.PathParams(call.URL().Path)
-			pathHandler.After(call)
+
+			for _, after := range pathHandler.After {
+				if call.bypassLifecycle {
+					return
+				}
+				after(call)
+			}
 		}
 	}
 }
@@ -379,31 +447,41 @@ func (server *App) rootHandlerFunc(w http.ResponseWriter, req *http.Request) {
 	incomingRequestTime := time.Now()
 	w.Header().Add("Server", "govalin")
 
+	// The recorder sees the response of every call, including calls handled by
+	// HTTPServe which write to the response writer directly
+	recorder := &responseRecorder{Wrapper: writers.Wrapper{ResponseWriter: w}}
 	call := newCallFromRequest(
-		w,
+		recorder,
 		req,
 		server.config,
 		map[string]string{},
 	)
+	// The access log is written after the complete functions, as plugins such as
+	// compression flush their responses when the call completes
+	defer server.logAccessLog(call, recorder, incomingRequestTime)
+	defer call.complete()
 
 	// Look for before handlers
-	if !server.matchBeforeHandlers(&call) && !call.bypassLifecycle {
-		// Before handler returned false, meaning short circuit, meaning we need to log access log here
-		server.logAccessLog(&call, float64(time.Since(incomingRequestTime))/float64(time.Millisecond))
-		return
-	}
-	if call.bypassLifecycle {
+	endPhase := call.startPhase(CallPhaseBefore, "")
+	beforeHandled := server.matchBeforeHandlers(call)
+	endPhase(nil)
+	if !beforeHandled || call.bypassLifecycle {
+		// Before handler returned false, meaning short circuit
 		return
 	}
 
 	// Look for endpoint handler
-	server.matchHandlers(&call)
+	endPhase = call.startPhase(CallPhaseHandler, "")
+	server.matchHandlers(call)
+	endPhase(nil)
 	if call.bypassLifecycle {
 		return
 	}
 
 	// Look for After handlers
-	server.matchAfterHandlers(&call)
+	endPhase = call.startPhase(CallPhaseAfter, "")
+	server.matchAfterHandlers(call)
+	endPhase(nil)
 	if call.bypassLifecycle {
 		return
 	}
@@ -411,29 +489,10 @@ func (server *App) rootHandlerFunc(w http.ResponseWriter, req *http.Request) {
 	// No status set, meaning no handlers have handled the request properly,
 	// ie 404 / not found
 	if call.Status() == 0 {
-		server.notFoundHandler(&call)
-	}
-
-	if server.config.server.accessLogEnabled {
-		server.logAccessLog(&call, float64(time.Since(incomingRequestTime))/float64(time.Millisecond))
+		server.notFoundHandler(call)
 	}
 }
 
-func (server *App) logAccessLog(call *Call, durationInMS float64) {
-	slog.Info(
-		"incoming request",
-		slog.String("id", call.ID()),
-		slog.String("method", call.Method()),
-		slog.Float64("duration_in_ms", durationInMS),
-		slog.String("path", call.URL().Path),
-		slog.Int("status", call.status),
-		slog.String(
-			"user_agent",
-			call.UserAgent(),
-		),
-	)
-}
-
 func (server *App) notFoundHandler(call *Call) {
 	call.Status(http.StatusNotFound)
 	call.JSON(validation.NewError(
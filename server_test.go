@@ -198,6 +198,29 @@ func TestBefore(t *testing.T) {
 			"Should trigger multiple before and endpoint",
 		)
 	})
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Before("/*", func(call *govalin.Call) bool {
+			call.Text("before")
+			return true
+		})
+		app.Before("/*", func(call *govalin.Call) bool {
+			call.Text("before2")
+			return true
+		})
+		app.Get("/test", func(call *govalin.Call) {
+			call.Text("govalin")
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Equal(
+			t,
+			"beforebefore2govalin",
+			http.Get("/test"),
+			"Should trigger multiple before on the same path in order",
+		)
+	})
 }
 
 func TestAfter(t *testing.T) {
@@ -239,6 +262,27 @@ func TestAfter(t *testing.T) {
 			"Should trigger endpoint and multiple after",
 		)
 	})
+
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/test", func(call *govalin.Call) {
+			call.Text("govalin")
+		})
+		app.After("/*", func(call *govalin.Call) {
+			call.Text("after")
+		})
+		app.After("/*", func(call *govalin.Call) {
+			call.Text("after2")
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Equal(
+			t,
+			"govalinafterafter2",
+			http.Get("/test"),
+			"Should trigger multiple after on the same path in order",
+		)
+	})
 }
 
 func TestRoute(t *testing.T) {
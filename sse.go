@@ -0,0 +1,246 @@
+package govalin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkkummermo/govalin/internal/http/contenttypes"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+const defaultSSEKeepAliveInterval = 15 * time.Second
+
+// ErrSSEClosed is returned when sending to a server-sent events stream which
+// has been closed.
+var ErrSSEClosed = errors.New("server-sent events stream is closed")
+
+// SSEHandlerFunc handles a server-sent events stream. The stream is open until
+// the function returns, which should be when SSEClient.Done() is closed.
+type SSEHandlerFunc func(client *SSEClient)
+
+// SSEEvent is a single event sent to a server-sent events stream.
+type SSEEvent struct {
+	// ID sets the last event ID of the client, which is sent in the Last-Event-ID
+	// header when the client reconnects.
+	ID string
+	// Event is the event type. Defaults to "message" in the browser.
+	Event string
+	// Data is the event data. Multi-line data is sent as multiple data lines.
+	Data string
+	// Retry is the reconnection time hint for the client.
+	Retry time.Duration
+}
+
+// SSEClient is a client connected to a server-sent events stream
+//
+// Sending is safe from multiple goroutines while the stream is open.
+type SSEClient struct {
+	call      *Call
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	mutex     sync.Mutex
+	closed    bool
+	keepAlive *time.Ticker
+}
+
+// SSE registers a server-sent events handler for the given path
+//
+// The response is sent as "text/event-stream" with buffering disabled in
+// proxies, and every event is flushed to the client immediately. Keep-alive
+// comments are sent every 15 seconds by default, see SSEClient.KeepAlive.
+// Streams are closed when the client disconnects or the server shuts down.
+func (server *App) SSE(path string, handlerFunc SSEHandlerFunc, routeFuncs ...RouteFunc) *App {
+	server.addMethod(http.MethodGet, server.currentFragment+path, func(call *Call) {
+		client := newSSEClient(call)
+
+		server.sseMutex.Lock()
+		server.sseClients[client] = struct{}{}
+		server.sseMutex.Unlock()
+
+		defer func() {
+			client.close()
+
+			server.sseMutex.Lock()
+			delete(server.sseClients, client)
+			server.sseMutex.Unlock()
+		}()
+
+		call.Header(headers.ContentType, contenttypes.TextEventStream)
+		call.Header(headers.CacheControl, "no-cache")
+		call.Header(headers.XAccelBuffering, "no")
+		call.Status(http.StatusOK)
+		call.sendStatusOrDefault()
+		if flushErr := http.NewResponseController(call.w).Flush(); flushErr != nil {
+			return
+		}
+
+		go client.sendKeepAlives()
+
+		handlerFunc(client)
+	}, routeFuncs...)
+
+	return server
+}
+
+func newSSEClient(call *Call) *SSEClient {
+	ctx, cancel := context.WithCancelCause(call.Context())
+	call.WithContext(ctx)
+
+	return &SSEClient{
+		call:      call,
+		ctx:       ctx,
+		cancel:    cancel,
+		keepAlive: time.NewTicker(defaultSSEKeepAliveInterval),
+	}
+}
+
+// Call returns the call of the stream.
+func (client *SSEClient) Call() *Call {
+	return client.call
+}
+
+// Context returns the context of the stream, which is cancelled when the stream
+// is closed.
+func (client *SSEClient) Context() context.Context {
+	return client.ctx
+}
+
+// Done returns a channel which is closed when the client disconnects or the
+// server shuts down.
+func (client *SSEClient) Done() <-chan struct{} {
+	return client.ctx.Done()
+}
+
+// LastEventID returns the ID of the last event received by the client before
+// reconnecting, letting the stream resume from there. Empty on first connect.
+func (client *SSEClient) LastEventID() string {
+	return client.call.Header(headers.LastEventID)
+}
+
+// KeepAlive sets the interval of keep-alive comments, preventing proxies from
+// closing idle streams. Use 0 to disable keep-alive comments.
+func (client *SSEClient) KeepAlive(interval time.Duration) {
+	if interval <= 0 {
+		client.keepAlive.Stop()
+		return
+	}
+
+	client.keepAlive.Reset(interval)
+}
+
+// Send sends an event to the client.
+func (client *SSEClient) Send(event SSEEvent) error {
+	var builder strings.Builder
+
+	if event.ID != "" {
+		builder.WriteString("id: " + singleLine(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		builder.WriteString("event: " + singleLine(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(event.Data, "\r\n", "\n"), "\n") {
+		builder.WriteString("data: " + line + "\n")
+	}
+	builder.WriteString("\n")
+
+	return client.write(builder.String())
+}
+
+// SendData sends an event with given data to the client.
+func (client *SSEClient) SendData(data string) error {
+	return client.Send(SSEEvent{Data: data})
+}
+
+// SendJSON sends an event of given type with the object as JSON data. The event
+// type can be left empty.
+func (client *SSEClient) SendJSON(event string, obj any) error {
+	data, marshalErr := json.Marshal(obj)
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal server-sent event data. %w", marshalErr)
+	}
+
+	return client.Send(SSEEvent{Event: event, Data: string(data)})
+}
+
+// Retry sends the reconnection time hint to the client.
+func (client *SSEClient) Retry(retry time.Duration) error {
+	return client.write("retry: " + strconv.FormatInt(retry.Milliseconds(), 10) + "\n\n")
+}
+
+// Comment sends a comment, which is ignored by the client.
+func (client *SSEClient) Comment(comment string) error {
+	return client.write(": " + singleLine(comment) + "\n\n")
+}
+
+// Close closes the stream. Handlers should return after closing the stream.
+func (client *SSEClient) Close() {
+	client.cancel(ErrSSEClosed)
+}
+
+func (client *SSEClient) write(message string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if client.closed || client.ctx.Err() != nil {
+		return ErrSSEClosed
+	}
+
+	if _, writeErr := io.WriteString(client.call.w, message); writeErr != nil {
+		return fmt.Errorf("failed to write server-sent event. %w", writeErr)
+	}
+
+	if flushErr := http.NewResponseController(client.call.w).Flush(); flushErr != nil {
+		return fmt.Errorf("failed to flush server-sent event. %w", flushErr)
+	}
+
+	return nil
+}
+
+func (client *SSEClient) sendKeepAlives() {
+	for {
+		select {
+		case <-client.ctx.Done():
+			return
+		case <-client.keepAlive.C:
+			if client.write(": keep-alive\n\n") != nil {
+				return
+			}
+		}
+	}
+}
+
+// close stops writes to the stream. The response can't be written to after the
+// handler returns.
+func (client *SSEClient) close() {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.closed = true
+	client.keepAlive.Stop()
+	client.cancel(ErrSSEClosed)
+}
+
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(value)
+}
+
+// closeSSEClients closes all open server-sent events streams.
+func (server *App) closeSSEClients() {
+	server.sseMutex.Lock()
+	defer server.sseMutex.Unlock()
+
+	for client := range server.sseClients {
+		client.cancel(ErrServerShutdown)
+	}
+}
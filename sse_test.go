@@ -0,0 +1,126 @@
+package govalin_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+// readSSE reads given number of lines from a server-sent events stream.
+func readSSE(t *testing.T, url string, lastEventID string, lines int) (*http.Response, []string) {
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	response, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	read := []string{}
+	for len(read) < lines {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil {
+			break
+		}
+		read = append(read, strings.TrimSuffix(line, "\n"))
+	}
+
+	return response, read
+}
+
+func TestSSE(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.SSE("/events", func(client *govalin.SSEClient) {
+			_ = client.Retry(time.Second)
+			_ = client.Send(govalin.SSEEvent{
+				ID:    "1",
+				Event: "update",
+				Data:  "first\nsecond",
+			})
+			_ = client.SendJSON("", map[string]string{"resumedFrom": client.LastEventID()})
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		response, lines := readSSE(t, http.Host+"/events", "41", 10)
+
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+		assert.Equal(t, "no", response.Header.Get("X-Accel-Buffering"))
+		assert.Equal(t, "no-cache", response.Header.Get("Cache-Control"))
+		assert.Equal(t, []string{
+			"retry: 1000",
+			"",
+			"id: 1",
+			"event: update",
+			"data: first",
+			"data: second",
+			"",
+			`data: {"resumedFrom":"41"}`,
+			"",
+		}, lines)
+	})
+}
+
+func TestSSEKeepAlive(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.SSE("/events", func(client *govalin.SSEClient) {
+			client.KeepAlive(10 * time.Millisecond)
+			<-client.Done()
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		_, lines := readSSE(t, http.Host+"/events", "", 2)
+
+		assert.Equal(t, []string{": keep-alive", ""}, lines)
+	})
+}
+
+func TestSSEClosedOnShutdown(t *testing.T) {
+	closed := make(chan error, 1)
+	connected := make(chan bool)
+	listening := make(chan bool)
+
+	app := govalin.New(func(config *govalin.Config) {
+		config.EnableStartupLog(false)
+		config.EnableAccessLog(false)
+		config.Events(func(serverEvents *govalin.ServerEvents) {
+			serverEvents.AddOnServerStartup(func() { listening <- true })
+		})
+	})
+	app.SSE("/events", func(client *govalin.SSEClient) {
+		connected <- true
+		<-client.Done()
+		closed <- client.SendData("too late")
+	})
+
+	listener, _ := net.Listen("tcp", "localhost:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	go func() { _ = app.Start(uint16(port)) }()
+	<-listening
+
+	go func() { readSSE(t, fmt.Sprintf("http://localhost:%d/events", port), "", 1) }()
+	<-connected
+
+	assert.NoError(t, app.Shutdown(), "Should close streams instead of waiting for them")
+
+	select {
+	case err := <-closed:
+		assert.ErrorIs(t, err, govalin.ErrSSEClosed)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Stream was not closed on shutdown")
+	}
+}
@@ -173,6 +173,7 @@ func (config *StaticConfig) EnableSPAMode(spaMode bool) *StaticConfig {
 // Add a Static endpoint
 //
 // Add a static endpoint which will serve static files from the given path or bundled FS.
+// Static endpoints aren't included in OpenAPI documents.
 func (server *App) Static(path string, staticHandlerFunc StaticHandlerFunc) *App {
 	// TODO: this doesn't feel right to override the path like this
 	normalizedPath := strings.TrimRight(path, "/*")
@@ -187,9 +188,13 @@ func (server *App) Static(path string, staticHandlerFunc StaticHandlerFunc) *App
 		internalConfig.handle(call)
 	}
 
+	hidden := func(route *Route) {
+		route.Hidden()
+	}
+
 	// TODO: this should be handled by a single handler, not two
-	server.addMethod(http.MethodGet, server.currentFragment+normalizedPath+"/", staticGetHandler)
-	server.addMethod(http.MethodGet, server.currentFragment+wildcardPath, staticGetHandler)
+	server.addMethod(http.MethodGet, server.currentFragment+normalizedPath+"/", staticGetHandler, hidden)
+	server.addMethod(http.MethodGet, server.currentFragment+wildcardPath, staticGetHandler, hidden)
 
 	return server
 }
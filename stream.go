@@ -0,0 +1,150 @@
+package govalin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+
+	"github.com/pkkummermo/govalin/internal/http/charsets"
+	"github.com/pkkummermo/govalin/internal/http/contenttypes"
+	"github.com/pkkummermo/govalin/internal/http/headers"
+)
+
+// StreamWriter writes a streamed response of a call
+//
+// The status of the call and the headers set before the first write are sent
+// on the first write, matching the behaviour of Text, HTML and JSON.
+type StreamWriter struct {
+	call      *Call
+	autoFlush bool
+}
+
+// Writer returns a writer for streaming the response. Data is buffered and sent
+// in chunks, use Flush or AutoFlush to send data to the client immediately.
+func (call *Call) Writer() *StreamWriter {
+	return &StreamWriter{call: call}
+}
+
+// Write writes data to the response, sending the status of the call first.
+func (writer *StreamWriter) Write(data []byte) (int, error) {
+	writer.call.sendStatusOrDefault()
+
+	written, writeErr := writer.call.w.Write(data)
+	if writeErr != nil {
+		return written, writeErr
+	}
+
+	if writer.autoFlush {
+		return written, writer.Flush()
+	}
+
+	return written, nil
+}
+
+// Flush sends buffered data to the client.
+func (writer *StreamWriter) Flush() error {
+	writer.call.sendStatusOrDefault()
+
+	return http.NewResponseController(writer.call.w).Flush()
+}
+
+// AutoFlush sets whether every write is flushed to the client immediately.
+func (writer *StreamWriter) AutoFlush(autoFlush bool) *StreamWriter {
+	writer.autoFlush = autoFlush
+	return writer
+}
+
+// Stream copies the reader to the response without buffering it in memory
+//
+// Stream sets the content-type of the response to the given content type and
+// writes the status of the call before copying. Data is flushed as it's read,
+// so slow readers such as pipes reach the client as data becomes available.
+// Returns an error if the copy fails, at which point the response is incomplete.
+func (call *Call) Stream(contentType string, reader io.Reader) error {
+	call.w.Header().Set(headers.ContentType, contentType)
+
+	if _, copyErr := io.Copy(call.Writer().AutoFlush(true), reader); copyErr != nil {
+		return fmt.Errorf("failed to stream response. %w", copyErr)
+	}
+
+	return nil
+}
+
+// StreamNDJSON streams the items as newline delimited JSON
+//
+// Each item is serialized and flushed as it's produced, so the full list is
+// never held in memory. Streaming stops when the client disconnects.
+func StreamNDJSON[T any](call *Call, items iter.Seq[T]) error {
+	call.w.Header().Set(headers.ContentType, contenttypes.ApplicationNDJSON)
+
+	writer := call.Writer().AutoFlush(true)
+	encoder := json.NewEncoder(writer)
+
+	for item := range items {
+		if ctxErr := call.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		// The encoder terminates every value with a newline
+		if encodeErr := encoder.Encode(item); encodeErr != nil {
+			return fmt.Errorf("failed to stream NDJSON item. %w", encodeErr)
+		}
+	}
+
+	// Make sure the status is sent for empty streams
+	return writer.Flush()
+}
+
+// StreamJSONArray streams the items as a JSON array
+//
+// Each item is serialized and flushed as it's produced, so the full list is
+// never held in memory. Streaming stops when the client disconnects, leaving
+// the array incomplete.
+func StreamJSONArray[T any](call *Call, items iter.Seq[T]) error {
+	call.w.Header().Set(headers.ContentType, headers.ContentTypeHeader(contenttypes.ApplicationJSON, charsets.UTF8))
+
+	writer := call.Writer().AutoFlush(true)
+	separator := "["
+
+	for item := range items {
+		if ctxErr := call.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		itemBytes, marshalErr := json.Marshal(item)
+		if marshalErr != nil {
+			return fmt.Errorf("failed to stream JSON array item. %w", marshalErr)
+		}
+
+		if _, writeErr := writer.Write(append([]byte(separator), itemBytes...)); writeErr != nil {
+			return fmt.Errorf("failed to stream JSON array item. %w", writeErr)
+		}
+		separator = ","
+	}
+
+	end := "]"
+	if separator == "[" {
+		end = "[]"
+	}
+
+	if _, writeErr := io.WriteString(writer, end); writeErr != nil {
+		return fmt.Errorf("failed to stream JSON array. %w", writeErr)
+	}
+
+	return nil
+}
+
+// Chan returns an iterator over the values received from the channel until it's
+// closed, for streaming values from a channel using StreamNDJSON or
+// StreamJSONArray.
+func Chan[T any](channel <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for value := range channel {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
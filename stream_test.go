@@ -0,0 +1,96 @@
+package govalin_test
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamedRow struct {
+	ID int `json:"id"`
+}
+
+func TestStream(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/stream", func(call *govalin.Call) {
+			call.Status(201)
+			assert.NoError(t, call.Stream("text/csv", strings.NewReader("id\n1\n2\n")))
+		})
+		app.Get("/pipe", func(call *govalin.Call) {
+			reader, writer := io.Pipe()
+			go func() {
+				for i := range 3 {
+					fmt.Fprintf(writer, "%d\n", i)
+				}
+				writer.Close()
+			}()
+
+			assert.NoError(t, call.Stream("text/plain", reader))
+		})
+		app.Get("/writer", func(call *govalin.Call) {
+			call.Header("X-Govalin", "stream")
+			writer := call.Writer()
+			fmt.Fprint(writer, "first")
+			assert.NoError(t, writer.Flush())
+			fmt.Fprint(writer, "second")
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/stream")
+		body, _ := response.ToString()
+		assert.Equal(t, 201, response.StatusCode, "Should send the status of the call")
+		assert.Equal(t, "text/csv", response.Header.Get("Content-Type"))
+		assert.Equal(t, "id\n1\n2\n", body)
+
+		assert.Equal(t, "0\n1\n2\n", http.Get("/pipe"))
+
+		response = http.GetResponse("/writer")
+		body, _ = response.ToString()
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "stream", response.Header.Get("X-Govalin"))
+		assert.Equal(t, "firstsecond", body)
+	})
+}
+
+func TestStreamJSON(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Get("/ndjson", func(call *govalin.Call) {
+			assert.NoError(t, govalin.StreamNDJSON(call, slices.Values([]streamedRow{{ID: 1}, {ID: 2}})))
+		})
+		app.Get("/array", func(call *govalin.Call) {
+			rows := make(chan streamedRow)
+			go func() {
+				for i := range 3 {
+					rows <- streamedRow{ID: i}
+				}
+				close(rows)
+			}()
+
+			assert.NoError(t, govalin.StreamJSONArray(call, govalin.Chan(rows)))
+		})
+		app.Get("/empty", func(call *govalin.Call) {
+			assert.NoError(t, govalin.StreamJSONArray(call, slices.Values([]streamedRow{})))
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		response := http.GetResponse("/ndjson")
+		body, _ := response.ToString()
+		assert.Equal(t, "application/x-ndjson", response.Header.Get("Content-Type"))
+		assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", body)
+
+		response = http.GetResponse("/array")
+		body, _ = response.ToString()
+		assert.Contains(t, response.Header.Get("Content-Type"), "application/json")
+		assert.JSONEq(t, `[{"id":0},{"id":1},{"id":2}]`, body)
+
+		assert.Equal(t, "[]", http.Get("/empty"))
+	})
+}
@@ -0,0 +1,133 @@
+package govalin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	headerTraceparent = "Traceparent"
+	headerTracestate  = "Tracestate"
+
+	traceparentVersion = "00"
+	traceFlagSampled   = 0x01
+	maxTracestateLen   = 512
+)
+
+// TraceContext is the W3C Trace Context of a call
+//
+// The trace context is parsed from the traceparent and tracestate headers of
+// the request. Calls without a valid traceparent header start a new trace. Each
+// call gets a new span ID, so downstream requests can be made part of the trace
+// using Inject.
+type TraceContext struct {
+	TraceID  string // TraceID is the 32 hex character ID of the trace
+	ParentID string // ParentID is the span ID of the caller, or empty for new traces
+	SpanID   string // SpanID is the 16 hex character ID of the call within the trace
+	Flags    byte   // Flags are the trace flags, such as whether the trace is sampled
+	State    string // State is the vendor specific tracestate of the trace
+}
+
+// Sampled returns whether the caller has recorded the trace.
+func (traceContext TraceContext) Sampled() bool {
+	return traceContext.Flags&traceFlagSampled != 0
+}
+
+// Traceparent returns the traceparent header value identifying the span of the
+// call, for propagating the trace to downstream requests.
+func (traceContext TraceContext) Traceparent() string {
+	return traceparentVersion + "-" + traceContext.TraceID + "-" + traceContext.SpanID + "-" +
+		hex.EncodeToString([]byte{traceContext.Flags})
+}
+
+// Inject sets the traceparent and tracestate headers of a downstream request,
+// making it a child of the span of the call.
+func (traceContext TraceContext) Inject(requestHeaders http.Header) {
+	requestHeaders.Set(headerTraceparent, traceContext.Traceparent())
+	if traceContext.State != "" {
+		requestHeaders.Set(headerTracestate, traceContext.State)
+	} else {
+		requestHeaders.Del(headerTracestate)
+	}
+}
+
+// TraceContext returns the W3C Trace Context of the call.
+func (call *Call) TraceContext() TraceContext {
+	return call.traceContext
+}
+
+// TraceContextFromContext returns the W3C Trace Context of the call the context
+// belongs to. Returns false if the context doesn't belong to a call.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	traceContext, ok := ctx.Value(traceContextKey).(TraceContext)
+	return traceContext, ok
+}
+
+// newTraceContext parses the trace context of the request, starting a new
+// trace if the traceparent header is missing or invalid.
+func newTraceContext(req *http.Request) TraceContext {
+	traceContext, valid := parseTraceparent(req.Header.Get(headerTraceparent))
+	if valid {
+		traceContext.State = parseTracestate(req.Header.Values(headerTracestate))
+	} else {
+		traceContext = TraceContext{TraceID: randomHex(16)}
+	}
+	traceContext.SpanID = randomHex(8)
+
+	return traceContext
+}
+
+// parseTraceparent parses a traceparent header value. Versions after 00 are
+// parsed as version 00, ignoring any additional fields, as required by the
+// specification.
+func parseTraceparent(value string) (TraceContext, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return TraceContext{}, false
+	}
+
+	version, traceID, parentID, flags := value[0:2], value[3:35], value[36:52], value[53:55]
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' ||
+		!isLowerHex(version) || version == "ff" || (version == traceparentVersion && len(value) != 55) ||
+		!isLowerHex(traceID) || traceID == strings.Repeat("0", 32) ||
+		!isLowerHex(parentID) || parentID == strings.Repeat("0", 16) ||
+		!isLowerHex(flags) {
+		return TraceContext{}, false
+	}
+
+	flagBytes, _ := hex.DecodeString(flags)
+
+	return TraceContext{TraceID: traceID, ParentID: parentID, Flags: flagBytes[0]}, true
+}
+
+// parseTracestate joins the tracestate header values, dropping the state if
+// it's too long to be propagated.
+func parseTracestate(values []string) string {
+	members := splitHeaderElements(values, ',')
+	state := strings.Join(members, ",")
+	if len(members) > 32 || len(state) > maxTracestateLen {
+		return ""
+	}
+
+	return state
+}
+
+func isLowerHex(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if !(value[i] >= '0' && value[i] <= '9') && !(value[i] >= 'a' && value[i] <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func randomHex(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,79 @@
+package govalin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkkummermo/govalin"
+	"github.com/pkkummermo/govalin/internal/govalintesting"
+	"github.com/stretchr/testify/assert"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTraceContext(t *testing.T) {
+	var downstream http.Header
+
+	govalintesting.HTTPTestUtil(func(_ *govalin.App) *govalin.App {
+		return govalin.New(func(config *govalin.Config) {
+			config.EnableAccessLog(false)
+			config.RequestID(func(requestIDConfig *govalin.RequestIDConfiguration) {
+				requestIDConfig.UseTraceID(true)
+			})
+		}).Get("/trace", func(call *govalin.Call) {
+			traceContext, ok := govalin.TraceContextFromContext(call.Context())
+			assert.True(t, ok)
+			assert.Equal(t, call.TraceContext(), traceContext)
+
+			downstream = http.Header{}
+			traceContext.Inject(downstream)
+			call.JSON(traceContext.Sampled())
+		})
+	}, func(govalinHTTP govalintesting.GovalinHTTP) {
+		response, _ := govalinHTTP.Raw().Begin().
+			WithHeader("traceparent", traceparent).
+			WithHeader("tracestate", "congo=t61rcWkgMzE, rojo=00f067aa0ba902b7").
+			Get(govalinHTTP.Host + "/trace")
+		_ = response.Body.Close()
+		id := response.Header.Get("X-Govalin-Id")
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", id, "Should use the trace ID as the call ID")
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceContextTraceID(downstream))
+		assert.NotContains(t, downstream.Get("Traceparent"), "00f067aa0ba902b7", "Should propagate a new span ID")
+		assert.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$`, downstream.Get("Traceparent"))
+		assert.Equal(t, "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7", downstream.Get("Tracestate"))
+
+		for _, invalid := range []string{
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			traceparent + "-extra",
+		} {
+			response, _ = govalinHTTP.Raw().Begin().
+				WithHeader("traceparent", invalid).
+				WithHeader("tracestate", "congo=t61rcWkgMzE").
+				Get(govalinHTTP.Host + "/trace")
+			_ = response.Body.Close()
+			assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceContextTraceID(downstream), invalid)
+			assert.Len(t, traceContextTraceID(downstream), 32, "Should start a new trace")
+			assert.Empty(t, downstream.Get("Tracestate"), "Should drop the state of invalid traces")
+			assert.Len(t, response.Header.Get("X-Govalin-Id"), 36, "Should generate IDs for new traces")
+		}
+
+		response, _ = govalinHTTP.Raw().Begin().
+			WithHeader("traceparent", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future").
+			Get(govalinHTTP.Host + "/trace")
+		_ = response.Body.Close()
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceContextTraceID(downstream), "Should accept future versions")
+		assert.Regexp(t, `-00$`, downstream.Get("Traceparent"))
+	})
+}
+
+func traceContextTraceID(requestHeaders http.Header) string {
+	traceparent := requestHeaders.Get("Traceparent")
+	if len(traceparent) != 55 {
+		return ""
+	}
+
+	return traceparent[3:35]
+}
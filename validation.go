@@ -236,6 +236,18 @@ func (v *BodyValidator) Get() error {
 	return nil
 }
 
+// Tags adds the rules given by the `govalin` struct tags of the body, such as
+// `govalin:"required,minLength=2"`. Nested structs are validated as well.
+func (v *BodyValidator) Tags() *BodyValidator {
+	v.rules = append(v.rules, func(data interface{}) error {
+		if err := validation.ValidateTags(data); err != nil {
+			return err
+		}
+		return nil
+	})
+	return v
+}
+
 // ValidateField sets the current field for validation and returns a BodyFieldValidator.
 func (v *BodyValidator) ValidateField(fieldName string) *BodyFieldValidator {
 	return &BodyFieldValidator{
@@ -515,3 +515,47 @@ func TestCurryableTypedCustomValidation(t *testing.T) {
 		assert.Contains(t, response, "Email must contain @ symbol")
 	})
 }
+
+type TaggedUser struct {
+	Name  string `json:"name"  govalin:"required,minLength=2"`
+	Email string `json:"email" govalin:"email"`
+	Age   int    `json:"age"   govalin:"min=18,max=100"`
+	Role  string `json:"role"  govalin:"oneOf=admin user"`
+}
+
+func TestValidatedBodyWithTags(t *testing.T) {
+	govalintesting.HTTPTestUtil(func(app *govalin.App) *govalin.App {
+		app.Post("/validate-tags", func(call *govalin.Call) {
+			var user TaggedUser
+
+			if err := call.ValidatedBody(&user).Tags().Get(); err != nil {
+				call.Error(err)
+				return
+			}
+
+			call.Text("valid")
+		})
+		app.Post("/validate-without-tags", func(call *govalin.Call) {
+			var user TaggedUser
+
+			if err := call.ValidatedBody(&user).Get(); err != nil {
+				call.Error(err)
+				return
+			}
+
+			call.Text("valid")
+		})
+
+		return app
+	}, func(http govalintesting.GovalinHTTP) {
+		assert.Equal(t, "valid", http.Post("/validate-tags", `{"name":"John","email":"john@example.com","age":25}`))
+		assert.Contains(t, http.Post("/validate-tags", `{"email":"john@example.com","age":25}`), "This field is required")
+		assert.Contains(t, http.Post("/validate-tags", `{"name":"J","age":25}`), "Must be at least 2 characters long")
+		assert.Contains(t, http.Post("/validate-tags", `{"name":"John","email":"john","age":25}`), "Must be a valid email address")
+		assert.Contains(t, http.Post("/validate-tags", `{"name":"John","age":17}`), `"field":"age"`)
+		assert.Contains(t, http.Post("/validate-tags", `{"name":"John","age":25,"role":"root"}`), "Must be one of")
+		assert.Equal(t, "valid", http.Post("/validate-without-tags", `{"name":"J","age":17}`),
+			"Should only apply the rules of tags when asked to",
+		)
+	})
+}
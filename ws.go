@@ -185,8 +185,15 @@ func (server *App) Ws(path string, handlerFunc WsHandlerFunc) *App {
 			return
 		}
 
+		endPhase := call.startPhase(CallPhaseWebsocket, "")
 		wsConfig.OnOpen(wsCall)
-		go readWebsocketFunc(wsCall, wsConfig)
+		go func() {
+			readWebsocketFunc(wsCall, wsConfig)
+			endPhase(nil)
+		}()
+	}, func(route *Route) {
+		// Websocket upgrades can't be described by OpenAPI
+		route.Hidden()
 	})
 
 	return server